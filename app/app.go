@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeksilaen/api-builder/config"
+	"github.com/jeksilaen/api-builder/mailer"
+	"github.com/jeksilaen/api-builder/middlewares"
+	collectionHandler "github.com/jeksilaen/api-builder/modules/collection/handlers"
+	collectionRepositories "github.com/jeksilaen/api-builder/modules/collection/repositories"
+	collectionUsecases "github.com/jeksilaen/api-builder/modules/collection/usecases"
+	environmentHandler "github.com/jeksilaen/api-builder/modules/environment/handlers"
+	environmentRepositories "github.com/jeksilaen/api-builder/modules/environment/repositories"
+	environmentUsecases "github.com/jeksilaen/api-builder/modules/environment/usecases"
+	mockHandler "github.com/jeksilaen/api-builder/modules/mock/handlers"
+	mockRepositories "github.com/jeksilaen/api-builder/modules/mock/repositories"
+	mockUsecases "github.com/jeksilaen/api-builder/modules/mock/usecases"
+	monitorHandler "github.com/jeksilaen/api-builder/modules/monitor/handlers"
+	monitorRepositories "github.com/jeksilaen/api-builder/modules/monitor/repositories"
+	monitorUsecases "github.com/jeksilaen/api-builder/modules/monitor/usecases"
+	requestHandler "github.com/jeksilaen/api-builder/modules/request/handlers"
+	requestRepositories "github.com/jeksilaen/api-builder/modules/request/repositories"
+	requestUsecases "github.com/jeksilaen/api-builder/modules/request/usecases"
+	shareHandler "github.com/jeksilaen/api-builder/modules/share/handlers"
+	shareRepositories "github.com/jeksilaen/api-builder/modules/share/repositories"
+	shareUsecases "github.com/jeksilaen/api-builder/modules/share/usecases"
+	userHandler "github.com/jeksilaen/api-builder/modules/user/handlers"
+	userRepositories "github.com/jeksilaen/api-builder/modules/user/repositories"
+	userUsecases "github.com/jeksilaen/api-builder/modules/user/usecases"
+	webhookHandler "github.com/jeksilaen/api-builder/modules/webhook/handlers"
+	webhookHelpers "github.com/jeksilaen/api-builder/modules/webhook/helpers"
+	webhookRepositories "github.com/jeksilaen/api-builder/modules/webhook/repositories"
+	webhookUsecases "github.com/jeksilaen/api-builder/modules/webhook/usecases"
+	workspaceHandler "github.com/jeksilaen/api-builder/modules/workspace/handlers"
+	workspaceRepositories "github.com/jeksilaen/api-builder/modules/workspace/repositories"
+	workspaceUsecases "github.com/jeksilaen/api-builder/modules/workspace/usecases"
+	"github.com/jeksilaen/api-builder/sso"
+	"gorm.io/gorm"
+)
+
+// ErrShutdownTimeout is returned when background runs are still going when
+// the shutdown timeout ends.
+var ErrShutdownTimeout = errors.New("shutdown timed out before the background runs finished")
+
+// App holds the usecases, the HTTP server and the monitor scheduler, wired
+// once at startup from the configuration and the database. Nothing else
+// keeps state between requests.
+type App struct {
+	Config *config.Config
+	DB     *gorm.DB
+
+	Collections  *collectionUsecases.CollectionCommandUsecase
+	Environments *environmentUsecases.EnvironmentCommandUsecase
+	Requests     *requestUsecases.RequestCommandUsecase
+	Mocks        *mockUsecases.MockCommandUsecase
+	Monitors     *monitorUsecases.MonitorCommandUsecase
+	Webhooks     *webhookUsecases.WebhookCommandUsecase
+	Dispatcher   *webhookUsecases.WebhookDispatcher
+	Shares       *shareUsecases.ShareCommandUsecase
+	Users        *userUsecases.UserCommandUsecase
+	Workspaces   *workspaceUsecases.WorkspaceCommandUsecase
+
+	Auth      *middlewares.Auth
+	Scheduler *monitorUsecases.MonitorScheduler
+	Server    *http.Server
+}
+
+// NewApp builds the repositories of gormDB, the usecases on top of them and
+// the server routing to their handlers. It loads the token keys, sets up the
+// email sender and discovers the IdP of the configuration.
+func NewApp(ctx context.Context, cfg *config.Config, gormDB *gorm.DB) (*App, error) {
+	app := &App{Config: cfg, DB: gormDB}
+
+	keys, err := middlewares.LoadKeys(cfg.JWT.KeysDir, cfg.JWT.SigningKeyID)
+	if err != nil {
+		return nil, err
+	}
+	sender, err := mailer.NewSender(cfg.Mail)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := sso.NewConfiguredProvider(ctx, cfg.OIDC)
+	if err != nil {
+		return nil, err
+	}
+
+	collectionRepository := collectionRepositories.NewGormCollectionRepository(gormDB)
+	environmentRepository := environmentRepositories.NewGormEnvironmentRepository(gormDB)
+	requestRepository := requestRepositories.NewGormRequestRepository(gormDB)
+	monitorRepository := monitorRepositories.NewGormMonitorRepository(gormDB)
+	webhookRepository := webhookRepositories.NewGormWebhookRepository(gormDB)
+	shareRepository := shareRepositories.NewGormShareRepository(gormDB)
+
+	app.Collections = collectionUsecases.NewCollectionCommandUsecase(collectionRepository)
+	app.Environments = environmentUsecases.NewEnvironmentCommandUsecase(environmentRepository, app.Collections)
+	app.Requests = requestUsecases.NewRequestCommandUsecase(requestRepository, app.Collections, &http.Client{Timeout: cfg.Timeouts.Request}, cfg.Timeouts.Run)
+	app.Mocks = mockUsecases.NewMockCommandUsecase(mockRepositories.NewGormMockRepository(gormDB), app.Requests)
+	app.Webhooks = webhookUsecases.NewWebhookCommandUsecase(webhookRepository, app.Collections)
+	app.Dispatcher = webhookUsecases.NewWebhookDispatcher(webhookRepository, webhookHelpers.NewClient(cfg.Webhooks.Timeout, cfg.Webhooks.AllowPrivateTargets))
+	app.Monitors = monitorUsecases.NewMonitorCommandUsecase(monitorRepository, app.Collections, app.Environments, app.Requests, app.Dispatcher)
+	app.Shares = shareUsecases.NewShareCommandUsecase(shareRepository, app.Collections)
+	app.Users = userUsecases.NewUserCommandUsecase(userRepositories.NewGormUserRepository(gormDB), keys, sender, provider, cfg.AppURL, userUsecases.AccountData{
+		Collections: collectionRepository,
+		ShareLinks:  shareRepository,
+		Records:     []userUsecases.CollectionRecords{requestRepository, environmentRepository, monitorRepository, webhookRepository, shareRepository},
+	})
+	app.Workspaces = workspaceUsecases.NewWorkspaceCommandUsecase(workspaceRepositories.NewGormWorkspaceRepository(gormDB), sender, cfg.AppURL)
+
+	app.Auth = middlewares.NewAuth(keys, middlewares.NewGormAuthRepository(gormDB), app.Workspaces)
+	app.Scheduler = monitorUsecases.NewMonitorScheduler(app.Monitors, gormDB)
+	app.Scheduler.Tasks = append(app.Scheduler.Tasks, app.Users.PruneLoginAttempts)
+
+	router, err := app.router()
+	if err != nil {
+		return nil, err
+	}
+
+	app.Server = &http.Server{
+		Addr:         cfg.Server.Addr,
+		Handler:      router,
+		ReadTimeout:  cfg.Timeouts.Read,
+		WriteTimeout: cfg.Timeouts.Write,
+		IdleTimeout:  cfg.Timeouts.Idle,
+	}
+
+	return app, nil
+}
+
+// router registers the routes of every module.
+func (a *App) router() (*gin.Engine, error) {
+	if a.Config.Log.Level != "debug" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	router := gin.New()
+
+	// Only the configured proxies can set the client IP the login limits
+	// count attempts by
+	if err := router.SetTrustedProxies(a.Config.Server.TrustedProxies); err != nil {
+		return nil, err
+	}
+
+	router.Use(middlewares.RequestIDMiddleware())
+	router.Use(middlewares.RequestLoggerMiddleware())
+	router.Use(middlewares.RecoveryMiddleware())
+	router.Use(middlewares.CORSMiddleware(a.Config.CORS.AllowedOrigins))
+	router.Use(middlewares.SetJSONContentTypeMiddleware())
+
+	userHandler.InitUserHttpHandler(router, a.Auth, userHandler.NewUserHttpHandler(a.Users))
+	collectionHandler.InitCollectionHttpHandler(router, a.Auth, collectionHandler.NewCollectionHttpHandler(a.Collections, a.Workspaces))
+	requestHandler.InitRequestHttpHandler(router, a.Auth, requestHandler.NewRequestHttpHandler(a.Requests, a.Workspaces, a.Environments, a.Dispatcher))
+	mockHandler.InitMockHttpHandler(router, a.Auth, mockHandler.NewMockHttpHandler(a.Mocks))
+	environmentHandler.InitEnvironmentHttpHandler(router, a.Auth, environmentHandler.NewEnvironmentHttpHandler(a.Environments))
+	monitorHandler.InitMonitorHttpHandler(router, a.Auth, monitorHandler.NewMonitorHttpHandler(a.Monitors))
+	webhookHandler.InitWebhookHttpHandler(router, a.Auth, webhookHandler.NewWebhookHttpHandler(a.Webhooks, a.Dispatcher))
+	workspaceHandler.InitWorkspaceHttpHandler(router, a.Auth, workspaceHandler.NewWorkspaceHttpHandler(a.Workspaces))
+	shareHandler.InitShareHttpHandler(router, a.Auth, shareHandler.NewShareHttpHandler(a.Shares))
+
+	return router, nil
+}
+
+// Run serves the API and runs scheduled monitors until ctx is cancelled,
+// then stops taking requests and waits for the in-flight ones, the monitor
+// runs and the webhook deliveries for up to the shutdown timeout. The
+// database is closed whatever happens.
+func (a *App) Run(ctx context.Context) error {
+	err := a.serve(ctx)
+	if closeErr := a.closeDB(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (a *App) serve(ctx context.Context) error {
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+	a.Scheduler.Start(schedulerCtx)
+
+	served := make(chan error, 1)
+	go func() {
+		slog.Info("Listening", "addr", a.Server.Addr)
+		served <- a.Server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down, waiting for in-flight requests and background runs", "timeout", a.Config.Timeouts.Shutdown.String())
+
+	// A zero shutdown timeout waits as long as it takes
+	shutdownCtx := context.Background()
+	if a.Config.Timeouts.Shutdown > 0 {
+		var cancel context.CancelFunc
+		shutdownCtx, cancel = context.WithTimeout(shutdownCtx, a.Config.Timeouts.Shutdown)
+		defer cancel()
+	}
+
+	// Stop accepting connections and wait for the requests being served
+	err := a.Server.Shutdown(shutdownCtx)
+	if err != nil {
+		return err
+	}
+
+	// Stop scheduling monitors and wait for the runs and deliveries started
+	stopScheduler()
+	drained := make(chan struct{})
+	go func() {
+		a.Scheduler.Wait()
+		a.Dispatcher.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-shutdownCtx.Done():
+		// Give up the deliveries still waiting to retry
+		a.Dispatcher.Stop()
+		return ErrShutdownTimeout
+	}
+}
+
+func (a *App) closeDB() error {
+	sqlDB, err := a.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
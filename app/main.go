@@ -1,26 +1,69 @@
 package main
 
 import (
-	"github.com/gin-gonic/gin"
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jeksilaen/api-builder/config"
 	db "github.com/jeksilaen/api-builder/db"
-	middlewares "github.com/jeksilaen/api-builder/middlewares"
-	userHandler "github.com/jeksilaen/api-builder/modules/user/handlers"
-	collectionHandler "github.com/jeksilaen/api-builder/modules/collection/handlers"
-	requestHandler "github.com/jeksilaen/api-builder/modules/request/handlers"
+	"github.com/jeksilaen/api-builder/logging"
 )
 
 func main() {
-	err := db.InitDB()
+	// Read the configuration from CONFIG_FILE and the environment
+	cfg, err := config.Load(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Write JSON logs from the configured level up
+	err = logging.InitLogger(cfg.Log.Level)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Manage the schema with api-builder migrate up, down or status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	gormDB, err := db.Open(cfg.Database.Driver, cfg.Database.DSN)
 	if err != nil {
-		panic(err)
+		log.Fatal(err)
 	}
 
-	router := gin.Default()
-	router.Use(middlewares.SetJSONContentTypeMiddleware())
+	// Refuse to run against a schema missing migrations
+	err = db.CheckSchema(gormDB)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	userHandler.InitUserHttpHandler(router)
-	collectionHandler.InitCollectionHttpHandler(router)
-	requestHandler.InitRequestHttpHandler(router)
+	app, err := NewApp(context.Background(), cfg, gormDB)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	router.Run("localhost:8080")
+	// Move collections created before workspaces into personal workspaces
+	err = app.Workspaces.MigrateCollections(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Drain the server and the background runs on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := app.Run(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+	slog.Info("Server stopped")
 }
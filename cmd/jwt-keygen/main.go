@@ -0,0 +1,107 @@
+// Command jwt-keygen writes a new private key for signing access tokens into
+// the JWT keys directory, named after its key ID:
+//
+//	go run ./cmd/jwt-keygen -alg EdDSA -kid 2026-10
+//
+// Start the server with JWT_SIGNING_KEY_ID set to the new key ID to sign
+// with it, keeping the previous key in the directory until the tokens it
+// signed have expired. -public writes the public key of an existing key, to
+// keep verifying with a key whose private part was removed.
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/jeksilaen/api-builder/config"
+	"github.com/jeksilaen/api-builder/middlewares"
+)
+
+func main() {
+	cfg, err := config.Read(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	dir := flag.String("dir", cfg.JWT.KeysDir, "JWT keys directory")
+	alg := flag.String("alg", middlewares.AlgorithmEdDSA, "algorithm of the key, RS256 or EdDSA")
+	kid := flag.String("kid", "", "ID of the key")
+	public := flag.Bool("public", false, "replace the private key -kid by its public key")
+	flag.Parse()
+
+	if *kid == "" {
+		log.Fatal("-kid is required")
+	}
+	path := filepath.Join(*dir, *kid+".pem")
+
+	if *public {
+		writePublicKey(path)
+		return
+	}
+
+	var private interface{}
+	switch *alg {
+	case middlewares.AlgorithmRS256:
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			log.Fatal(err)
+		}
+		private = key
+	case middlewares.AlgorithmEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			log.Fatal(err)
+		}
+		private = key
+	default:
+		log.Fatalf("unsupported algorithm %q", *alg)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.MkdirAll(*dir, 0o700); err != nil {
+		log.Fatal(err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	if err := pem.Encode(file, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Wrote %s key %s", *alg, path)
+}
+
+func writePublicKey(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	key, err := middlewares.ParseKey(filepath.Base(path), data)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(key.Public)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Replaced %s by its public key", path)
+}
@@ -0,0 +1,194 @@
+// Command mock-idp is an OpenID Connect identity provider for trying single
+// sign-on locally. It logs everyone in as the same user without asking:
+//
+//	go run ./cmd/mock-idp -addr :9000 -email jane@example.com
+//
+// and start the server with OIDC_ISSUER=http://localhost:9000 and
+// OIDC_CLIENT_ID=api-builder. Codes require PKCE with S256.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jeksilaen/api-builder/middlewares"
+	"github.com/jeksilaen/api-builder/sso"
+)
+
+// grant is an authorization code waiting to be exchanged.
+type grant struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	expiresAt     time.Time
+}
+
+// identityProvider serves the endpoints of the mock IdP.
+type identityProvider struct {
+	issuer   string
+	subject  string
+	email    string
+	verified bool
+	username string
+	keys     *middlewares.KeySet
+
+	mu     sync.Mutex
+	grants map[string]grant
+}
+
+func main() {
+	addr := flag.String("addr", ":9000", "address to listen on")
+	issuer := flag.String("issuer", "http://localhost:9000", "issuer URL, the address clients reach the IdP at")
+	subject := flag.String("subject", "mock-user", "subject of the logged in user")
+	email := flag.String("email", "user@example.com", "email of the logged in user")
+	verified := flag.Bool("email-verified", true, "whether the email is verified")
+	username := flag.String("username", "", "preferred username of the logged in user")
+	flag.Parse()
+
+	keys, err := middlewares.NewTemporaryKeySet()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	idp := &identityProvider{
+		issuer:   *issuer,
+		subject:  *subject,
+		email:    *email,
+		verified: *verified,
+		username: *username,
+		keys:     keys,
+		grants:   map[string]grant{},
+	}
+
+	log.Printf("Mock IdP %s logging in %s", *issuer, *email)
+	log.Fatal(http.ListenAndServe(*addr, idp.handler()))
+}
+
+func (idp *identityProvider) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/authorize", idp.authorize)
+	mux.HandleFunc("/token", idp.token)
+	mux.HandleFunc("/jwks", idp.jwks)
+	return mux
+}
+
+func (idp *identityProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                idp.issuer,
+		"authorization_endpoint":                idp.issuer + "/authorize",
+		"token_endpoint":                        idp.issuer + "/token",
+		"jwks_uri":                              idp.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{middlewares.AlgorithmEdDSA},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// authorize approves every request and redirects back with a code.
+func (idp *identityProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "only the code flow with S256 PKCE is supported", http.StatusBadRequest)
+		return
+	}
+
+	code, err := sso.NewRandomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	idp.mu.Lock()
+	idp.grants[code] = grant{
+		clientID:      query.Get("client_id"),
+		redirectURI:   redirectURI.String(),
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	idp.mu.Unlock()
+
+	back := redirectURI.Query()
+	back.Set("code", code)
+	back.Set("state", query.Get("state"))
+	redirectURI.RawQuery = back.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+// token exchanges a code for an ID token.
+func (idp *identityProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	clientID, _, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.PostForm.Get("client_id")
+	}
+
+	idp.mu.Lock()
+	g, found := idp.grants[r.PostForm.Get("code")]
+	delete(idp.grants, r.PostForm.Get("code"))
+	idp.mu.Unlock()
+
+	if !found || g.expiresAt.Before(time.Now()) || g.clientID != clientID ||
+		g.redirectURI != r.PostForm.Get("redirect_uri") ||
+		sso.CodeChallenge(r.PostForm.Get("code_verifier")) != g.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            idp.issuer,
+		"sub":            idp.subject,
+		"aud":            clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"nonce":          g.nonce,
+		"email":          idp.email,
+		"email_verified": idp.verified,
+	}
+	if idp.username != "" {
+		claims["preferred_username"] = idp.username
+	}
+
+	idToken, err := idp.keys.Sign(claims)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	accessToken, _ := sso.NewRandomString()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func (idp *identityProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, idp.keys.JWKS())
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,78 @@
+// Command webhook-receiver is a local endpoint for trying out webhooks. It
+// checks the signature of every delivery and prints its payload. Use -fail
+// to answer the first deliveries with a 500 and watch the retries:
+//
+//	go run ./cmd/webhook-receiver -secret whsec_... -fail 2
+//
+// The server only posts to localhost with webhooks.allow_private_targets
+// (WEBHOOK_ALLOW_PRIVATE_TARGETS=true).
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jeksilaen/api-builder/modules/webhook/helpers"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:9090", "address to listen on")
+	secret := flag.String("secret", "", "signing secret of the webhook")
+	fail := flag.Int("fail", 0, "number of deliveries to answer with a 500")
+	tolerance := flag.Duration("tolerance", 5*time.Minute, "accepted clock skew of the timestamp")
+	flag.Parse()
+
+	if *secret == "" {
+		log.Fatal("-secret is required")
+	}
+
+	var mu sync.Mutex
+	remainingFailures := *fail
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		event := r.Header.Get(helpers.EventHeader)
+		delivery := r.Header.Get(helpers.DeliveryHeader)
+
+		err = helpers.VerifySignature(*secret, r.Header.Get(helpers.SignatureHeader), r.Header.Get(helpers.TimestampHeader), body, *tolerance, time.Now())
+		if err != nil {
+			log.Printf("rejected %s delivery %s: %v", event, delivery, err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		mu.Lock()
+		failing := remainingFailures > 0
+		if failing {
+			remainingFailures--
+		}
+		mu.Unlock()
+
+		if failing {
+			log.Printf("failing %s delivery %s on purpose", event, delivery)
+			http.Error(w, "failing on purpose", http.StatusInternalServerError)
+			return
+		}
+
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, body, "", "  "); err != nil {
+			pretty.Write(body)
+		}
+		log.Printf("verified %s delivery %s\n%s", event, delivery, pretty.String())
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	log.Println("Listening on", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
@@ -1,9 +1,287 @@
 package config
 
-const (
-	DBHost     = "localhost"
-	DBPort     = 5432
-	DBUser     = "postgres"
-	DBPassword = "050502"
-	DBName     = "api-builder"
-)
\ No newline at end of file
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the configuration of the server. It is read from an optional
+// YAML file, then from the environment variables named in the env tags,
+// which win over the file.
+type Config struct {
+	// AppURL is the address of the web app, used in the links sent by email
+	AppURL   string         `yaml:"app_url" env:"APP_URL"`
+	Server   ServerConfig   `yaml:"server"`
+	Database DatabaseConfig `yaml:"database"`
+	JWT      JWTConfig      `yaml:"jwt"`
+	CORS     CORSConfig     `yaml:"cors"`
+	Log      LogConfig      `yaml:"log"`
+	Mail     MailConfig     `yaml:"mail"`
+	OIDC     OIDCConfig     `yaml:"oidc"`
+	Timeouts TimeoutsConfig `yaml:"timeouts"`
+	Webhooks WebhooksConfig `yaml:"webhooks"`
+}
+
+// ServerConfig sets up the listener. TrustedProxies lists the addresses or
+// CIDR ranges of the reverse proxies whose X-Forwarded-For header gives the
+// client IP; with none the IP of the connection is used.
+type ServerConfig struct {
+	Addr           string   `yaml:"addr" env:"SERVER_ADDR"`
+	TrustedProxies []string `yaml:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES"`
+}
+
+// DatabaseConfig picks the database. Driver is postgres, or sqlite to run
+// without a database server, in which case DSN is the path of the database
+// file.
+type DatabaseConfig struct {
+	Driver string `yaml:"driver" env:"DATABASE_DRIVER"`
+	DSN    string `yaml:"dsn" env:"DATABASE_DSN"`
+}
+
+// JWTConfig locates the keys signing access tokens.
+//
+// KeysDir holds one PEM file per key, named after its key ID: private keys
+// (RSA or Ed25519) can sign, public keys only verify tokens. To rotate, add
+// the new private key, point SigningKeyID at it, and remove the old key once
+// the access tokens it signed have expired.
+type JWTConfig struct {
+	KeysDir      string `yaml:"keys_dir" env:"JWT_KEYS_DIR"`
+	SigningKeyID string `yaml:"signing_key_id" env:"JWT_SIGNING_KEY_ID"`
+}
+
+// CORSConfig lists the origins of the browser apps allowed to call the API.
+// "*" allows every origin; no origin turns CORS off.
+type CORSConfig struct {
+	AllowedOrigins []string `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+}
+
+type LogConfig struct {
+	// Level is debug, info, warn or error
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+}
+
+// MailConfig sets up the emails. Sender is smtp, file or log; the file and
+// log senders are meant for local development.
+type MailConfig struct {
+	Sender       string `yaml:"sender" env:"MAIL_SENDER"`
+	From         string `yaml:"from" env:"MAIL_FROM"`
+	Dir          string `yaml:"dir" env:"MAIL_DIR"`
+	SMTPHost     string `yaml:"smtp_host" env:"SMTP_HOST"`
+	SMTPPort     int    `yaml:"smtp_port" env:"SMTP_PORT"`
+	SMTPUsername string `yaml:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword string `yaml:"smtp_password" env:"SMTP_PASSWORD"`
+}
+
+// OIDCConfig sets up OpenID Connect single sign-on, which is off while
+// Issuer is empty. The IdP redirects to RedirectURL in the web app, which
+// posts the code and state back to the API.
+type OIDCConfig struct {
+	Issuer       string   `yaml:"issuer" env:"OIDC_ISSUER"`
+	ClientID     string   `yaml:"client_id" env:"OIDC_CLIENT_ID"`
+	ClientSecret string   `yaml:"client_secret" env:"OIDC_CLIENT_SECRET"`
+	RedirectURL  string   `yaml:"redirect_url" env:"OIDC_REDIRECT_URL"`
+	Scopes       []string `yaml:"scopes" env:"OIDC_SCOPES"`
+}
+
+// TimeoutsConfig bounds how long connections and outgoing calls can take.
+type TimeoutsConfig struct {
+	// Read, Write and Idle limit the connections of the API clients
+	Read  time.Duration `yaml:"read" env:"SERVER_READ_TIMEOUT"`
+	Write time.Duration `yaml:"write" env:"SERVER_WRITE_TIMEOUT"`
+	Idle  time.Duration `yaml:"idle" env:"SERVER_IDLE_TIMEOUT"`
+	// Request limits the requests executed for the users
+	Request time.Duration `yaml:"request" env:"REQUEST_TIMEOUT"`
+	// Run limits the collection runs users start, whose report is written
+	// once every request is done: it must end before Write
+	Run time.Duration `yaml:"run" env:"RUN_TIMEOUT"`
+	// Shutdown limits how long in-flight requests and background runs are
+	// waited for on SIGTERM
+	Shutdown time.Duration `yaml:"shutdown" env:"SHUTDOWN_TIMEOUT"`
+}
+
+// WebhooksConfig sets up the delivery of the alert webhooks.
+type WebhooksConfig struct {
+	// Timeout limits each delivery attempt
+	Timeout time.Duration `yaml:"timeout" env:"WEBHOOK_TIMEOUT"`
+	// AllowPrivateTargets lets webhooks post to loopback and private
+	// addresses, like cmd/webhook-receiver on localhost. Keep it off where
+	// users could reach the services next to the server.
+	AllowPrivateTargets bool `yaml:"allow_private_targets" env:"WEBHOOK_ALLOW_PRIVATE_TARGETS"`
+}
+
+// Log levels.
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// Database drivers.
+var databaseDrivers = []string{"postgres", "sqlite"}
+
+// Default returns the configuration used for what isn't set. The database
+// DSN has no default.
+func Default() *Config {
+	return &Config{
+		AppURL:   "http://localhost:3000",
+		Server:   ServerConfig{Addr: "localhost:8080"},
+		Database: DatabaseConfig{Driver: "postgres"},
+		JWT:      JWTConfig{KeysDir: "keys"},
+		Log:      LogConfig{Level: "info"},
+		Mail: MailConfig{
+			Sender:   "log",
+			From:     "api-builder <no-reply@localhost>",
+			Dir:      "mail",
+			SMTPHost: "localhost",
+			SMTPPort: 587,
+		},
+		OIDC: OIDCConfig{Scopes: []string{"openid", "email", "profile"}},
+		Timeouts: TimeoutsConfig{
+			Read:     15 * time.Second,
+			Write:    60 * time.Second,
+			Idle:     2 * time.Minute,
+			Request:  30 * time.Second,
+			Run:      45 * time.Second,
+			Shutdown: 30 * time.Second,
+		},
+		Webhooks: WebhooksConfig{Timeout: 10 * time.Second},
+	}
+}
+
+// Load reads and validates the configuration.
+func Load(path string) (*Config, error) {
+	cfg, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Read reads the configuration from the YAML file at path, if any, and the
+// environment, without validating it.
+func Read(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading the config file: %w", err)
+		}
+		defer file.Close()
+
+		// Misspelled keys are reported instead of being ignored
+		decoder := yaml.NewDecoder(file)
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("parsing the config file %s: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.OIDC.RedirectURL == "" {
+		cfg.OIDC.RedirectURL = strings.TrimSuffix(cfg.AppURL, "/") + "/oidc/callback"
+	}
+
+	return cfg, nil
+}
+
+// Validate reports every missing or invalid value at once.
+func (cfg *Config) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(contains(databaseDrivers, cfg.Database.Driver), "database.driver %q is not one of %s", cfg.Database.Driver, strings.Join(databaseDrivers, ", "))
+	check(cfg.Database.DSN != "", "database.dsn is required, set it in the config file or DATABASE_DSN")
+	check(cfg.Server.Addr != "", "server.addr is required, set it in the config file or SERVER_ADDR")
+	check(cfg.JWT.KeysDir != "", "jwt.keys_dir is required, set it in the config file or JWT_KEYS_DIR")
+	check(isURL(cfg.AppURL), "app_url %q is not an absolute URL", cfg.AppURL)
+	check(contains(logLevels, cfg.Log.Level), "log.level %q is not one of %s", cfg.Log.Level, strings.Join(logLevels, ", "))
+
+	for _, proxy := range cfg.Server.TrustedProxies {
+		check(isIPOrCIDR(proxy), "server.trusted_proxies: %q is neither an IP address nor a CIDR range", proxy)
+	}
+
+	for _, origin := range cfg.CORS.AllowedOrigins {
+		check(origin == "*" || isURL(origin), "cors.allowed_origins: %q is neither * nor an origin like https://app.example.com", origin)
+	}
+
+	switch cfg.Mail.Sender {
+	case "smtp":
+		check(cfg.Mail.SMTPHost != "", "mail.smtp_host is required by the smtp sender, set it in the config file or SMTP_HOST")
+		check(cfg.Mail.SMTPPort > 0, "mail.smtp_port %d is not a port", cfg.Mail.SMTPPort)
+	case "file":
+		check(cfg.Mail.Dir != "", "mail.dir is required by the file sender, set it in the config file or MAIL_DIR")
+	case "log":
+	default:
+		check(false, "mail.sender %q is not one of smtp, file, log", cfg.Mail.Sender)
+	}
+
+	if cfg.OIDC.Issuer != "" {
+		check(isURL(cfg.OIDC.Issuer), "oidc.issuer %q is not an absolute URL", cfg.OIDC.Issuer)
+		check(cfg.OIDC.ClientID != "", "oidc.client_id is required with oidc.issuer, set it in the config file or OIDC_CLIENT_ID")
+		check(isURL(cfg.OIDC.RedirectURL), "oidc.redirect_url %q is not an absolute URL", cfg.OIDC.RedirectURL)
+		check(contains(cfg.OIDC.Scopes, "openid"), "oidc.scopes must include openid")
+	}
+
+	timeouts := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"timeouts.read", cfg.Timeouts.Read},
+		{"timeouts.write", cfg.Timeouts.Write},
+		{"timeouts.idle", cfg.Timeouts.Idle},
+		{"timeouts.request", cfg.Timeouts.Request},
+		{"timeouts.run", cfg.Timeouts.Run},
+		{"timeouts.shutdown", cfg.Timeouts.Shutdown},
+		{"webhooks.timeout", cfg.Webhooks.Timeout},
+	}
+	for _, timeout := range timeouts {
+		check(timeout.value >= 0, "%s %s can't be negative", timeout.name, timeout.value)
+	}
+	if cfg.Timeouts.Write > 0 {
+		check(cfg.Timeouts.Run > 0 && cfg.Timeouts.Write > cfg.Timeouts.Run, "timeouts.write %s must be longer than timeouts.run %s, or collection runs are cut off before their report", cfg.Timeouts.Write, cfg.Timeouts.Run)
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration:\n  - " + strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+func isURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+func isIPOrCIDR(value string) bool {
+	if net.ParseIP(value) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(value)
+	return err == nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func validConfig() *Config {
+	cfg := Default()
+	cfg.Database.DSN = "postgres://localhost/api_builder"
+	return cfg
+}
+
+func TestValidateDefault(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := Default().Validate(); err == nil || !strings.Contains(err.Error(), "database.dsn is required") {
+		t.Errorf("default without a DSN: %v", err)
+	}
+}
+
+func TestValidateTrustedProxies(t *testing.T) {
+	cfg := validConfig()
+	cfg.Server.TrustedProxies = []string{"10.0.0.1", "10.0.0.0/8", "fd00::/8", "::1"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid proxies: %v", err)
+	}
+
+	for _, proxy := range []string{"proxy.internal", "10.0.0.0/33", "10.0.0", ""} {
+		cfg.Server.TrustedProxies = []string{"10.0.0.1", proxy}
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "server.trusted_proxies") {
+			t.Errorf("%q: %v", proxy, err)
+		}
+	}
+}
+
+func TestValidateRunFitsWriteTimeout(t *testing.T) {
+	tests := []struct {
+		write, run time.Duration
+		ok         bool
+	}{
+		{60 * time.Second, 45 * time.Second, true},
+		{60 * time.Second, 60 * time.Second, false},
+		{30 * time.Second, 45 * time.Second, false},
+		{60 * time.Second, 0, false},
+		{0, 0, true},
+	}
+
+	for _, test := range tests {
+		cfg := validConfig()
+		cfg.Timeouts.Write = test.write
+		cfg.Timeouts.Run = test.run
+		err := cfg.Validate()
+		if test.ok && err != nil {
+			t.Errorf("write %s, run %s: %v", test.write, test.run, err)
+		}
+		if !test.ok && (err == nil || !strings.Contains(err.Error(), "timeouts.write")) {
+			t.Errorf("write %s, run %s: error = %v, want timeouts.write reported", test.write, test.run, err)
+		}
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := validConfig()
+	cfg.Database.Driver = "mysql"
+	cfg.Log.Level = "verbose"
+	cfg.CORS.AllowedOrigins = []string{"app.example.com"}
+	cfg.Mail.Sender = "pigeon"
+	cfg.OIDC.Issuer = "https://idp.example.com"
+	cfg.Timeouts.Request = -time.Second
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("no error")
+	}
+	for _, want := range []string{"database.driver", "log.level", "cors.allowed_origins", "mail.sender", "oidc.client_id", "timeouts.request"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("%s is not reported in:\n%s", want, err)
+		}
+	}
+}
+
+func TestReadFileAndEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	file := `
+database:
+  driver: sqlite
+  dsn: api-builder.db
+server:
+  trusted_proxies: ["10.0.0.1"]
+log:
+  level: warn
+timeouts:
+  request: 5s
+`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SERVER_TRUSTED_PROXIES", "10.0.0.0/8, 192.168.0.1")
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE_TARGETS", "true")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Database.Driver != "sqlite" {
+		t.Errorf("database driver = %q, want sqlite", cfg.Database.Driver)
+	}
+	if cfg.Timeouts.Request != 5*time.Second {
+		t.Errorf("request timeout = %s, want 5s", cfg.Timeouts.Request)
+	}
+	if cfg.Log.Level != "debug" {
+		t.Errorf("log level = %q, want the environment to win", cfg.Log.Level)
+	}
+	if proxies := strings.Join(cfg.Server.TrustedProxies, " "); proxies != "10.0.0.0/8 192.168.0.1" {
+		t.Errorf("trusted proxies = %q, want the environment to win", proxies)
+	}
+	if !cfg.Webhooks.AllowPrivateTargets || Default().Webhooks.AllowPrivateTargets {
+		t.Errorf("private webhook targets allowed = %v, want them off by default", cfg.Webhooks.AllowPrivateTargets)
+	}
+	if cfg.OIDC.RedirectURL != "http://localhost:3000/oidc/callback" {
+		t.Errorf("redirect URL = %q", cfg.OIDC.RedirectURL)
+	}
+}
+
+func TestReadRefusesUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("server:\n  trusted_proxy: 10.0.0.1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read(path); err == nil {
+		t.Error("misspelled key was accepted")
+	}
+}
+
+func TestReadRefusesInvalidEnvironment(t *testing.T) {
+	t.Setenv("REQUEST_TIMEOUT", "soon")
+	if _, err := Read(""); err == nil || !strings.Contains(err.Error(), "REQUEST_TIMEOUT") {
+		t.Errorf("error = %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+// Package dbtest gives the tests of the repositories a database to run on.
+package dbtest
+
+import (
+	"testing"
+
+	"github.com/jeksilaen/api-builder/db"
+	"gorm.io/gorm"
+)
+
+// Open returns a migrated in-memory SQLite database, closed at the end of
+// the test.
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	gormDB, err := db.Open(db.DriverSQLite, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := gormDB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if _, err := db.MigrateUp(gormDB); err != nil {
+		t.Fatal(err)
+	}
+	return gormDB
+}
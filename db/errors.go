@@ -0,0 +1,105 @@
+package db
+
+import (
+	"errors"
+
+	sqliteDriver "github.com/glebarez/go-sqlite"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Errors the repositories return, whatever the driver of the database.
+var (
+	// ErrNotFound is returned when no record matches a lookup
+	ErrNotFound = gorm.ErrRecordNotFound
+	// ErrDuplicate is returned when a record breaks a unique index
+	ErrDuplicate = gorm.ErrDuplicatedKey
+	// ErrInvalidReference is returned when a record points at a record that
+	// doesn't exist
+	ErrInvalidReference = gorm.ErrForeignKeyViolated
+)
+
+// errInvalidID stands for ids that can't be a uuid, which only Postgres
+// checks. Nothing has such an id: lookups find nothing and references to
+// it are invalid.
+var errInvalidID = errors.New("invalid id")
+
+// registerErrorTranslation replaces the constraint errors of the driver with
+// the errors above once each statement ran.
+func registerErrorTranslation(gormDB *gorm.DB, translate func(err error) error) error {
+	callbacks := gormDB.Callback()
+
+	lookup := func(tx *gorm.DB) {
+		if tx.Error == nil {
+			return
+		}
+		tx.Error = translate(tx.Error)
+		if errors.Is(tx.Error, errInvalidID) {
+			tx.Error = ErrNotFound
+		}
+	}
+	write := func(tx *gorm.DB) {
+		if tx.Error == nil {
+			return
+		}
+		tx.Error = translate(tx.Error)
+		if errors.Is(tx.Error, errInvalidID) {
+			tx.Error = ErrInvalidReference
+		}
+	}
+
+	if err := callbacks.Query().After("gorm:query").Register("db:translate_error", lookup); err != nil {
+		return err
+	}
+	if err := callbacks.Row().After("gorm:row").Register("db:translate_error", lookup); err != nil {
+		return err
+	}
+	if err := callbacks.Create().After("gorm:create").Register("db:translate_error", write); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("db:translate_error", write); err != nil {
+		return err
+	}
+	return callbacks.Delete().After("gorm:delete").Register("db:translate_error", write)
+}
+
+// Postgres error codes, see
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgInvalidText         = "22P02"
+)
+
+func translatePostgresError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		return ErrDuplicate
+	case pgForeignKeyViolation:
+		return ErrInvalidReference
+	case pgInvalidText:
+		return errInvalidID
+	}
+	return err
+}
+
+func translateSQLiteError(err error) error {
+	var sqliteErr *sqliteDriver.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		return ErrDuplicate
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return ErrInvalidReference
+	}
+	return err
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+// LeaderLock elects one leader among the server instances sharing a
+// database.
+type LeaderLock interface {
+	// Acquire reports whether this instance holds the lock, trying to take
+	// it when it doesn't
+	Acquire(ctx context.Context) bool
+	// Release gives the lock up
+	Release()
+}
+
+// NewLeaderLock returns the lock named key. Postgres databases use an
+// advisory lock; a SQLite database belongs to a single server, which always
+// leads.
+func NewLeaderLock(gormDB *gorm.DB, key int64) LeaderLock {
+	if gormDB.Dialector.Name() == DriverSQLite {
+		return soleLeader{}
+	}
+	return &advisoryLock{DB: gormDB, Key: key}
+}
+
+type soleLeader struct{}
+
+func (soleLeader) Acquire(ctx context.Context) bool { return true }
+
+func (soleLeader) Release() {}
+
+// advisoryLock is a Postgres advisory lock. The lock lives as long as the
+// session it was taken on, so a dedicated connection is kept while leading.
+type advisoryLock struct {
+	DB  *gorm.DB
+	Key int64
+
+	conn *sql.Conn
+}
+
+func (l *advisoryLock) Acquire(ctx context.Context) bool {
+	if l.conn != nil {
+		if err := l.conn.PingContext(ctx); err == nil {
+			return true
+		}
+		slog.Warn("Lost the connection holding leader lock", "key", l.Key)
+		l.conn.Close()
+		l.conn = nil
+	}
+
+	sqlDB, err := l.DB.DB()
+	if err != nil {
+		slog.Error("Failed to get database for leader lock", "error", err)
+		return false
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		slog.Error("Failed to open connection for leader lock", "error", err)
+		return false
+	}
+
+	var locked bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.Key).Scan(&locked)
+	if err != nil || !locked {
+		if err != nil {
+			slog.Error("Failed to take leader lock", "error", err)
+		}
+		conn.Close()
+		return false
+	}
+
+	l.conn = conn
+	return true
+}
+
+func (l *advisoryLock) Release() {
+	if l.conn == nil {
+		return
+	}
+
+	_, err := l.conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", l.Key)
+	if err != nil {
+		slog.Error("Failed to release leader lock", "error", err)
+	}
+	l.conn.Close()
+	l.conn = nil
+}
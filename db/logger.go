@@ -0,0 +1,26 @@
+package db
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// queryLogger logs the failed and slow queries to slog, without the values
+// bound to them so secrets stay out of the logs.
+var queryLogger = logger.New(slogWriter{}, logger.Config{
+	SlowThreshold:             200 * time.Millisecond,
+	LogLevel:                  logger.Warn,
+	IgnoreRecordNotFoundError: true,
+	ParameterizedQueries:      true,
+})
+
+// slogWriter hands the messages of the gorm logger to slog.
+type slogWriter struct{}
+
+func (slogWriter) Printf(format string, args ...interface{}) {
+	slog.Warn(strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
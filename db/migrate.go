@@ -0,0 +1,244 @@
+package db
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationFiles holds the migrations of each driver, as
+// migrations/<driver>/<version>_<name>.up.sql and .down.sql.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// ErrSchemaOutdated is returned when the database misses migrations of this
+// build.
+var ErrSchemaOutdated = errors.New("database schema is not up to date, run the migrate up command")
+
+// Migration is a versioned change of the schema with the SQL undoing it.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus tells whether a migration was applied to the database.
+type MigrationStatus struct {
+	Migration
+	// AppliedAt is nil while the migration is pending
+	AppliedAt *time.Time
+}
+
+// schemaMigration records a migration applied to the database.
+type schemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrations returns the migrations of a driver, oldest first.
+func Migrations(driver string) ([]Migration, error) {
+	dir := path.Join("migrations", driver)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for database driver %q", driver)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s is not named <version>_<name>.up.sql or .down.sql", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", migration.Name, match[2], version)
+		}
+
+		sql, err := fs.ReadFile(migrationFiles, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if match[3] == "up" {
+			migration.Up = string(sql)
+		} else {
+			migration.Down = string(sql)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Status lists the migrations of the database driver with when they were
+// applied.
+func Status(gormDB *gorm.DB) ([]MigrationStatus, error) {
+	migrations, err := Migrations(gormDB.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(gormDB)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, migration := range migrations {
+		statuses[i].Migration = migration
+		if record, ok := applied[migration.Version]; ok {
+			statuses[i].AppliedAt = &record.AppliedAt
+		}
+	}
+	return statuses, nil
+}
+
+// MigrateUp applies the pending migrations in order, each in a
+// transaction, and returns them.
+func MigrateUp(gormDB *gorm.DB) ([]Migration, error) {
+	statuses, err := Status(gormDB)
+	if err != nil {
+		return nil, err
+	}
+	if !gormDB.Migrator().HasTable(&schemaMigration{}) {
+		if err := gormDB.Migrator().CreateTable(&schemaMigration{}); err != nil {
+			return nil, err
+		}
+	}
+
+	var done []Migration
+	for _, status := range statuses {
+		if status.AppliedAt != nil {
+			continue
+		}
+
+		migration := status.Migration
+		err := gormDB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// MigrateDown rolls back the last steps applied migrations, newest first,
+// and returns them.
+func MigrateDown(gormDB *gorm.DB, steps int) ([]Migration, error) {
+	migrations, err := Migrations(gormDB.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(gormDB)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]int, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+	if steps < len(versions) {
+		versions = versions[:steps]
+	}
+
+	var done []Migration
+	for _, version := range versions {
+		i := sort.Search(len(migrations), func(i int) bool {
+			return migrations[i].Version >= version
+		})
+		if i == len(migrations) || migrations[i].Version != version {
+			return done, fmt.Errorf("migration %d_%s is not part of this build", version, applied[version].Name)
+		}
+
+		migration := migrations[i]
+		err := gormDB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{Version: migration.Version}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// CheckSchema fails with ErrSchemaOutdated unless every migration was
+// applied.
+func CheckSchema(gormDB *gorm.DB) error {
+	statuses, err := Status(gormDB)
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%w (%d of %d migrations pending)", ErrSchemaOutdated, pending, len(statuses))
+	}
+	return nil
+}
+
+// appliedMigrations returns the migrations recorded in the database by
+// version.
+func appliedMigrations(gormDB *gorm.DB) (map[int]schemaMigration, error) {
+	applied := map[int]schemaMigration{}
+	if !gormDB.Migrator().HasTable(&schemaMigration{}) {
+		return applied, nil
+	}
+
+	var records []schemaMigration
+	if err := gormDB.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
@@ -0,0 +1,135 @@
+package db_test
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/jeksilaen/api-builder/db"
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	environmentModels "github.com/jeksilaen/api-builder/modules/environment/models"
+	mockModels "github.com/jeksilaen/api-builder/modules/mock/models"
+	monitorModels "github.com/jeksilaen/api-builder/modules/monitor/models"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+	shareModels "github.com/jeksilaen/api-builder/modules/share/models"
+	userModels "github.com/jeksilaen/api-builder/modules/user/models"
+	webhookModels "github.com/jeksilaen/api-builder/modules/webhook/models"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+	"gorm.io/gorm"
+)
+
+// models are every model stored in the database.
+var models = []interface{}{
+	&userModels.User{}, &userModels.RefreshToken{}, &userModels.APIKey{}, &userModels.UserToken{}, &userModels.RecoveryCode{}, &userModels.SSOLogin{},
+	&userModels.LoginAttempt{}, &userModels.AuditEvent{},
+	&workspaceModels.Workspace{}, &workspaceModels.WorkspaceMember{}, &workspaceModels.WorkspaceInvitation{},
+	&collectionModels.Collection{}, &requestModels.Request{}, &mockModels.MockExample{},
+	&environmentModels.Environment{}, &monitorModels.Monitor{}, &monitorModels.MonitorRun{},
+	&webhookModels.Webhook{}, &webhookModels.WebhookDelivery{},
+	&shareModels.ShareLink{},
+}
+
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	gormDB, err := db.Open(db.DriverSQLite, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := gormDB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return gormDB
+}
+
+// sqliteSchema describes the columns and indexes of every table, leaving
+// out the order they were created in.
+func sqliteSchema(t *testing.T, gormDB *gorm.DB) map[string]string {
+	t.Helper()
+	var tables []string
+	if err := gormDB.Raw("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name <> 'schema_migrations'").Scan(&tables).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	schema := map[string]string{}
+	for _, table := range tables {
+		var columns []struct {
+			Name    string
+			Type    string
+			Notnull int
+			Pk      int
+		}
+		gormDB.Raw(fmt.Sprintf("PRAGMA table_info(%q)", table)).Scan(&columns)
+		var described []string
+		for _, column := range columns {
+			described = append(described, fmt.Sprintf("%s %s notnull=%d pk=%d", column.Name, strings.ToLower(column.Type), column.Notnull, column.Pk))
+		}
+
+		var indexes []struct {
+			Name   string
+			Unique int
+		}
+		gormDB.Raw(fmt.Sprintf("PRAGMA index_list(%q)", table)).Scan(&indexes)
+		for _, index := range indexes {
+			if strings.HasPrefix(index.Name, "sqlite_autoindex") {
+				continue
+			}
+			var indexColumns []string
+			gormDB.Raw(fmt.Sprintf("SELECT name FROM pragma_index_info(%q)", index.Name)).Scan(&indexColumns)
+			described = append(described, fmt.Sprintf("index %s unique=%d (%s)", index.Name, index.Unique, strings.Join(indexColumns, ", ")))
+		}
+
+		sort.Strings(described)
+		schema[table] = strings.Join(described, "\n    ")
+	}
+	return schema
+}
+
+// The migrations have to create the schema the models expect, otherwise a
+// model change is missing its migration.
+func TestMigrationsMatchModels(t *testing.T) {
+	migrated := openSQLite(t)
+	if _, err := db.MigrateUp(migrated); err != nil {
+		t.Fatal(err)
+	}
+	fromModels := openSQLite(t)
+	if err := fromModels.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+
+	want, got := sqliteSchema(t, fromModels), sqliteSchema(t, migrated)
+	for table, columns := range want {
+		if got[table] != columns {
+			t.Errorf("table %s\n  migrated:\n    %s\n  models:\n    %s", table, got[table], columns)
+		}
+	}
+	for table := range got {
+		if _, ok := want[table]; !ok {
+			t.Errorf("table %s has no model", table)
+		}
+	}
+}
+
+func TestMigrateDownAndUp(t *testing.T) {
+	gormDB := openSQLite(t)
+	applied, err := db.MigrateUp(gormDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CheckSchema(gormDB); err != nil {
+		t.Fatalf("after migrating up: %v", err)
+	}
+
+	if _, err := db.MigrateDown(gormDB, len(applied)); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CheckSchema(gormDB); err == nil {
+		t.Error("CheckSchema accepted a database without migrations")
+	}
+
+	if _, err := db.MigrateUp(gormDB); err != nil {
+		t.Fatalf("migrating up again: %v", err)
+	}
+}
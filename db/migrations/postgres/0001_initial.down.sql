@@ -0,0 +1,20 @@
+DROP TABLE IF EXISTS "share_links";
+DROP TABLE IF EXISTS "webhook_deliveries";
+DROP TABLE IF EXISTS "webhooks";
+DROP TABLE IF EXISTS "monitor_runs";
+DROP TABLE IF EXISTS "monitors";
+DROP TABLE IF EXISTS "environments";
+DROP TABLE IF EXISTS "mock_examples";
+DROP TABLE IF EXISTS "requests";
+DROP TABLE IF EXISTS "collections";
+DROP TABLE IF EXISTS "workspace_invitations";
+DROP TABLE IF EXISTS "workspace_members";
+DROP TABLE IF EXISTS "workspaces";
+DROP TABLE IF EXISTS "audit_events";
+DROP TABLE IF EXISTS "login_attempts";
+DROP TABLE IF EXISTS "sso_logins";
+DROP TABLE IF EXISTS "recovery_codes";
+DROP TABLE IF EXISTS "user_tokens";
+DROP TABLE IF EXISTS "api_keys";
+DROP TABLE IF EXISTS "refresh_tokens";
+DROP TABLE IF EXISTS "users";
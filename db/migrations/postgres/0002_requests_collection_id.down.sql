@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "idx_requests_collection_id";
@@ -0,0 +1,2 @@
+-- The members given the editor role can't be told from the other editors,
+-- so they stay editors.
@@ -0,0 +1 @@
+ALTER TABLE "requests" DROP COLUMN "raw_response";
@@ -0,0 +1,2 @@
+-- Response bodies other than JSON objects, as they were received
+ALTER TABLE "requests" ADD COLUMN "raw_response" text;
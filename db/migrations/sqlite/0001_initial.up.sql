@@ -0,0 +1,344 @@
+-- Schema created by AutoMigrate up to this release. Every statement is
+-- skipped when its table or index exists, so databases AutoMigrate created
+-- are adopted as they are.
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    "email" text NOT NULL UNIQUE,
+    "username" text NOT NULL UNIQUE,
+    "password" text,
+    "email_verified_at" datetime,
+    "totp_secret" text,
+    "totp_enabled_at" datetime,
+    "totp_last_step" integer,
+    "oidc_subject" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_oidc_subject" ON "users" ("oidc_subject");
+
+CREATE TABLE IF NOT EXISTS "refresh_tokens" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    "user_id" uuid NOT NULL,
+    "family_id" uuid NOT NULL,
+    "token_hash" text NOT NULL,
+    "expires_at" datetime NOT NULL,
+    "used_at" datetime,
+    "revoked_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_deleted_at" ON "refresh_tokens" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_family_id" ON "refresh_tokens" ("family_id");
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_user_id" ON "refresh_tokens" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_refresh_tokens_token_hash" ON "refresh_tokens" ("token_hash");
+
+CREATE TABLE IF NOT EXISTS "api_keys" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    "user_id" uuid NOT NULL,
+    "name" text NOT NULL,
+    "prefix" text,
+    "key_hash" text NOT NULL,
+    "scopes" json,
+    "expires_at" datetime,
+    "last_used_at" datetime,
+    "revoked_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_api_keys_deleted_at" ON "api_keys" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_api_keys_user_id" ON "api_keys" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_keys_key_hash" ON "api_keys" ("key_hash");
+
+CREATE TABLE IF NOT EXISTS "user_tokens" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    "user_id" uuid NOT NULL,
+    "purpose" text NOT NULL,
+    "email" text NOT NULL,
+    "token_hash" text NOT NULL,
+    "expires_at" datetime NOT NULL,
+    "used_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_user_tokens_deleted_at" ON "user_tokens" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_user_tokens_user_id" ON "user_tokens" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_tokens_token_hash" ON "user_tokens" ("token_hash");
+
+CREATE TABLE IF NOT EXISTS "recovery_codes" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    "user_id" uuid NOT NULL,
+    "code_hash" text NOT NULL,
+    "used_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_recovery_codes_code_hash" ON "recovery_codes" ("code_hash");
+CREATE INDEX IF NOT EXISTS "idx_recovery_codes_deleted_at" ON "recovery_codes" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_recovery_codes_user_id" ON "recovery_codes" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "sso_logins" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    "state_hash" text NOT NULL,
+    "nonce" text NOT NULL,
+    "code_verifier" text NOT NULL,
+    "binding_hash" text NOT NULL DEFAULT '',
+    "expires_at" datetime NOT NULL,
+    "used_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_sso_logins_deleted_at" ON "sso_logins" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_sso_logins_state_hash" ON "sso_logins" ("state_hash");
+
+CREATE TABLE IF NOT EXISTS "login_attempts" (
+    "id" uuid,
+    "email" text NOT NULL,
+    "ip" text NOT NULL,
+    "success" numeric NOT NULL,
+    "created_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_login_attempts_created_at" ON "login_attempts" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_login_attempts_email" ON "login_attempts" ("email");
+CREATE INDEX IF NOT EXISTS "idx_login_attempts_ip" ON "login_attempts" ("ip");
+
+CREATE TABLE IF NOT EXISTS "audit_events" (
+    "id" uuid,
+    "user_id" text,
+    "event" text NOT NULL,
+    "ip" text NOT NULL,
+    "detail" text,
+    "created_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_audit_events_created_at" ON "audit_events" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_audit_events_event" ON "audit_events" ("event");
+CREATE INDEX IF NOT EXISTS "idx_audit_events_user_id" ON "audit_events" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "workspaces" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    "name" text,
+    "owner_id" uuid NOT NULL,
+    "personal" numeric,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_workspaces_owner" FOREIGN KEY ("owner_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_workspaces_deleted_at" ON "workspaces" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_workspaces_owner_id" ON "workspaces" ("owner_id");
+
+CREATE TABLE IF NOT EXISTS "workspace_members" (
+    "id" uuid,
+    "workspace_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "role" text,
+    "created_at" datetime,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_workspace_members_workspace" FOREIGN KEY ("workspace_id") REFERENCES "workspaces"("id"),
+    CONSTRAINT "fk_workspace_members_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_workspace_members_user_id" ON "workspace_members" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_workspace_member" ON "workspace_members" ("workspace_id","user_id");
+
+CREATE TABLE IF NOT EXISTS "workspace_invitations" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    "workspace_id" uuid NOT NULL,
+    "email" text NOT NULL,
+    "role" text,
+    "invited_by" uuid NOT NULL,
+    "status" text,
+    "expires_at" datetime,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_workspace_invitations_workspace" FOREIGN KEY ("workspace_id") REFERENCES "workspaces"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_workspace_invitations_deleted_at" ON "workspace_invitations" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_workspace_invitations_email" ON "workspace_invitations" ("email");
+CREATE INDEX IF NOT EXISTS "idx_workspace_invitations_workspace_id" ON "workspace_invitations" ("workspace_id");
+
+CREATE TABLE IF NOT EXISTS "collections" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    "user_id" uuid NOT NULL,
+    "workspace_id" uuid,
+    "name" text,
+    "mocked" numeric,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_collections_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_collections_deleted_at" ON "collections" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_collections_workspace_id" ON "collections" ("workspace_id");
+
+CREATE TABLE IF NOT EXISTS "requests" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    "collection_id" uuid,
+    "name" text,
+    "url" text,
+    "method" text,
+    "bearer_token" text,
+    "headers" json,
+    "payload" json,
+    "raw_body" text,
+    "response" json,
+    "response_status" integer,
+    "response_headers" json,
+    "response_time" integer,
+    "last_run_at" datetime,
+    "mock_status" integer,
+    "mock_headers" json,
+    "mock_delay" integer,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_requests_collection" FOREIGN KEY ("collection_id") REFERENCES "collections"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_requests_deleted_at" ON "requests" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "mock_examples" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    "request_id" uuid NOT NULL,
+    "name" text,
+    "priority" integer,
+    "match_query" json,
+    "match_headers" json,
+    "match_body" json,
+    "status" integer,
+    "response_headers" json,
+    "body" text,
+    "delay" integer,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_mock_examples_request" FOREIGN KEY ("request_id") REFERENCES "requests"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_mock_examples_deleted_at" ON "mock_examples" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_mock_examples_request_id" ON "mock_examples" ("request_id");
+
+CREATE TABLE IF NOT EXISTS "environments" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    "collection_id" uuid NOT NULL,
+    "name" text,
+    "variables" json,
+    "secret_variables" json,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_environments_collection" FOREIGN KEY ("collection_id") REFERENCES "collections"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_environments_collection_id" ON "environments" ("collection_id");
+CREATE INDEX IF NOT EXISTS "idx_environments_deleted_at" ON "environments" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "monitors" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    "collection_id" uuid NOT NULL,
+    "environment_id" uuid,
+    "name" text,
+    "cron" text,
+    "enabled" numeric,
+    "last_run_at" datetime,
+    "next_run_at" datetime,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_monitors_collection" FOREIGN KEY ("collection_id") REFERENCES "collections"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_monitors_collection_id" ON "monitors" ("collection_id");
+CREATE INDEX IF NOT EXISTS "idx_monitors_deleted_at" ON "monitors" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_monitors_next_run_at" ON "monitors" ("next_run_at");
+
+CREATE TABLE IF NOT EXISTS "monitor_runs" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    "monitor_id" uuid NOT NULL,
+    "started_at" datetime,
+    "duration" integer,
+    "total" integer,
+    "passed" integer,
+    "failed" integer,
+    "success" numeric,
+    "results" json,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_monitor_runs_deleted_at" ON "monitor_runs" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_monitor_runs_monitor_id" ON "monitor_runs" ("monitor_id");
+CREATE INDEX IF NOT EXISTS "idx_monitor_runs_started_at" ON "monitor_runs" ("started_at");
+
+CREATE TABLE IF NOT EXISTS "webhooks" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    "collection_id" uuid NOT NULL,
+    "name" text,
+    "url" text,
+    "secret" text,
+    "enabled" numeric,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_webhooks_collection" FOREIGN KEY ("collection_id") REFERENCES "collections"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_webhooks_collection_id" ON "webhooks" ("collection_id");
+CREATE INDEX IF NOT EXISTS "idx_webhooks_deleted_at" ON "webhooks" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "webhook_deliveries" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    "webhook_id" uuid NOT NULL,
+    "event" text,
+    "payload" text,
+    "success" numeric,
+    "status_code" integer,
+    "attempts" json,
+    "delivered_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_deleted_at" ON "webhook_deliveries" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_webhook_id" ON "webhook_deliveries" ("webhook_id");
+
+CREATE TABLE IF NOT EXISTS "share_links" (
+    "id" uuid,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    "collection_id" uuid NOT NULL,
+    "name" text,
+    "token_hash" text NOT NULL,
+    "token_prefix" text,
+    "created_by" uuid,
+    "expires_at" datetime,
+    "revoked_at" datetime,
+    "views" integer,
+    "last_viewed_at" datetime,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_share_links_collection" FOREIGN KEY ("collection_id") REFERENCES "collections"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_share_links_collection_id" ON "share_links" ("collection_id");
+CREATE INDEX IF NOT EXISTS "idx_share_links_deleted_at" ON "share_links" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_share_links_token_hash" ON "share_links" ("token_hash");
@@ -0,0 +1,2 @@
+-- Requests are listed and deleted by collection
+CREATE INDEX IF NOT EXISTS "idx_requests_collection_id" ON "requests" ("collection_id");
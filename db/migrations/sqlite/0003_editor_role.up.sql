@@ -0,0 +1,3 @@
+-- Members and invitations from before roles existed become editors
+UPDATE "workspace_members" SET "role" = 'editor' WHERE "role" = 'member';
+UPDATE "workspace_invitations" SET "role" = 'editor' WHERE "role" = 'member';
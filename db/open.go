@@ -0,0 +1,64 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Database drivers.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Open connects to a database. For the sqlite driver dsn is the path of the
+// database file, or :memory: for a database living as long as the process.
+func Open(driver string, dsn string) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	var translate func(err error) error
+	switch driver {
+	case DriverPostgres:
+		dialector = postgres.Open(dsn)
+		translate = translatePostgresError
+	case DriverSQLite:
+		dialector = sqlite.Open(sqliteDSN(dsn))
+		translate = translateSQLiteError
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", driver)
+	}
+
+	gormDB, err := gorm.Open(dialector, &gorm.Config{Logger: queryLogger})
+	if err != nil {
+		return nil, err
+	}
+
+	if driver == DriverSQLite {
+		// SQLite writes one transaction at a time, and an in-memory database
+		// only exists on the connection that created it
+		sqlDB, err := gormDB.DB()
+		if err != nil {
+			return nil, err
+		}
+		sqlDB.SetMaxOpenConns(1)
+	}
+
+	if err := registerErrorTranslation(gormDB, translate); err != nil {
+		return nil, err
+	}
+
+	return gormDB, nil
+}
+
+// sqliteDSN turns on the foreign keys, which SQLite doesn't check by
+// default, and waits for other processes writing to the file.
+func sqliteDSN(path string) string {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return path + separator + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+}
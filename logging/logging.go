@@ -0,0 +1,133 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// RequestIDHeader carries the correlation ID of a request, received from
+// the client or generated, and sent on with the webhooks it triggers.
+const RequestIDHeader = "X-Request-ID"
+
+// redacted replaces the values of secrets in the logs.
+const redacted = "[REDACTED]"
+
+// secretNames are the parts of attribute, header and query parameter names
+// whose values are never logged.
+var secretNames = []string{"authorization", "cookie", "password", "secret", "token", "api_key", "api-key", "apikey", "signature"}
+
+type requestIDKey struct{}
+
+// InitLogger makes slog, and the log package through it, write JSON lines
+// to stdout from level up, which is debug, info, warn or error.
+func InitLogger(level string) error {
+	var minLevel slog.Level
+	if err := minLevel.UnmarshalText([]byte(level)); err != nil {
+		return err
+	}
+
+	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level:       minLevel,
+		ReplaceAttr: redactAttr,
+	})
+	slog.SetDefault(slog.New(&requestIDHandler{handler}))
+	return nil
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID of ctx, empty outside of a request.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// IsSecret reports whether the value of a name must be kept out of the logs.
+func IsSecret(name string) bool {
+	name = strings.ToLower(name)
+	for _, secret := range secretNames {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// Headers returns the headers as a log group with the secret ones redacted.
+func Headers(key string, header http.Header) slog.Attr {
+	attrs := make([]any, 0, len(header))
+	for name := range header {
+		value := header.Get(name)
+		if IsSecret(name) {
+			value = redacted
+		}
+		attrs = append(attrs, slog.String(name, value))
+	}
+	return slog.Group(key, attrs...)
+}
+
+// URL returns u for the logs, without its password and with the secret query
+// parameters redacted.
+func URL(u *url.URL) string {
+	query := u.Query()
+	for name := range query {
+		if IsSecret(name) {
+			query.Set(name, redacted)
+		}
+	}
+
+	clean := *u
+	clean.RawQuery = query.Encode()
+	return clean.Redacted()
+}
+
+// Error returns err for the logs. The URL of a failed request, which
+// *url.Error repeats in full, is redacted like URL does.
+func Error(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+
+	clean := redacted
+	if u, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+		clean = URL(u)
+	}
+	return &url.Error{Op: urlErr.Op, URL: clean, Err: urlErr.Err}
+}
+
+// redactAttr hides the values of secret attributes.
+func redactAttr(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Value.Kind() != slog.KindGroup && IsSecret(attr.Key) {
+		return slog.String(attr.Key, redacted)
+	}
+	return attr
+}
+
+// requestIDHandler adds the request ID of the context to every record.
+type requestIDHandler struct {
+	slog.Handler
+}
+
+func (h *requestIDHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *requestIDHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &requestIDHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h *requestIDHandler) WithGroup(name string) slog.Handler {
+	return &requestIDHandler{h.Handler.WithGroup(name)}
+}
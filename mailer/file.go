@@ -0,0 +1,38 @@
+package mailer
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileSender writes every email to a .eml file of Dir instead of sending it.
+type FileSender struct {
+	Dir  string
+	From string
+}
+
+func (s *FileSender) Send(message Message) error {
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000000000"), message.To)
+	path := filepath.Join(s.Dir, name)
+	if err := os.WriteFile(path, format(s.From, message), 0o600); err != nil {
+		return err
+	}
+
+	slog.Info("Wrote email", "path", path)
+	return nil
+}
+
+// LogSender prints every email to the log instead of sending it.
+type LogSender struct{}
+
+func (s *LogSender) Send(message Message) error {
+	slog.Info("Email", "to", message.To, "subject", message.Subject, "body", message.Body)
+	return nil
+}
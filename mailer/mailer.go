@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"fmt"
+
+	"github.com/jeksilaen/api-builder/config"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers emails.
+type Sender interface {
+	Send(message Message) error
+}
+
+// NewSender returns the sender chosen in the configuration.
+func NewSender(mail config.MailConfig) (Sender, error) {
+	switch mail.Sender {
+	case "smtp":
+		return &SMTPSender{
+			Host:     mail.SMTPHost,
+			Port:     mail.SMTPPort,
+			Username: mail.SMTPUsername,
+			Password: mail.SMTPPassword,
+			From:     mail.From,
+		}, nil
+	case "file":
+		return &FileSender{Dir: mail.Dir, From: mail.From}, nil
+	case "log":
+		return &LogSender{}, nil
+	default:
+		return nil, fmt.Errorf("unknown mail sender %q", mail.Sender)
+	}
+}
@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"context"
+
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+)
+
+// PersonalWorkspaces gives the personal workspace of a user, creating it the
+// first time.
+type PersonalWorkspaces interface {
+	EnsurePersonalWorkspace(ctx context.Context, userID string) (*workspaceModels.Workspace, error)
+}
+
+// Auth authenticates the requests with its keys and checks the permissions
+// of their user with the records of its repository.
+type Auth struct {
+	Keys       *KeySet
+	Repo       AuthRepository
+	Workspaces PersonalWorkspaces
+}
+
+func NewAuth(keys *KeySet, repo AuthRepository, workspaces PersonalWorkspaces) *Auth {
+	return &Auth{
+		Keys:       keys,
+		Repo:       repo,
+		Workspaces: workspaces,
+	}
+}
@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeksilaen/api-builder/logging"
+)
+
+// CORSMiddleware lets the browser apps of allowedOrigins call the API. "*"
+// allows every origin. Preflight requests are answered right away.
+func CORSMiddleware(allowedOrigins []string) gin.HandlerFunc {
+	allowed := map[string]bool{}
+	for _, origin := range allowedOrigins {
+		allowed[strings.TrimSuffix(origin, "/")] = true
+	}
+
+	return func(ctx *gin.Context) {
+		origin := ctx.GetHeader("Origin")
+		if origin == "" || len(allowed) == 0 {
+			ctx.Next()
+			return
+		}
+
+		ctx.Writer.Header().Add("Vary", "Origin")
+		if !allowed["*"] && !allowed[origin] {
+			ctx.Next()
+			return
+		}
+
+		ctx.Header("Access-Control-Allow-Origin", origin)
+		// The single sign-on login is bound to the browser by a cookie
+		ctx.Header("Access-Control-Allow-Credentials", "true")
+		ctx.Header("Access-Control-Expose-Headers", "Retry-After, "+logging.RequestIDHeader)
+
+		if ctx.Request.Method == http.MethodOptions && ctx.GetHeader("Access-Control-Request-Method") != "" {
+			ctx.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			ctx.Header("Access-Control-Allow-Headers", "Authorization, Content-Type, "+APIKeyHeader+", "+logging.RequestIDHeader)
+			ctx.Header("Access-Control-Max-Age", "600")
+			ctx.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		ctx.Next()
+	}
+}
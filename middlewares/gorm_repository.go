@@ -0,0 +1,76 @@
+package middlewares
+
+import (
+	"time"
+
+	"github.com/jeksilaen/api-builder/db"
+	userModels "github.com/jeksilaen/api-builder/modules/user/models"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+	"gorm.io/gorm"
+)
+
+// GormAuthRepository reads what the middlewares need with gorm, on any of
+// the databases of db.Open.
+type GormAuthRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormAuthRepository(db *gorm.DB) *GormAuthRepository {
+	return &GormAuthRepository{DB: db}
+}
+
+func (r *GormAuthRepository) SessionRevoked(sessionID string) (bool, error) {
+	// A session without live refresh tokens is over, whether they were
+	// revoked or deleted along with their user
+	var live int64
+	err := r.DB.Model(&userModels.RefreshToken{}).Where("family_id = ? AND revoked_at IS NULL", sessionID).Count(&live).Error
+	return live == 0, err
+}
+
+func (r *GormAuthRepository) FindActiveAPIKey(keyHash string) (*userModels.APIKey, error) {
+	var apiKey userModels.APIKey
+	if err := r.DB.Where("key_hash = ? AND revoked_at IS NULL", keyHash).First(&apiKey).Error; err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+func (r *GormAuthRepository) TouchAPIKey(keyID string, now time.Time, before time.Time) error {
+	return r.DB.Model(&userModels.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", keyID, before).
+		UpdateColumn("last_used_at", now).Error
+}
+
+func (r *GormAuthRepository) MemberRole(workspaceID string, userID string) (string, error) {
+	var roles []string
+	err := r.DB.Model(&workspaceModels.WorkspaceMember{}).Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Pluck("role", &roles).Error
+	return first(roles, err)
+}
+
+func (r *GormAuthRepository) CollectionWorkspace(collectionID string) (string, error) {
+	var workspaceIDs []string
+	err := r.DB.Table("collections").
+		Where("id = ? AND deleted_at IS NULL", collectionID).
+		Pluck("workspace_id", &workspaceIDs).Error
+	return first(workspaceIDs, err)
+}
+
+func (r *GormAuthRepository) RecordWorkspace(table string, recordID string) (string, error) {
+	var workspaceIDs []string
+	err := r.DB.Table(table).
+		Joins("JOIN collections ON collections.id = "+table+".collection_id AND collections.deleted_at IS NULL").
+		Where(table+".id = ? AND "+table+".deleted_at IS NULL", recordID).
+		Pluck("collections.workspace_id", &workspaceIDs).Error
+	return first(workspaceIDs, err)
+}
+
+// first returns the first non empty value plucked, or db.ErrNotFound.
+func first(values []string, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	if len(values) == 0 || values[0] == "" {
+		return "", db.ErrNotFound
+	}
+	return values[0], nil
+}
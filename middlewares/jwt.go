@@ -1,26 +1,29 @@
 package middlewares
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	userModels "github.com/jeksilaen/api-builder/modules/user/models"
 )
 
-var jwtSecret = []byte("your_secret_key_here")
+// AccessTokenTTL is how long an access token is valid. Clients get a new one
+// with their refresh token.
+const AccessTokenTTL = 15 * time.Minute
 
-func GenerateToken(userID string) (string, error) {
+// GenerateToken issues an access token for a login session of the user.
+func (keys *KeySet) GenerateToken(userID string, sessionID string) (string, error) {
 	claims := jwt.MapClaims{
 		"userID": userID,
-		"exp":    time.Now().Add(time.Hour * 24).Unix(), // Token expires in 24 hours
+		"sid":    sessionID,
+		"exp":    time.Now().Add(AccessTokenTTL).Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	tokenString, err := token.SignedString(jwtSecret)
+	tokenString, err := keys.Sign(claims)
 	if err != nil {
 		return "", err
 	}
@@ -28,8 +31,13 @@ func GenerateToken(userID string) (string, error) {
 	return tokenString, nil
 }
 
-func VerifyToken(ctx *gin.Context) {
+// VerifyToken authenticates the request with its access token or API key.
+func (a *Auth) VerifyToken(ctx *gin.Context) {
 	authHeader := ctx.GetHeader("Authorization")
+	if authHeader == "" && ctx.GetHeader(APIKeyHeader) != "" {
+		a.verifyAPIKey(ctx, ctx.GetHeader(APIKeyHeader))
+		return
+	}
 	if authHeader == "" {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Missing Authorization header"})
 		ctx.Abort()
@@ -45,12 +53,19 @@ func VerifyToken(ctx *gin.Context) {
 
 	tokenString := tokenParts[1]
 
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return jwtSecret, nil
-	})
+	// Personal API keys are accepted in place of an access token
+	if strings.HasPrefix(tokenString, userModels.APIKeyPrefix) {
+		a.verifyAPIKey(ctx, tokenString)
+		return
+	}
+
+	token, err := a.Keys.Parse(tokenString)
+
+	if errors.Is(err, jwt.ErrTokenExpired) || (err == nil && !token.Valid) {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Token is not valid, it might be expired"})
+		ctx.Abort()
+		return
+	}
 
 	if err != nil {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Token"})
@@ -58,13 +73,38 @@ func VerifyToken(ctx *gin.Context) {
 		return
 	}
 
-	if !token.Valid {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Token is not valid, it might be expired"})
+	// Keep the user ID of the token for the handlers
+	claims, ok := token.Claims.(jwt.MapClaims)
+	userID, _ := claims["userID"].(string)
+	sessionID, _ := claims["sid"].(string)
+	if !ok || userID == "" || sessionID == "" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Token"})
+		ctx.Abort()
+		return
+	}
+
+	// Refuse the tokens of sessions ended by a logout or a reused refresh token
+	if revoked, err := a.Repo.SessionRevoked(sessionID); err != nil || revoked {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
 		ctx.Abort()
 		return
 	}
 
 	ctx.Set("token", token)
+	ctx.Set("userID", userID)
+	ctx.Set("sessionID", sessionID)
 
 	ctx.Next()
 }
+
+// GetUserID returns the ID of the user whose token was verified by
+// VerifyToken.
+func GetUserID(ctx *gin.Context) string {
+	return ctx.GetString("userID")
+}
+
+// GetSessionID returns the login session of the token verified by
+// VerifyToken.
+func GetSessionID(ctx *gin.Context) string {
+	return ctx.GetString("sessionID")
+}
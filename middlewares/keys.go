@@ -0,0 +1,219 @@
+package middlewares
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signing algorithms of the supported key types.
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// Key is a key that verifies tokens, and signs them when its private key is
+// known.
+type Key struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+	Public    crypto.PublicKey
+}
+
+// KeySet holds the signing key and every key accepted when verifying.
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+// JWK is the JSON Web Key form of a public key.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+// JWKS is the document served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// LoadKeys loads the keys of dir for GenerateToken and VerifyToken. When
+// dir doesn't exist a temporary key is generated, so tokens don't survive a
+// restart.
+func LoadKeys(dir string, signingKeyID string) (*KeySet, error) {
+	keys, err := LoadKeySet(dir, signingKeyID)
+	if errors.Is(err, os.ErrNotExist) {
+		slog.Warn("JWT keys directory not found, signing with a temporary key", "dir", dir)
+		keys, err = NewTemporaryKeySet()
+	}
+	return keys, err
+}
+
+// LoadKeySet reads every .pem file of dir. The key named signingKeyID signs
+// new tokens; it can be left empty when dir has a single private key.
+func LoadKeySet(dir string, signingKeyID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+
+	keys := &KeySet{keys: map[string]*Key{}}
+	var privateKeys []string
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := ParseKey(strings.TrimSuffix(filepath.Base(path), ".pem"), data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		keys.keys[key.ID] = key
+		if key.Private != nil {
+			privateKeys = append(privateKeys, key.ID)
+		}
+	}
+
+	if signingKeyID == "" {
+		if len(privateKeys) != 1 {
+			return nil, fmt.Errorf("%s has %d private keys, set the signing key ID", dir, len(privateKeys))
+		}
+		signingKeyID = privateKeys[0]
+	}
+
+	signing, ok := keys.keys[signingKeyID]
+	if !ok || signing.Private == nil {
+		return nil, fmt.Errorf("no private key %q in %s", signingKeyID, dir)
+	}
+	keys.signing = signing
+
+	return keys, nil
+}
+
+// NewTemporaryKeySet generates an Ed25519 signing key that only lives in
+// memory.
+func NewTemporaryKeySet() (*KeySet, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{ID: "temporary", Algorithm: AlgorithmEdDSA, Private: private, Public: public}
+	return &KeySet{signing: key, keys: map[string]*Key{key.ID: key}}, nil
+}
+
+// ParseKey reads a PEM encoded RSA or Ed25519 key, private or public.
+func ParseKey(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{ID: id}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm, key.Private, key.Public = AlgorithmRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Algorithm, key.Public = AlgorithmRS256, k
+	case ed25519.PrivateKey:
+		key.Algorithm, key.Private, key.Public = AlgorithmEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Algorithm, key.Public = AlgorithmEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+
+	return key, nil
+}
+
+// Sign signs claims with the signing key, naming it in the kid header.
+func (keys *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(keys.signing.Algorithm), claims)
+	token.Header["kid"] = keys.signing.ID
+	return token.SignedString(keys.signing.Private)
+}
+
+// Parse verifies a token with the key named in its kid header.
+func (keys *KeySet) Parse(tokenString string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := keys.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key %q", kid)
+		}
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.Public, nil
+	}, jwt.WithValidMethods([]string{AlgorithmRS256, AlgorithmEdDSA}))
+}
+
+// JWKS returns the public keys, so other services can verify tokens.
+func (keys *KeySet) JWKS() *JWKS {
+	ids := make([]string, 0, len(keys.keys))
+	for id := range keys.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	jwks := &JWKS{Keys: []JWK{}}
+	for _, id := range ids {
+		key := keys.keys[id]
+		jwk := JWK{KeyID: key.ID, Use: "sig", Algorithm: key.Algorithm}
+
+		switch public := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks
+}
@@ -0,0 +1,197 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+)
+
+// badRequestError is a resolver error answered with 400 and its message
+// instead of 404.
+type badRequestError string
+
+func (e badRequestError) Error() string {
+	return string(e)
+}
+
+// WorkspaceResolver finds the workspace a request acts on.
+type WorkspaceResolver func(ctx *gin.Context) (string, error)
+
+// RequirePermission checks that the user verified by VerifyToken has a role
+// granting permission in the workspace found by resolve. Workspaces the user
+// is not a member of are reported as not found.
+func (a *Auth) RequirePermission(permission string, resolve WorkspaceResolver) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		workspaceID, err := resolve(ctx)
+		var badRequest badRequestError
+		if errors.As(err, &badRequest) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": badRequest.Error()})
+			ctx.Abort()
+			return
+		}
+		if err != nil || workspaceID == "" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			ctx.Abort()
+			return
+		}
+
+		role, err := a.Repo.MemberRole(workspaceID, GetUserID(ctx))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			ctx.Abort()
+			return
+		}
+
+		if !workspaceModels.Can(role, permission) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "Your role in the workspace doesn't allow this"})
+			ctx.Abort()
+			return
+		}
+		if !scopeAllows(ctx, permission) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "The API key doesn't have the " + permission + " scope"})
+			ctx.Abort()
+			return
+		}
+
+		// Keep the role of the user for the handlers
+		ctx.Set("role", role)
+		ctx.Set("workspaceID", workspaceID)
+
+		ctx.Next()
+	}
+}
+
+// GetRole returns the role of the user in the workspace checked by
+// RequirePermission.
+func GetRole(ctx *gin.Context) string {
+	return ctx.GetString("role")
+}
+
+// HasPermission reports whether the role checked by RequirePermission, and
+// the API key of the request if any, grant permission.
+func HasPermission(ctx *gin.Context, permission string) bool {
+	return workspaceModels.Can(GetRole(ctx), permission) && scopeAllows(ctx, permission)
+}
+
+// WorkspaceFromParam resolves the workspace from its ID in a path parameter.
+func WorkspaceFromParam(param string) WorkspaceResolver {
+	return func(ctx *gin.Context) (string, error) {
+		return ctx.Param(param), nil
+	}
+}
+
+// WorkspaceOfCollection resolves the workspace of the collection whose ID is
+// in a path parameter.
+func (a *Auth) WorkspaceOfCollection(param string) WorkspaceResolver {
+	return func(ctx *gin.Context) (string, error) {
+		return a.Repo.CollectionWorkspace(ctx.Param(param))
+	}
+}
+
+// WorkspaceOfRecord resolves the workspace of a record of table, whose ID is
+// in a path parameter, through the collection_id column of the table.
+func (a *Auth) WorkspaceOfRecord(table string, param string) WorkspaceResolver {
+	return func(ctx *gin.Context) (string, error) {
+		return a.Repo.RecordWorkspace(table, ctx.Param(param))
+	}
+}
+
+// WorkspaceFromBody resolves the workspace from its ID in a field of the JSON
+// body, or to the personal workspace of the user when the field is empty,
+// which is where the handler puts what it creates then. The body is left in
+// place for the handler.
+func (a *Auth) WorkspaceFromBody(field string) WorkspaceResolver {
+	return func(ctx *gin.Context) (string, error) {
+		workspaceID, err := bodyField(ctx, field)
+		if err != nil || workspaceID != "" {
+			return workspaceID, err
+		}
+		return a.personalWorkspace(ctx)
+	}
+}
+
+// WorkspaceOfBodyCollection resolves the workspace of the collection whose ID
+// is in a field of the JSON body.
+func (a *Auth) WorkspaceOfBodyCollection(field string) WorkspaceResolver {
+	return func(ctx *gin.Context) (string, error) {
+		collectionID, err := bodyField(ctx, field)
+		if err != nil {
+			return "", err
+		}
+		if collectionID == "" {
+			return "", badRequestError(field + " is required")
+		}
+		return a.Repo.CollectionWorkspace(collectionID)
+	}
+}
+
+// WorkspaceFromForm resolves the workspace from its ID in a form field, or to
+// the personal workspace of the user when the field is empty.
+func (a *Auth) WorkspaceFromForm(field string) WorkspaceResolver {
+	return func(ctx *gin.Context) (string, error) {
+		if workspaceID := ctx.PostForm(field); workspaceID != "" {
+			return workspaceID, nil
+		}
+		return a.personalWorkspace(ctx)
+	}
+}
+
+func (a *Auth) personalWorkspace(ctx *gin.Context) (string, error) {
+	workspace, err := a.Workspaces.EnsurePersonalWorkspace(ctx.Request.Context(), GetUserID(ctx))
+	if err != nil {
+		return "", err
+	}
+	return workspace.ID, nil
+}
+
+// bodyField reads a string field of the JSON body and puts the body back so
+// the handler can bind it. The name matches case-insensitively like the
+// handler binding does, so a body naming the field more than once is
+// refused: the check and the handler could each pick a different one.
+func bodyField(ctx *gin.Context, field string) (string, error) {
+	if ctx.Request.Body == nil {
+		return "", nil
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	// Bodies that aren't an object are left for the handler to refuse
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return "", nil
+	}
+
+	var value string
+	found := false
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", nil
+		}
+		name, _ := token.(string)
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return "", nil
+		}
+		if !strings.EqualFold(name, field) {
+			continue
+		}
+		if found {
+			return "", badRequestError(field + " is given more than once")
+		}
+		found = true
+		json.Unmarshal(raw, &value)
+	}
+	return value, nil
+}
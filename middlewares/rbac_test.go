@@ -0,0 +1,220 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeksilaen/api-builder/db"
+	userModels "github.com/jeksilaen/api-builder/modules/user/models"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+)
+
+// fakeAuthRepository keeps the memberships and collections of the tests in
+// maps, keyed by "workspaceID/userID" and collection ID.
+type fakeAuthRepository struct {
+	roles       map[string]string
+	collections map[string]string
+}
+
+func (r *fakeAuthRepository) SessionRevoked(sessionID string) (bool, error) {
+	return false, nil
+}
+
+func (r *fakeAuthRepository) FindActiveAPIKey(keyHash string) (*userModels.APIKey, error) {
+	return nil, db.ErrNotFound
+}
+
+func (r *fakeAuthRepository) TouchAPIKey(keyID string, now time.Time, before time.Time) error {
+	return nil
+}
+
+func (r *fakeAuthRepository) MemberRole(workspaceID string, userID string) (string, error) {
+	role, ok := r.roles[workspaceID+"/"+userID]
+	if !ok {
+		return "", db.ErrNotFound
+	}
+	return role, nil
+}
+
+func (r *fakeAuthRepository) CollectionWorkspace(collectionID string) (string, error) {
+	workspaceID, ok := r.collections[collectionID]
+	if !ok {
+		return "", db.ErrNotFound
+	}
+	return workspaceID, nil
+}
+
+func (r *fakeAuthRepository) RecordWorkspace(table string, recordID string) (string, error) {
+	return "", db.ErrNotFound
+}
+
+type fakePersonalWorkspaces struct{}
+
+func (fakePersonalWorkspaces) EnsurePersonalWorkspace(ctx context.Context, userID string) (*workspaceModels.Workspace, error) {
+	return &workspaceModels.Workspace{ID: "personal-" + userID}, nil
+}
+
+func newTestAuth() *Auth {
+	return NewAuth(nil, &fakeAuthRepository{
+		roles: map[string]string{
+			"ws-1/alice":           workspaceModels.RoleEditor,
+			"ws-1/bob":             workspaceModels.RoleViewer,
+			"personal-alice/alice": workspaceModels.RoleOwner,
+		},
+		collections: map[string]string{
+			"col-1": "ws-1",
+			"col-2": "ws-2",
+		},
+	}, fakePersonalWorkspaces{})
+}
+
+// serve runs the permission check for userID on body and returns the
+// recorder, along with the workspace and role the next handler saw.
+func serve(t *testing.T, check gin.HandlerFunc, userID string, scopes userModels.Scopes, body string) (*httptest.ResponseRecorder, string, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var workspaceID, role string
+	router := gin.New()
+	router.POST("/", func(ctx *gin.Context) {
+		ctx.Set("userID", userID)
+		if scopes != nil {
+			ctx.Set("scopes", scopes)
+		}
+	}, check, func(ctx *gin.Context) {
+		workspaceID = ctx.GetString("workspaceID")
+		role = GetRole(ctx)
+		ctx.Status(http.StatusOK)
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	return recorder, workspaceID, role
+}
+
+func TestRequirePermission(t *testing.T) {
+	auth := newTestAuth()
+	edit := auth.RequirePermission(workspaceModels.PermEdit, auth.WorkspaceOfBodyCollection("collection_id"))
+
+	tests := []struct {
+		name   string
+		userID string
+		scopes userModels.Scopes
+		body   string
+		status int
+	}{
+		{"allowed", "alice", nil, `{"collection_id":"col-1"}`, http.StatusOK},
+		{"field named twice", "alice", nil, `{"collection_id":"col-1","Collection_ID":"col-2"}`, http.StatusBadRequest},
+		{"missing field", "alice", nil, `{"name":"x"}`, http.StatusBadRequest},
+		{"unknown collection", "alice", nil, `{"collection_id":"col-9"}`, http.StatusNotFound},
+		{"not a member", "alice", nil, `{"collection_id":"col-2"}`, http.StatusNotFound},
+		{"role too low", "bob", nil, `{"collection_id":"col-1"}`, http.StatusForbidden},
+		{"scope missing", "alice", userModels.Scopes{workspaceModels.PermRead}, `{"collection_id":"col-1"}`, http.StatusForbidden},
+		{"scope given", "alice", userModels.Scopes{workspaceModels.PermEdit}, `{"collection_id":"col-1"}`, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		recorder, workspaceID, role := serve(t, edit, test.userID, test.scopes, test.body)
+		if recorder.Code != test.status {
+			t.Errorf("%s: status = %d, want %d (%s)", test.name, recorder.Code, test.status, recorder.Body)
+			continue
+		}
+		if test.status == http.StatusOK && (workspaceID != "ws-1" || role != workspaceModels.RoleEditor) {
+			t.Errorf("%s: next handler saw workspace %q and role %q", test.name, workspaceID, role)
+		}
+	}
+}
+
+func TestPermissionMatrix(t *testing.T) {
+	roles := []string{workspaceModels.RoleOwner, workspaceModels.RoleAdmin, workspaceModels.RoleEditor, workspaceModels.RoleRunner, workspaceModels.RoleViewer}
+	members := map[string]string{}
+	for _, role := range roles {
+		members["ws-1/"+role] = role
+	}
+	auth := NewAuth(nil, &fakeAuthRepository{roles: members, collections: map[string]string{"col-1": "ws-1"}}, fakePersonalWorkspaces{})
+
+	// Roles in the order above, each allowed or not
+	matrix := map[string][]bool{
+		workspaceModels.PermRead:            {true, true, true, true, true},
+		workspaceModels.PermRun:             {true, true, true, true, true},
+		workspaceModels.PermEdit:            {true, true, true, false, false},
+		workspaceModels.PermSecrets:         {true, true, true, false, false},
+		workspaceModels.PermManage:          {true, true, false, false, false},
+		workspaceModels.PermDeleteWorkspace: {true, false, false, false, false},
+	}
+
+	for permission, allowed := range matrix {
+		check := auth.RequirePermission(permission, auth.WorkspaceOfBodyCollection("collection_id"))
+		for i, role := range roles {
+			want := http.StatusForbidden
+			if allowed[i] {
+				want = http.StatusOK
+			}
+			if recorder, _, _ := serve(t, check, role, nil, `{"collection_id":"col-1"}`); recorder.Code != want {
+				t.Errorf("%s asking for %s: status = %d, want %d", role, permission, recorder.Code, want)
+			}
+		}
+	}
+}
+
+func TestRequirePermissionFailsClosed(t *testing.T) {
+	auth := newTestAuth()
+	empty := func(ctx *gin.Context) (string, error) { return "", nil }
+	failed := func(ctx *gin.Context) (string, error) { return "ws-1", db.ErrNotFound }
+
+	for name, resolve := range map[string]WorkspaceResolver{"empty workspace": empty, "resolver error": failed} {
+		recorder, _, _ := serve(t, auth.RequirePermission(workspaceModels.PermRead, resolve), "alice", nil, "")
+		if recorder.Code != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want 404", name, recorder.Code)
+		}
+	}
+}
+
+func TestWorkspaceFromBody(t *testing.T) {
+	auth := newTestAuth()
+	manage := auth.RequirePermission(workspaceModels.PermManage, auth.WorkspaceFromBody("workspace_id"))
+
+	// An empty field falls back to the personal workspace
+	recorder, workspaceID, _ := serve(t, manage, "alice", nil, `{"name":"x"}`)
+	if recorder.Code != http.StatusOK || workspaceID != "personal-alice" {
+		t.Errorf("no workspace_id: status = %d, workspace = %q", recorder.Code, workspaceID)
+	}
+
+	recorder, _, _ = serve(t, manage, "alice", nil, `{"workspace_id":"ws-1"}`)
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("editor managing ws-1: status = %d, want 403", recorder.Code)
+	}
+
+	recorder, _, _ = serve(t, manage, "alice", nil, `{"workspace_id":"personal-alice","WORKSPACE_ID":"ws-1"}`)
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("workspace_id named twice: status = %d, want 400", recorder.Code)
+	}
+}
+
+func TestBodyFieldKeepsBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := `{"collection_id":"col-1","name":"x"}`
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+	value, err := bodyField(ctx, "collection_id")
+	if err != nil || value != "col-1" {
+		t.Fatalf("bodyField = %q, %v", value, err)
+	}
+
+	var bound map[string]string
+	if err := ctx.ShouldBindJSON(&bound); err != nil || bound["name"] != "x" {
+		t.Errorf("handler bound %v, %v", bound, err)
+	}
+
+	// Bodies that aren't an object are left for the handler
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`["col-1"]`))
+	if value, err := bodyField(ctx, "collection_id"); err != nil || value != "" {
+		t.Errorf("array body: %q, %v", value, err)
+	}
+}
@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"time"
+
+	userModels "github.com/jeksilaen/api-builder/modules/user/models"
+)
+
+// AuthRepository reads the sessions, API keys and workspace memberships the
+// middlewares authenticate and authorize requests with. Lookups of a missing
+// record fail with db.ErrNotFound.
+type AuthRepository interface {
+	// SessionRevoked reports whether a session has ended: its refresh
+	// tokens were revoked, or there are none left
+	SessionRevoked(sessionID string) (bool, error)
+	// FindActiveAPIKey returns the API key with keyHash unless it was
+	// revoked
+	FindActiveAPIKey(keyHash string) (*userModels.APIKey, error)
+	// TouchAPIKey stores now as the last use of the key, unless it was used
+	// after before
+	TouchAPIKey(keyID string, now time.Time, before time.Time) error
+
+	// MemberRole returns the role of userID in the workspace
+	MemberRole(workspaceID string, userID string) (string, error)
+	// CollectionWorkspace returns the workspace of a collection
+	CollectionWorkspace(collectionID string) (string, error)
+	// RecordWorkspace returns the workspace of the collection of a record of
+	// table, through its collection_id column
+	RecordWorkspace(table string, recordID string) (string, error)
+}
@@ -0,0 +1,35 @@
+package middlewares
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jeksilaen/api-builder/logging"
+)
+
+// validRequestID matches the request IDs accepted from the clients, the
+// others are replaced so they can't forge log lines.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestIDMiddleware takes the X-Request-ID of the request, or generates
+// one, and puts it in the request context and the response.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(logging.RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		ctx.Set("requestID", requestID)
+		ctx.Request = ctx.Request.WithContext(logging.WithRequestID(ctx.Request.Context(), requestID))
+		ctx.Header(logging.RequestIDHeader, requestID)
+
+		ctx.Next()
+	}
+}
+
+// GetRequestID returns the ID given to the request by RequestIDMiddleware.
+func GetRequestID(ctx *gin.Context) string {
+	return ctx.GetString("requestID")
+}
@@ -0,0 +1,62 @@
+package middlewares
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jeksilaen/api-builder/logging"
+)
+
+// RequestLoggerMiddleware logs every request once it is answered. The query
+// string is left out and secret path parameters, like share link tokens,
+// are redacted.
+func RequestLoggerMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		startedAt := time.Now()
+		ctx.Next()
+
+		path := ctx.Request.URL.Path
+		for _, param := range ctx.Params {
+			if logging.IsSecret(param.Key) && param.Value != "" {
+				path = strings.Replace(path, param.Value, "[REDACTED]", 1)
+			}
+		}
+
+		status := ctx.Writer.Status()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", ctx.Request.Method),
+			slog.String("route", ctx.FullPath()),
+			slog.String("path", path),
+			slog.Int("status", status),
+			slog.Int64("duration_ms", time.Since(startedAt).Milliseconds()),
+			slog.String("ip", ctx.ClientIP()),
+		}
+		if userID := GetUserID(ctx); userID != "" {
+			attrs = append(attrs, slog.String("user_id", userID))
+		}
+		if len(ctx.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", ctx.Errors.String()))
+		}
+
+		slog.LogAttrs(ctx.Request.Context(), level, "Request handled", attrs...)
+	}
+}
+
+// RecoveryMiddleware answers 500 when a handler panics and logs the panic
+// with the request ID instead of gin's text output.
+func RecoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(ctx *gin.Context, err any) {
+		slog.ErrorContext(ctx.Request.Context(), "Handler panicked", "panic", err, "stack", string(debug.Stack()))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	})
+}
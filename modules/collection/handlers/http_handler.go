@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	// "log"
 
@@ -10,18 +11,35 @@ import (
 	"github.com/jeksilaen/api-builder/modules/collection/helpers"	
 	"github.com/jeksilaen/api-builder/modules/collection/models"	
 	"github.com/jeksilaen/api-builder/modules/collection/usecases"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+	workspaceUsecases "github.com/jeksilaen/api-builder/modules/workspace/usecases"
 )
 
-func InitCollectionHttpHandler(router *gin.Engine) {	
-	router.GET("/users/v1/collection/:user_id", middlewares.VerifyToken, GetCollectionByUserID)
-	router.POST("/users/v1/collection", middlewares.VerifyToken,CreateCollection)
-	router.PUT("/users/v1/collection/:id", middlewares.VerifyToken,UpdateCollection)
-	router.DELETE("/users/v1/collection/:id", middlewares.VerifyToken,DeleteCollection)
+// CollectionHttpHandler serves the collection routes with the usecases it is given.
+type CollectionHttpHandler struct {
+	Collections *usecases.CollectionCommandUsecase
+	Workspaces  *workspaceUsecases.WorkspaceCommandUsecase
+}
+
+func NewCollectionHttpHandler(collections *usecases.CollectionCommandUsecase, workspaces *workspaceUsecases.WorkspaceCommandUsecase) *CollectionHttpHandler {
+	return &CollectionHttpHandler{
+		Collections: collections,
+		Workspaces:  workspaces,
+	}
+}
+
+func InitCollectionHttpHandler(router *gin.Engine, auth *middlewares.Auth, h *CollectionHttpHandler) {	
+	router.GET("/users/v1/collection/:user_id", auth.VerifyToken, middlewares.RequireScope(workspaceModels.PermRead), h.GetCollectionByUserID)
+	router.GET("/users/v1/collection_by_workspace/:workspace_id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermRead, middlewares.WorkspaceFromParam("workspace_id")), h.GetCollectionByWorkspaceID)
+	router.POST("/users/v1/collection", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, auth.WorkspaceFromBody("workspace_id")), h.CreateCollection)
+	router.PUT("/users/v1/collection/:id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, auth.WorkspaceOfCollection("id")), h.UpdateCollection)
+	router.PUT("/users/v1/collection/:id/mock", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, auth.WorkspaceOfCollection("id")), h.UpdateCollectionMock)
+	router.PUT("/users/v1/collection/:id/workspace", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermManage, auth.WorkspaceOfCollection("id")), h.MoveCollection)
+	router.DELETE("/users/v1/collection/:id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermManage, auth.WorkspaceOfCollection("id")), h.DeleteCollection)
 }
 
 
-func GetCollectionByUserID(ctx *gin.Context) {
-	collectionUsecase := usecases.NewCollectionCommandUsecase()
+func (h *CollectionHttpHandler) GetCollectionByUserID(ctx *gin.Context) {
 
 	// Get user_id from path parameter
 	userID := ctx.Param("user_id")
@@ -32,8 +50,14 @@ func GetCollectionByUserID(ctx *gin.Context) {
 		return
 	}
 
+	// Only the collections shared with the logged in user can be listed
+	if userID != middlewares.GetUserID(ctx) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Collections not found"})
+		return
+	}
+
 	// Get the collection data from usecase
-	collections, err := collectionUsecase.GetCollectionsByUserID(userID)
+	collections, err := h.Collections.GetCollectionsByUserID(userID)
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Collections not found"})
 		return
@@ -44,8 +68,26 @@ func GetCollectionByUserID(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, helpers.ReturnSucessGetResponse(collections))
 }
 
-func CreateCollection(ctx *gin.Context) {
-	collectionUsecase := usecases.NewCollectionCommandUsecase()
+func (h *CollectionHttpHandler) GetCollectionByWorkspaceID(ctx *gin.Context) {
+
+	workspaceID := ctx.Param("workspace_id")
+
+	if workspaceID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Workspace ID is required"})
+		return
+	}
+
+	// Get the collections of the workspace from usecase
+	collections, err := h.Collections.GetCollectionsByWorkspaceID(middlewares.GetUserID(ctx), workspaceID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessGetResponse(collections))
+}
+
+func (h *CollectionHttpHandler) CreateCollection(ctx *gin.Context) {
 	validate := validator.New()
 
 	// Decode the request JSON data into Collection object
@@ -55,6 +97,18 @@ func CreateCollection(ctx *gin.Context) {
 		return
 	}
 
+	// The collection is created by the logged in user, in their personal
+	// workspace unless another one is given
+	req.UserID = middlewares.GetUserID(ctx)
+	if req.WorkspaceID == "" {
+		workspace, err := h.Workspaces.EnsurePersonalWorkspace(ctx.Request.Context(), req.UserID)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+			return
+		}
+		req.WorkspaceID = workspace.ID
+	}
+
 	// Validate the request JSON data
 	err := validate.Struct(req)
 	if err != nil {
@@ -63,7 +117,11 @@ func CreateCollection(ctx *gin.Context) {
 	}
 
 	// Create the collection
-	createdCollection, err := collectionUsecase.CreateCollection(&req)
+	createdCollection, err := h.Collections.CreateCollection(ctx.Request.Context(), req.UserID, &req)
+	if errors.Is(err, workspaceUsecases.ErrWorkspaceNotFound) {
+		ctx.JSON(http.StatusNotFound, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
 		return
@@ -72,8 +130,7 @@ func CreateCollection(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, helpers.ReturnSucessCreateResponse(createdCollection))
 }
 
-func UpdateCollection(ctx *gin.Context) {
-    collectionUsecase := usecases.NewCollectionCommandUsecase()
+func (h *CollectionHttpHandler) UpdateCollection(ctx *gin.Context) {
     validate := validator.New()
 
     // Get collection ID from path parameter
@@ -100,7 +157,7 @@ func UpdateCollection(ctx *gin.Context) {
     }
 
     // Get the existing collection data from usecase without preloading the User field
-    existingCollection, err := collectionUsecase.GetCollectionByIDWithoutPreload(collectionID)
+    existingCollection, err := h.Collections.GetCollectionByIDWithoutPreload(middlewares.GetUserID(ctx), collectionID)
     if err != nil {
         ctx.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
         return
@@ -110,7 +167,7 @@ func UpdateCollection(ctx *gin.Context) {
     existingCollection.Name = req.Name
 
     // Save the updated collection
-    updatedCollection, err := collectionUsecase.UpdateCollection(existingCollection)
+    updatedCollection, err := h.Collections.UpdateCollection(existingCollection)
     if err != nil {
         ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
         return
@@ -119,8 +176,87 @@ func UpdateCollection(ctx *gin.Context) {
     ctx.JSON(http.StatusOK, helpers.ReturnSucessCreateResponse(updatedCollection))
 }
 
-func DeleteCollection(ctx *gin.Context) {
-    collectionUsecase := usecases.NewCollectionCommandUsecase()
+func (h *CollectionHttpHandler) UpdateCollectionMock(ctx *gin.Context) {
+
+	// Get collection ID from path parameter
+	collectionID := ctx.Param("id")
+
+	if collectionID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Collection ID is required"})
+		return
+	}
+
+	// Decode the request JSON data into MockRequest object
+	var req models.MockRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Get the existing collection data from usecase without preloading the User field
+	existingCollection, err := h.Collections.GetCollectionByIDWithoutPreload(middlewares.GetUserID(ctx), collectionID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		return
+	}
+
+	// Turn the mock server on or off for the collection
+	existingCollection.Mocked = req.Mocked
+
+	updatedCollection, err := h.Collections.UpdateCollection(existingCollection)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessCreateResponse(updatedCollection))
+}
+
+func (h *CollectionHttpHandler) MoveCollection(ctx *gin.Context) {
+	validate := validator.New()
+
+	collectionID := ctx.Param("id")
+
+	if collectionID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Collection ID is required"})
+		return
+	}
+
+	// Decode the request JSON data into MoveRequest object
+	var req models.MoveRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	existingCollection, err := h.Collections.GetCollectionByIDWithoutPreload(middlewares.GetUserID(ctx), collectionID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		return
+	}
+
+	// Move the collection to another workspace of the user
+	movedCollection, err := h.Collections.MoveCollection(middlewares.GetUserID(ctx), existingCollection, req.WorkspaceID)
+	if errors.Is(err, workspaceUsecases.ErrPermissionDenied) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessCreateResponse(movedCollection))
+}
+
+func (h *CollectionHttpHandler) DeleteCollection(ctx *gin.Context) {
 
     // Get collection ID from path parameter
     collectionID := ctx.Param("id")
@@ -132,7 +268,7 @@ func DeleteCollection(ctx *gin.Context) {
     }
 
     // Delete the collection
-    err := collectionUsecase.DeleteCollection(collectionID)
+    err := h.Collections.DeleteCollection(middlewares.GetUserID(ctx), collectionID)
     if err != nil {
         ctx.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
         return
@@ -23,7 +23,9 @@ func ReturnSucessCreateResponse(createdCollection *models.Collection) *models.Su
 		Data: models.CollectionResponse{
 			ID:       createdCollection.ID,
 			UserID:       createdCollection.UserID,
-			Name:    createdCollection.Name,			
+			WorkspaceID: createdCollection.WorkspaceID,
+			Name:    createdCollection.Name,
+			Mocked:  createdCollection.Mocked,
 		},
 		Links: []models.Link{
 			{
@@ -41,7 +43,9 @@ func ReturnSucessGetResponse(collections []*models.Collection) *models.SucessGet
 		collectionResponses = append(collectionResponses, models.CollectionResponse{
 			ID:       collection.ID,
 			UserID:   collection.UserID,
+			WorkspaceID: collection.WorkspaceID,
 			Name:     collection.Name,
+			Mocked:   collection.Mocked,
 		})
 	}
 
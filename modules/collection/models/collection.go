@@ -10,14 +10,26 @@ type Collection struct {
 	gorm.Model
 	ID       string `gorm:"type:uuid;primaryKey"`
 	UserID   string `gorm:"type:uuid;not null"`
+	WorkspaceID string `json:"workspace_id" gorm:"type:uuid;index"`
 	Name     string `json:"name" validate:"required"`
+	Mocked   bool   `json:"mocked"`
 	User     models.User   `gorm:"foreignKey:UserID" validate:"-"`
 }
 
 type CollectionResponse struct {
 	ID       string `json:"id"`
 	UserID   string `json:"user_id"`
+	WorkspaceID string `json:"workspace_id"`
 	Name    string `json:"name" validate:"required"`
+	Mocked  bool   `json:"mocked"`
+}
+
+type MoveRequest struct {
+	WorkspaceID string `json:"workspace_id" validate:"required"`
+}
+
+type MockRequest struct {
+	Mocked bool `json:"mocked"`
 }
 
 type SucessCreateResponse struct {
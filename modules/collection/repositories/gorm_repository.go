@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"github.com/jeksilaen/api-builder/db"
+	"github.com/jeksilaen/api-builder/modules/collection/models"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+	"gorm.io/gorm"
+)
+
+// GormCollectionRepository stores collections with gorm, on any of the
+// databases of db.Open.
+type GormCollectionRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormCollectionRepository(db *gorm.DB) *GormCollectionRepository {
+	return &GormCollectionRepository{DB: db}
+}
+
+func (r *GormCollectionRepository) FindByMember(userID string) ([]*models.Collection, error) {
+	var collections []*models.Collection
+	err := r.DB.Where("workspace_id IN (?)", MemberWorkspaceIDs(r.DB, userID)).Preload("User").Find(&collections).Error
+	return collections, err
+}
+
+func (r *GormCollectionRepository) FindByWorkspaceID(workspaceID string) ([]*models.Collection, error) {
+	var collections []*models.Collection
+	err := r.DB.Where("workspace_id = ?", workspaceID).Find(&collections).Error
+	return collections, err
+}
+
+func (r *GormCollectionRepository) FindByID(collectionID string) (*models.Collection, error) {
+	var collection models.Collection
+	if err := r.DB.Where("id = ?", collectionID).First(&collection).Error; err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+func (r *GormCollectionRepository) FindAccessible(userID string, collectionID string) (*models.Collection, error) {
+	var collection models.Collection
+	err := r.DB.Where("id = ? AND workspace_id IN (?)", collectionID, MemberWorkspaceIDs(r.DB, userID)).First(&collection).Error
+	if err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+func (r *GormCollectionRepository) Create(collection *models.Collection) error {
+	return r.DB.Create(collection).Error
+}
+
+func (r *GormCollectionRepository) Save(collection *models.Collection) error {
+	return r.DB.Save(collection).Error
+}
+
+func (r *GormCollectionRepository) Delete(collection *models.Collection) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", collection.ID).Delete(&requestModels.Request{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(collection).Error
+	})
+}
+
+func (r *GormCollectionRepository) FindIDsByWorkspace(workspaceID string) ([]string, error) {
+	var collectionIDs []string
+	err := r.DB.Unscoped().Model(&models.Collection{}).Where("workspace_id = ?", workspaceID).Pluck("id", &collectionIDs).Error
+	return collectionIDs, err
+}
+
+func (r *GormCollectionRepository) FindOrphanedIDs(userID string) ([]string, error) {
+	var collectionIDs []string
+	err := r.DB.Unscoped().Model(&models.Collection{}).
+		Where("user_id = ? AND (workspace_id IS NULL OR workspace_id NOT IN (?))", userID, r.DB.Model(&workspaceModels.Workspace{}).Select("id")).
+		Pluck("id", &collectionIDs).Error
+	return collectionIDs, err
+}
+
+func (r *GormCollectionRepository) HandToWorkspaceOwners(userID string) error {
+	return r.DB.Unscoped().Model(&models.Collection{}).
+		Where("user_id = ?", userID).
+		Update("user_id", gorm.Expr("(SELECT owner_id FROM workspaces WHERE workspaces.id = collections.workspace_id)")).Error
+}
+
+func (r *GormCollectionRepository) DeleteByIDs(collectionIDs []string) error {
+	return r.DB.Unscoped().Where("id IN ?", collectionIDs).Delete(&models.Collection{}).Error
+}
+
+func (r *GormCollectionRepository) MemberRole(userID string, workspaceID string) (string, error) {
+	var roles []string
+	err := r.DB.Model(&workspaceModels.WorkspaceMember{}).Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Pluck("role", &roles).Error
+	if err != nil {
+		return "", err
+	}
+	if len(roles) == 0 {
+		return "", db.ErrNotFound
+	}
+	return roles[0], nil
+}
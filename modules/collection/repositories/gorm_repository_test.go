@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/jeksilaen/api-builder/db"
+	"github.com/jeksilaen/api-builder/db/dbtest"
+	"github.com/jeksilaen/api-builder/modules/collection/models"
+	userModels "github.com/jeksilaen/api-builder/modules/user/models"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+	"gorm.io/gorm"
+)
+
+// fixture is a workspace of bob that alice is an editor of, and a workspace
+// of carol alone.
+type fixture struct {
+	alice, bob, carol      *userModels.User
+	shared, carolWorkspace *workspaceModels.Workspace
+}
+
+func newFixture(t *testing.T, gormDB *gorm.DB) *fixture {
+	t.Helper()
+	create := func(record interface{}) {
+		if err := gormDB.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	user := func(name string) *userModels.User {
+		user := &userModels.User{Email: name + "@example.com", Username: name, Password: "hash"}
+		create(user)
+		return user
+	}
+
+	f := &fixture{alice: user("alice"), bob: user("bob"), carol: user("carol")}
+	f.shared = &workspaceModels.Workspace{Name: "Team", OwnerID: f.bob.ID}
+	f.carolWorkspace = &workspaceModels.Workspace{Name: "Carol", OwnerID: f.carol.ID, Personal: true}
+	create(f.shared)
+	create(f.carolWorkspace)
+	create(&workspaceModels.WorkspaceMember{WorkspaceID: f.shared.ID, UserID: f.bob.ID, Role: workspaceModels.RoleOwner})
+	create(&workspaceModels.WorkspaceMember{WorkspaceID: f.shared.ID, UserID: f.alice.ID, Role: workspaceModels.RoleEditor})
+	create(&workspaceModels.WorkspaceMember{WorkspaceID: f.carolWorkspace.ID, UserID: f.carol.ID, Role: workspaceModels.RoleOwner})
+	return f
+}
+
+func createCollection(t *testing.T, repo *GormCollectionRepository, userID string, workspaceID string, name string) *models.Collection {
+	t.Helper()
+	collection := &models.Collection{UserID: userID, WorkspaceID: workspaceID, Name: name}
+	if err := repo.Create(collection); err != nil {
+		t.Fatal(err)
+	}
+	return collection
+}
+
+func TestFindAccessible(t *testing.T) {
+	gormDB := dbtest.Open(t)
+	f := newFixture(t, gormDB)
+	repo := NewGormCollectionRepository(gormDB)
+
+	shared := createCollection(t, repo, f.bob.ID, f.shared.ID, "Shared")
+	private := createCollection(t, repo, f.carol.ID, f.carolWorkspace.ID, "Private")
+
+	for _, userID := range []string{f.alice.ID, f.bob.ID} {
+		if found, err := repo.FindAccessible(userID, shared.ID); err != nil || found.ID != shared.ID {
+			t.Errorf("member %s: %v, %v", userID, found, err)
+		}
+	}
+	if _, err := repo.FindAccessible(f.alice.ID, private.ID); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("collection of another workspace: error = %v, want db.ErrNotFound", err)
+	}
+
+	collections, err := repo.FindByMember(f.alice.ID)
+	if err != nil || len(collections) != 1 || collections[0].ID != shared.ID {
+		t.Errorf("FindByMember = %v, %v", collections, err)
+	}
+	if role, err := repo.MemberRole(f.alice.ID, f.shared.ID); err != nil || role != workspaceModels.RoleEditor {
+		t.Errorf("MemberRole = %q, %v", role, err)
+	}
+}
+
+func TestAccountCleanup(t *testing.T) {
+	gormDB := dbtest.Open(t)
+	f := newFixture(t, gormDB)
+	repo := NewGormCollectionRepository(gormDB)
+
+	handed := createCollection(t, repo, f.alice.ID, f.shared.ID, "Handed")
+	orphan := createCollection(t, repo, f.alice.ID, f.shared.ID, "Orphan")
+	deleted := createCollection(t, repo, f.bob.ID, f.shared.ID, "Deleted")
+	if err := gormDB.Model(orphan).Update("workspace_id", nil).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := gormDB.Delete(deleted).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Soft deleted collections are still found, their rows have to go too
+	ids, err := repo.FindIDsByWorkspace(f.shared.ID)
+	sort.Strings(ids)
+	want := []string{handed.ID, deleted.ID}
+	sort.Strings(want)
+	if err != nil || strings.Join(ids, ",") != strings.Join(want, ",") {
+		t.Errorf("FindIDsByWorkspace = %v, %v, want %v", ids, err, want)
+	}
+
+	ids, err = repo.FindOrphanedIDs(f.alice.ID)
+	if err != nil || len(ids) != 1 || ids[0] != orphan.ID {
+		t.Errorf("FindOrphanedIDs = %v, %v, want [%s]", ids, err, orphan.ID)
+	}
+
+	if err := repo.DeleteByIDs(ids); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.HandToWorkspaceOwners(f.alice.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := repo.FindByID(handed.ID)
+	if err != nil || found.UserID != f.bob.ID {
+		t.Errorf("handed collection = %+v, %v, want it created by the owner", found, err)
+	}
+	if _, err := repo.FindByID(orphan.ID); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("orphan after DeleteByIDs: error = %v, want db.ErrNotFound", err)
+	}
+}
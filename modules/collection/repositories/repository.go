@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"github.com/jeksilaen/api-builder/modules/collection/models"
+)
+
+// CollectionRepository stores collections, and tells which workspaces their
+// users are members of. Lookups of a missing record fail with db.ErrNotFound.
+type CollectionRepository interface {
+	// FindByMember returns the collections of the workspaces of userID with
+	// their creator
+	FindByMember(userID string) ([]*models.Collection, error)
+	FindByWorkspaceID(workspaceID string) ([]*models.Collection, error)
+	FindByID(collectionID string) (*models.Collection, error)
+	// FindAccessible returns the collection if it is in a workspace of userID
+	FindAccessible(userID string, collectionID string) (*models.Collection, error)
+	Create(collection *models.Collection) error
+	Save(collection *models.Collection) error
+	// Delete deletes the collection with its requests
+	Delete(collection *models.Collection) error
+	// FindIDsByWorkspace returns the collections of a workspace, soft
+	// deleted ones too
+	FindIDsByWorkspace(workspaceID string) ([]string, error)
+	// FindOrphanedIDs returns the collections userID created that are in no
+	// existing workspace, soft deleted ones too
+	FindOrphanedIDs(userID string) ([]string, error)
+	// HandToWorkspaceOwners makes the owners of their workspaces the
+	// creators of the collections userID created
+	HandToWorkspaceOwners(userID string) error
+	// DeleteByIDs deletes the collections for good, once nothing points at
+	// them anymore
+	DeleteByIDs(collectionIDs []string) error
+	// MemberRole returns the role of userID in the workspace
+	MemberRole(userID string, workspaceID string) (string, error)
+}
@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+	"gorm.io/gorm"
+)
+
+// MemberWorkspaceIDs selects the ids of the workspaces userID is a member of.
+func MemberWorkspaceIDs(tx *gorm.DB, userID string) *gorm.DB {
+	return tx.Session(&gorm.Session{NewDB: true}).
+		Model(&workspaceModels.WorkspaceMember{}).
+		Select("workspace_id").
+		Where("user_id = ?", userID)
+}
+
+// MemberCollectionIDs selects the ids of the collections in the workspaces
+// of userID.
+func MemberCollectionIDs(tx *gorm.DB, userID string) *gorm.DB {
+	return tx.Session(&gorm.Session{NewDB: true}).
+		Model(&collectionModels.Collection{}).
+		Select("id").
+		Where("workspace_id IN (?)", MemberWorkspaceIDs(tx, userID))
+}
+
+// CollectionAccessibleBy scopes a query on a table with a collection_id
+// column to the rows of collections in the workspaces of userID.
+func CollectionAccessibleBy(userID string) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("collection_id IN (?)", MemberCollectionIDs(tx, userID))
+	}
+}
@@ -1,98 +1,153 @@
 package usecases
 
 import (
+	"context"
 	"errors"
-	"log"
-	"strings"	
 	"github.com/jeksilaen/api-builder/db"
-	collectionModels"github.com/jeksilaen/api-builder/modules/collection/models"	
-	requestModels"github.com/jeksilaen/api-builder/modules/request/models"	
-	"gorm.io/gorm"
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	"github.com/jeksilaen/api-builder/modules/collection/repositories"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+	workspaceUsecases "github.com/jeksilaen/api-builder/modules/workspace/usecases"
+	"log/slog"
 )
 
+// ErrCollectionNotFound is returned for collections that don't exist or are
+// outside of the workspaces of the user, so callers can't tell the two apart.
+var ErrCollectionNotFound = errors.New("Collection not found")
+
 type CollectionCommandUsecase struct {
-	DB *gorm.DB
+	Repo repositories.CollectionRepository
 }
 
-func NewCollectionCommandUsecase() *CollectionCommandUsecase {
+func NewCollectionCommandUsecase(repo repositories.CollectionRepository) *CollectionCommandUsecase {
 	return &CollectionCommandUsecase{
-		DB: db.GetDB(),
+		Repo: repo,
 	}
 }
 
+// GetCollectionsByUserID returns the collections of every workspace the user
+// is a member of.
 func (uc *CollectionCommandUsecase) GetCollectionsByUserID(userID string) ([]*collectionModels.Collection, error) {
-	var collections []*collectionModels.Collection
-	result := uc.DB.Where("user_id = ?", userID).Preload("User").Find(&collections)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			return nil, errors.New("Collections not found")
-		}
-		return nil, result.Error
+	collections, err := uc.Repo.FindByMember(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return collections, nil
+}
+
+func (uc *CollectionCommandUsecase) GetCollectionsByWorkspaceID(userID string, workspaceID string) ([]*collectionModels.Collection, error) {
+	if !uc.IsWorkspaceMember(userID, workspaceID) {
+		return nil, workspaceUsecases.ErrWorkspaceNotFound
+	}
+
+	collections, err := uc.Repo.FindByWorkspaceID(workspaceID)
+	if err != nil {
+		return nil, err
 	}
 
 	return collections, nil
 }
 
-func (uc *CollectionCommandUsecase) CreateCollection(collection *collectionModels.Collection) (*collectionModels.Collection, error) {
-	
-	// Create the collectiion and handle duplicate error	
-	err := uc.DB.Create(collection).Error
+// CreateCollection creates a collection in a workspace of the user.
+func (uc *CollectionCommandUsecase) CreateCollection(ctx context.Context, userID string, collection *collectionModels.Collection) (*collectionModels.Collection, error) {
+	if !uc.IsWorkspaceMember(userID, collection.WorkspaceID) {
+		return nil, workspaceUsecases.ErrWorkspaceNotFound
+	}
+
+	// Create the collectiion and handle duplicate error
+	err := uc.Repo.Create(collection)
 	if err != nil {
-		if strings.Contains(err.Error(), "invalid input syntax for type uuid") {
+		if errors.Is(err, db.ErrInvalidReference) {
 			return nil, errors.New("User Id Not Found")
 		}
 
-		log.Println("Error creating collection:", err)
+		slog.ErrorContext(ctx, "Error creating collection", "error", err)
 		return nil, err
 	}
 
 	return collection, nil
 }
 
+func (uc *CollectionCommandUsecase) GetCollectionByIDWithoutPreload(userID string, collectionID string) (*collectionModels.Collection, error) {
+	collection, err := uc.Repo.FindAccessible(userID, collectionID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, err
+	}
 
-func (uc *CollectionCommandUsecase) GetCollectionByIDWithoutPreload(collectionID string) (*collectionModels.Collection, error) {
-    var collection collectionModels.Collection
-    result := uc.DB.Where("id = ?", collectionID).First(&collection)
-    if result.Error != nil {
-        if result.Error == gorm.ErrRecordNotFound {
-            return nil, errors.New("Collection not found")
-        }
-        return nil, result.Error
-    }
+	return collection, nil
+}
 
-    return &collection, nil
+// GetCollectionByID returns a collection whatever workspace it is in, for
+// work done on behalf of its owner.
+func (uc *CollectionCommandUsecase) GetCollectionByID(collectionID string) (*collectionModels.Collection, error) {
+	collection, err := uc.Repo.FindByID(collectionID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, err
+	}
+
+	return collection, nil
 }
 
 func (uc *CollectionCommandUsecase) UpdateCollection(collection *collectionModels.Collection) (*collectionModels.Collection, error) {
-	err := uc.DB.Save(collection).Error
+	err := uc.Repo.Save(collection)
 	if err != nil {
 		return nil, err
 	}
 	return collection, nil
 }
 
-func (uc *CollectionCommandUsecase) DeleteCollection(collectionID string) error {
-    // Check if the collection exists
-    var collection collectionModels.Collection
-    result := uc.DB.Where("id = ?", collectionID).First(&collection)
-    if result.Error != nil {
-        if result.Error == gorm.ErrRecordNotFound {
-            return errors.New("Collection not found")
-        }
-        return result.Error
-    }
-
-    // Delete related data from the request table
-    err := uc.DB.Where("collection_id = ?", collectionID).Delete(&requestModels.Request{}).Error
-    if err != nil {
-        return err
-    }
-
-    // Delete the collection
-    err = uc.DB.Delete(&collection).Error
-    if err != nil {
-        return err
-    }
-
-    return nil
+func (uc *CollectionCommandUsecase) DeleteCollection(userID string, collectionID string) error {
+	// Check if the collection exists in a workspace of the user
+	collection, err := uc.Repo.FindAccessible(userID, collectionID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return errors.New("Collection not found")
+		}
+		return err
+	}
+
+	// Delete the collection with its requests
+	err = uc.Repo.Delete(collection)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MoveCollection moves a collection to another workspace in which the user
+// can manage collections.
+func (uc *CollectionCommandUsecase) MoveCollection(userID string, collection *collectionModels.Collection, workspaceID string) (*collectionModels.Collection, error) {
+	role, err := uc.WorkspaceRole(userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if !workspaceModels.Can(role, workspaceModels.PermManage) {
+		return nil, workspaceUsecases.ErrPermissionDenied
+	}
+
+	collection.WorkspaceID = workspaceID
+	return uc.UpdateCollection(collection)
+}
+
+// WorkspaceRole returns the role of userID in the workspace.
+func (uc *CollectionCommandUsecase) WorkspaceRole(userID string, workspaceID string) (string, error) {
+	role, err := uc.Repo.MemberRole(userID, workspaceID)
+	if err != nil {
+		return "", workspaceUsecases.ErrWorkspaceNotFound
+	}
+	return role, nil
+}
+
+// IsWorkspaceMember reports whether userID is a member of the workspace.
+func (uc *CollectionCommandUsecase) IsWorkspaceMember(userID string, workspaceID string) bool {
+	_, err := uc.Repo.MemberRole(userID, workspaceID)
+	return err == nil
 }
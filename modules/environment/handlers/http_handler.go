@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/jeksilaen/api-builder/middlewares"
+	collectionUsecases "github.com/jeksilaen/api-builder/modules/collection/usecases"
+	"github.com/jeksilaen/api-builder/modules/environment/helpers"
+	"github.com/jeksilaen/api-builder/modules/environment/models"
+	"github.com/jeksilaen/api-builder/modules/environment/usecases"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+)
+
+// EnvironmentHttpHandler serves the environment routes with the usecases it is given.
+type EnvironmentHttpHandler struct {
+	Environments *usecases.EnvironmentCommandUsecase
+}
+
+func NewEnvironmentHttpHandler(environments *usecases.EnvironmentCommandUsecase) *EnvironmentHttpHandler {
+	return &EnvironmentHttpHandler{
+		Environments: environments,
+	}
+}
+
+func InitEnvironmentHttpHandler(router *gin.Engine, auth *middlewares.Auth, h *EnvironmentHttpHandler) {
+	ofEnvironment := auth.WorkspaceOfRecord("environments", "id")
+
+	router.GET("/users/v1/environment_by_collection/:collection_id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermRead, auth.WorkspaceOfCollection("collection_id")), h.GetEnvironmentsByCollection)
+	router.POST("/users/v1/environment", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, auth.WorkspaceOfBodyCollection("collection_id")), h.CreateEnvironment)
+	router.PUT("/users/v1/environment/:id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, ofEnvironment), h.UpdateEnvironment)
+	router.DELETE("/users/v1/environment/:id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, ofEnvironment), h.DeleteEnvironment)
+}
+
+func (h *EnvironmentHttpHandler) GetEnvironmentsByCollection(ctx *gin.Context) {
+
+	collectionID := ctx.Param("collection_id")
+
+	if collectionID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Collection ID is required"})
+		return
+	}
+
+	// Get the environments from usecase
+	environments, err := h.Environments.GetEnvironmentsByCollectionID(middlewares.GetUserID(ctx), collectionID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Environments not found"})
+		return
+	}
+
+	// Hide the secret values from members who can't see secrets
+	if !middlewares.HasPermission(ctx, workspaceModels.PermSecrets) {
+		helpers.RedactEnvironments(environments)
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessGetResponse(environments))
+}
+
+func (h *EnvironmentHttpHandler) CreateEnvironment(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into Environment object
+	var req models.Environment
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Create the environment
+	createdEnvironment, err := h.Environments.CreateEnvironment(ctx.Request.Context(), middlewares.GetUserID(ctx), &req)
+	if errors.Is(err, collectionUsecases.ErrCollectionNotFound) {
+		ctx.JSON(http.StatusNotFound, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, helpers.ReturnSucessCreateResponse(createdEnvironment))
+}
+
+func (h *EnvironmentHttpHandler) UpdateEnvironment(ctx *gin.Context) {
+	validate := validator.New()
+
+	environmentID := ctx.Param("id")
+
+	if environmentID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Environment ID is required"})
+		return
+	}
+
+	// Decode the request JSON data into Environment object
+	var req models.Environment
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.StructPartial(req, "Name")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Get the existing environment from usecase
+	existingEnvironment, err := h.Environments.GetEnvironmentByID(middlewares.GetUserID(ctx), environmentID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Environment not found"})
+		return
+	}
+
+	existingEnvironment.Name = req.Name
+	existingEnvironment.Variables = req.Variables
+	existingEnvironment.SecretVariables = helpers.KeepSecretVariables(existingEnvironment.SecretVariables, req.SecretVariables, middlewares.HasPermission(ctx, workspaceModels.PermSecrets))
+
+	// Save the updated environment
+	updatedEnvironment, err := h.Environments.UpdateEnvironment(existingEnvironment)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Hide the secret values from members who can't see secrets
+	if !middlewares.HasPermission(ctx, workspaceModels.PermSecrets) {
+		helpers.RedactEnvironments([]*models.Environment{updatedEnvironment})
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessCreateResponse(updatedEnvironment))
+}
+
+func (h *EnvironmentHttpHandler) DeleteEnvironment(ctx *gin.Context) {
+
+	environmentID := ctx.Param("id")
+
+	if environmentID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Environment ID is required"})
+		return
+	}
+
+	// Delete the environment
+	err := h.Environments.DeleteEnvironment(middlewares.GetUserID(ctx), environmentID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Environment not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessDeleteResponse("Deleted Environment Successfully"))
+}
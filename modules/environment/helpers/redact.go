@@ -0,0 +1,46 @@
+package helpers
+
+import (
+	"github.com/jeksilaen/api-builder/modules/environment/models"
+	requestHelpers "github.com/jeksilaen/api-builder/modules/request/helpers"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+)
+
+// RedactEnvironments hides the values of the secret variables for members
+// who can't see secrets, keeping their names. Redacted environments must not
+// be saved.
+func RedactEnvironments(environments []*models.Environment) {
+	for _, environment := range environments {
+		if environment.SecretVariables == nil {
+			continue
+		}
+
+		secrets := requestModels.JSONMap{}
+		for name := range environment.SecretVariables {
+			secrets[name] = requestHelpers.RedactedValue
+		}
+		environment.SecretVariables = secrets
+	}
+}
+
+// KeepSecretVariables returns the secret variables of an update with the
+// stored values of those sent back redacted. Members who can't see secrets
+// can't change the secret variables either: they keep the stored ones.
+func KeepSecretVariables(stored requestModels.JSONMap, updated requestModels.JSONMap, canSeeSecrets bool) requestModels.JSONMap {
+	if !canSeeSecrets {
+		return stored
+	}
+
+	secrets := requestModels.JSONMap{}
+	for name, value := range updated {
+		if value == requestHelpers.RedactedValue {
+			storedValue, ok := stored[name]
+			if !ok {
+				continue
+			}
+			value = storedValue
+		}
+		secrets[name] = value
+	}
+	return secrets
+}
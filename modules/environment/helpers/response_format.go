@@ -0,0 +1,71 @@
+package helpers
+
+import (
+	"github.com/jeksilaen/api-builder/modules/environment/models"
+)
+
+func ReturnFailedCreateResponse(message string) *models.FailedResponse {
+	return &models.FailedResponse{
+		Error:   "Create failed",
+		Message: message,
+		Links: []models.Link{
+			{
+				Rel:  "create environment",
+				Href: "/users/v1/environment",
+			},
+		},
+	}
+}
+
+func toEnvironmentResponse(environment *models.Environment) models.EnvironmentResponse {
+	return models.EnvironmentResponse{
+		ID:              environment.ID,
+		CollectionID:    environment.CollectionID,
+		Name:            environment.Name,
+		Variables:       environment.Variables,
+		SecretVariables: environment.SecretVariables,
+	}
+}
+
+func ReturnSucessCreateResponse(environment *models.Environment) *models.SucessCreateResponse {
+	return &models.SucessCreateResponse{
+		Message: "Save Environment sucessfully",
+		Data:    toEnvironmentResponse(environment),
+		Links: []models.Link{
+			{
+				Rel:  "get environments",
+				Href: "/users/v1/environment_by_collection/" + environment.CollectionID,
+			},
+		},
+	}
+}
+
+func ReturnSucessGetResponse(environments []*models.Environment) *models.SucessGetResponse {
+	environmentResponses := []models.EnvironmentResponse{}
+	for _, environment := range environments {
+		environmentResponses = append(environmentResponses, toEnvironmentResponse(environment))
+	}
+
+	return &models.SucessGetResponse{
+		Message: "Get Environment sucessfully",
+		Data:    environmentResponses,
+		Links: []models.Link{
+			{
+				Rel:  "create environment",
+				Href: "/users/v1/environment",
+			},
+		},
+	}
+}
+
+func ReturnSucessDeleteResponse(message string) *models.SucessDeleteResponse {
+	return &models.SucessDeleteResponse{
+		Message: message,
+		Links: []models.Link{
+			{
+				Rel:  "create environment",
+				Href: "/users/v1/environment",
+			},
+		},
+	}
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+	"gorm.io/gorm"
+)
+
+// Environment holds the {{variables}} substituted into the requests of a
+// collection when it is run. Secrets are kept apart so they can be hidden.
+type Environment struct {
+	gorm.Model
+	ID              string                      `gorm:"type:uuid;primaryKey"`
+	CollectionID    string                      `json:"collection_id" gorm:"type:uuid;not null;index" validate:"required"`
+	Name            string                      `json:"name" validate:"required"`
+	Variables       requestModels.JSONMap       `json:"variables" gorm:"type:json"`
+	SecretVariables requestModels.JSONMap       `json:"secret_variables" gorm:"type:json"`
+	Collection      collectionModels.Collection `gorm:"foreignKey:CollectionID" json:"-" validate:"-"`
+}
+
+type EnvironmentResponse struct {
+	ID              string                `json:"id"`
+	CollectionID    string                `json:"collection_id"`
+	Name            string                `json:"name"`
+	Variables       requestModels.JSONMap `json:"variables"`
+	SecretVariables requestModels.JSONMap `json:"secret_variables"`
+}
+
+type SucessCreateResponse struct {
+	Message string              `json:"message"`
+	Data    EnvironmentResponse `json:"data"`
+	Links   []Link              `json:"links"`
+}
+
+type SucessGetResponse struct {
+	Message string                `json:"message"`
+	Data    []EnvironmentResponse `json:"data"`
+	Links   []Link                `json:"links"`
+}
+
+type SucessDeleteResponse struct {
+	Message string `json:"message"`
+	Links   []Link `json:"links"`
+}
+
+type FailedResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+	Links   []Link `json:"links"`
+}
+
+type Link struct {
+	Rel  string `json:"rel"`
+	Href string `json:"href"`
+}
+
+// Values merges plain and secret variables into the map used to run a
+// collection.
+func (environment *Environment) Values() map[string]string {
+	values := make(map[string]string)
+	for key, value := range environment.Variables {
+		values[key] = toString(value)
+	}
+	for key, value := range environment.SecretVariables {
+		values[key] = toString(value)
+	}
+	return values
+}
+
+func (environment *Environment) BeforeCreate(tx *gorm.DB) error {
+	environment.ID = uuid.New().String()
+	return nil
+}
+
+// toString renders a variable value the way it is substituted into a
+// request: strings as-is, anything else as JSON.
+func toString(value interface{}) string {
+	if text, ok := value.(string); ok {
+		return text
+	}
+	if encoded, err := json.Marshal(value); err == nil {
+		return string(encoded)
+	}
+	return fmt.Sprint(value)
+}
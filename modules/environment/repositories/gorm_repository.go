@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	collectionRepositories "github.com/jeksilaen/api-builder/modules/collection/repositories"
+	"github.com/jeksilaen/api-builder/modules/environment/models"
+	"gorm.io/gorm"
+)
+
+// GormEnvironmentRepository stores environments with gorm, on any of the
+// databases of db.Open.
+type GormEnvironmentRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormEnvironmentRepository(db *gorm.DB) *GormEnvironmentRepository {
+	return &GormEnvironmentRepository{DB: db}
+}
+
+func (r *GormEnvironmentRepository) FindAccessibleByCollectionID(userID string, collectionID string) ([]*models.Environment, error) {
+	var environments []*models.Environment
+	err := r.DB.Scopes(collectionRepositories.CollectionAccessibleBy(userID)).Where("collection_id = ?", collectionID).Find(&environments).Error
+	return environments, err
+}
+
+func (r *GormEnvironmentRepository) FindAccessible(userID string, environmentID string) (*models.Environment, error) {
+	var environment models.Environment
+	err := r.DB.Scopes(collectionRepositories.CollectionAccessibleBy(userID)).Where("id = ?", environmentID).First(&environment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &environment, nil
+}
+
+func (r *GormEnvironmentRepository) FindByID(environmentID string) (*models.Environment, error) {
+	var environment models.Environment
+	if err := r.DB.Where("id = ?", environmentID).First(&environment).Error; err != nil {
+		return nil, err
+	}
+	return &environment, nil
+}
+
+func (r *GormEnvironmentRepository) Create(environment *models.Environment) error {
+	return r.DB.Omit("Collection").Create(environment).Error
+}
+
+func (r *GormEnvironmentRepository) Save(environment *models.Environment) error {
+	return r.DB.Omit("Collection").Save(environment).Error
+}
+
+func (r *GormEnvironmentRepository) Delete(environment *models.Environment) error {
+	return r.DB.Delete(environment).Error
+}
+
+func (r *GormEnvironmentRepository) DeleteByCollections(collectionIDs []string) error {
+	return r.DB.Unscoped().Where("collection_id IN ?", collectionIDs).Delete(&models.Environment{}).Error
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"github.com/jeksilaen/api-builder/modules/environment/models"
+)
+
+// EnvironmentRepository stores the environments of collections. Lookups of a
+// missing record fail with db.ErrNotFound.
+type EnvironmentRepository interface {
+	// FindAccessibleByCollectionID returns the environments of a collection
+	// in a workspace of userID
+	FindAccessibleByCollectionID(userID string, collectionID string) ([]*models.Environment, error)
+	// FindAccessible returns the environment if its collection is in a
+	// workspace of userID
+	FindAccessible(userID string, environmentID string) (*models.Environment, error)
+	FindByID(environmentID string) (*models.Environment, error)
+	Create(environment *models.Environment) error
+	Save(environment *models.Environment) error
+	Delete(environment *models.Environment) error
+	// DeleteByCollections deletes for good the environments of the
+	// collections, soft deleted ones too
+	DeleteByCollections(collectionIDs []string) error
+}
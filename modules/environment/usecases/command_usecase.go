@@ -0,0 +1,95 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/jeksilaen/api-builder/db"
+	collectionUsecases "github.com/jeksilaen/api-builder/modules/collection/usecases"
+	"github.com/jeksilaen/api-builder/modules/environment/models"
+	"github.com/jeksilaen/api-builder/modules/environment/repositories"
+)
+
+type EnvironmentCommandUsecase struct {
+	Repo        repositories.EnvironmentRepository
+	Collections *collectionUsecases.CollectionCommandUsecase
+}
+
+func NewEnvironmentCommandUsecase(repo repositories.EnvironmentRepository, collections *collectionUsecases.CollectionCommandUsecase) *EnvironmentCommandUsecase {
+	return &EnvironmentCommandUsecase{
+		Repo:        repo,
+		Collections: collections,
+	}
+}
+
+func (uc *EnvironmentCommandUsecase) GetEnvironmentsByCollectionID(userID string, collectionID string) ([]*models.Environment, error) {
+	environments, err := uc.Repo.FindAccessibleByCollectionID(userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return environments, nil
+}
+
+func (uc *EnvironmentCommandUsecase) GetEnvironmentByID(userID string, environmentID string) (*models.Environment, error) {
+	environment, err := uc.Repo.FindAccessible(userID, environmentID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, errors.New("Environment not found")
+		}
+		return nil, err
+	}
+
+	return environment, nil
+}
+
+// GetCollectionEnvironment returns an environment of a collection whatever
+// workspace it is in, for the runs the server starts itself such as
+// monitors.
+func (uc *EnvironmentCommandUsecase) GetCollectionEnvironment(collectionID string, environmentID string) (*models.Environment, error) {
+	environment, err := uc.Repo.FindByID(environmentID)
+	if errors.Is(err, db.ErrNotFound) || (err == nil && environment.CollectionID != collectionID) {
+		return nil, errors.New("Environment not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return environment, nil
+}
+
+func (uc *EnvironmentCommandUsecase) CreateEnvironment(ctx context.Context, userID string, environment *models.Environment) (*models.Environment, error) {
+	if _, err := uc.Collections.GetCollectionByIDWithoutPreload(userID, environment.CollectionID); err != nil {
+		return nil, err
+	}
+
+	err := uc.Repo.Create(environment)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidReference) {
+			return nil, errors.New("Collection Id Not Found")
+		}
+
+		slog.ErrorContext(ctx, "Error creating environment", "error", err)
+		return nil, err
+	}
+
+	return environment, nil
+}
+
+func (uc *EnvironmentCommandUsecase) UpdateEnvironment(environment *models.Environment) (*models.Environment, error) {
+	err := uc.Repo.Save(environment)
+	if err != nil {
+		return nil, err
+	}
+	return environment, nil
+}
+
+func (uc *EnvironmentCommandUsecase) DeleteEnvironment(userID string, environmentID string) error {
+	environment, err := uc.GetEnvironmentByID(userID, environmentID)
+	if err != nil {
+		return err
+	}
+
+	return uc.Repo.Delete(environment)
+}
@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/jeksilaen/api-builder/middlewares"
+	"github.com/jeksilaen/api-builder/modules/mock/helpers"
+	"github.com/jeksilaen/api-builder/modules/mock/models"
+	"github.com/jeksilaen/api-builder/modules/mock/usecases"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+	requestUsecases "github.com/jeksilaen/api-builder/modules/request/usecases"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+)
+
+// maxNearMisses caps how many failed examples the 404 diagnostic lists.
+const maxNearMisses = 5
+
+// maxMockDelay caps the delay of a mock response, whatever was stored.
+const maxMockDelay = 10 * time.Second
+
+// maxMockBodySize caps how much of an incoming body is read for matching.
+const maxMockBodySize = 1 << 20
+
+// MockHttpHandler serves the mock routes with the usecases it is given.
+type MockHttpHandler struct {
+	Mocks *usecases.MockCommandUsecase
+}
+
+func NewMockHttpHandler(mocks *usecases.MockCommandUsecase) *MockHttpHandler {
+	return &MockHttpHandler{
+		Mocks: mocks,
+	}
+}
+
+func InitMockHttpHandler(router *gin.Engine, auth *middlewares.Auth, h *MockHttpHandler) {
+	router.Any("/mock/:collection_id/*path", h.ServeMock)
+
+	ofRequest := auth.WorkspaceOfRecord("requests", "request_id")
+
+	router.GET("/users/v1/request/:request_id/examples", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermRead, ofRequest), h.GetExamples)
+	router.POST("/users/v1/request/:request_id/examples", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, ofRequest), h.CreateExample)
+	router.PUT("/users/v1/request/:request_id/examples/:example_id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, ofRequest), h.UpdateExample)
+	router.DELETE("/users/v1/request/:request_id/examples/:example_id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, ofRequest), h.DeleteExample)
+}
+
+func (h *MockHttpHandler) ServeMock(ctx *gin.Context) {
+
+	collectionID := ctx.Param("collection_id")
+	path := ctx.Param("path")
+	method := ctx.Request.Method
+
+	// Only collections marked as mocked are served
+	_, err := h.Mocks.GetMockedCollection(ctx.Request.Context(), collectionID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, helpers.ReturnFailedMockResponse(err.Error(), method, path))
+		return
+	}
+
+	// Find the saved requests matching the incoming method and path
+	requests, err := h.Mocks.FindMockRequests(collectionID, method, path)
+	if errors.Is(err, usecases.ErrNoMatchingRequest) {
+		ctx.JSON(http.StatusNotFound, helpers.ReturnFailedMockResponse(err.Error(), method, path))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, helpers.ReturnFailedMockResponse("Could not load the mocked requests", method, path))
+		return
+	}
+
+	// Decode the incoming body once for the body rules
+	var body interface{}
+	bodyBytes, err := io.ReadAll(io.LimitReader(ctx.Request.Body, maxMockBodySize))
+	if err == nil && len(bodyBytes) > 0 {
+		if err := json.Unmarshal(bodyBytes, &body); err != nil {
+			body = nil
+		}
+	}
+
+	var nearMisses []models.NearMiss
+	for _, request := range requests {
+		examples, err := h.Mocks.GetExamplesByRequestID(request.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, helpers.ReturnFailedMockResponse("Could not load the mocked examples", method, path))
+			return
+		}
+
+		// Requests without examples replay their stored response
+		if len(examples) == 0 {
+			replayRequest(ctx, request)
+			return
+		}
+
+		var best *models.MockExample
+		for _, example := range examples {
+			failures := helpers.MatchExample(example, ctx.Request, body)
+			if len(failures) > 0 {
+				nearMisses = append(nearMisses, models.NearMiss{
+					RequestID:   request.ID,
+					ExampleID:   example.ID,
+					ExampleName: example.Name,
+					Failures:    failures,
+				})
+				continue
+			}
+
+			if best == nil || example.Priority > best.Priority ||
+				(example.Priority == best.Priority && helpers.RuleCount(example) > helpers.RuleCount(best)) {
+				best = example
+			}
+		}
+
+		if best != nil {
+			replayExample(ctx, best)
+			return
+		}
+	}
+
+	// List the examples that came closest to matching
+	sort.SliceStable(nearMisses, func(i, j int) bool {
+		return len(nearMisses[i].Failures) < len(nearMisses[j].Failures)
+	})
+	if len(nearMisses) > maxNearMisses {
+		nearMisses = nearMisses[:maxNearMisses]
+	}
+
+	ctx.JSON(http.StatusNotFound, helpers.ReturnFailedMatchResponse(method, path, nearMisses))
+}
+
+// delay waits milliseconds, at most maxMockDelay, and reports false if the
+// client went away meanwhile.
+func delay(ctx *gin.Context, milliseconds int) bool {
+	if milliseconds <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(min(time.Duration(milliseconds)*time.Millisecond, maxMockDelay))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Request.Context().Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// replayRequest writes the stored response of a request with its mock
+// status, headers and delay.
+func replayRequest(ctx *gin.Context, request *requestModels.Request) {
+	if !delay(ctx, request.MockDelay) {
+		return
+	}
+
+	status := request.MockStatus
+	if status == 0 {
+		status = request.ResponseStatus
+	}
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	contentType := writeMockHeaders(ctx, request.MockHeaders)
+
+	// Bodies that weren't JSON objects replay as they were received
+	if request.RawResponse != "" {
+		for key, value := range request.ResponseHeaders {
+			if contentType == "" && http.CanonicalHeaderKey(key) == "Content-Type" {
+				contentType = fmt.Sprint(value)
+			}
+		}
+		ctx.Data(status, helpers.PassiveContentType(contentType, "text/plain; charset=utf-8"), []byte(request.RawResponse))
+		return
+	}
+
+	ctx.Header("Content-Type", helpers.PassiveContentType(contentType, "application/json; charset=utf-8"))
+	ctx.JSON(status, request.Response)
+}
+
+// writeMockHeaders sets the stored headers that are safe to replay, along
+// with headers keeping browsers from running the response on our origin.
+// It returns the stored Content-Type, which callers vet themselves.
+func writeMockHeaders(ctx *gin.Context, headers requestModels.JSONMap) string {
+	contentType := ""
+	for key, value := range headers {
+		if http.CanonicalHeaderKey(key) == "Content-Type" {
+			contentType = fmt.Sprint(value)
+			continue
+		}
+		if helpers.ReplayableHeader(key) {
+			ctx.Header(key, fmt.Sprint(value))
+		}
+	}
+
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Header("Content-Security-Policy", "sandbox")
+	return contentType
+}
+
+// replayExample writes the body of an example as-is, under its stored
+// Content-Type when that is a passive one.
+func replayExample(ctx *gin.Context, example *models.MockExample) {
+	if !delay(ctx, example.Delay) {
+		return
+	}
+
+	status := example.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	fallback := "text/plain; charset=utf-8"
+	if json.Valid([]byte(example.Body)) {
+		fallback = "application/json; charset=utf-8"
+	}
+	contentType := writeMockHeaders(ctx, example.ResponseHeaders)
+
+	ctx.Data(status, helpers.PassiveContentType(contentType, fallback), []byte(example.Body))
+}
+
+func (h *MockHttpHandler) GetExamples(ctx *gin.Context) {
+
+	requestID := ctx.Param("request_id")
+
+	if requestID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Request ID is required"})
+		return
+	}
+
+	// Get the examples from usecase
+	examples, err := h.Mocks.GetOwnedExamplesByRequestID(middlewares.GetUserID(ctx), requestID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Examples not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessGetExamplesResponse(examples))
+}
+
+func (h *MockHttpHandler) CreateExample(ctx *gin.Context) {
+	validate := validator.New()
+
+	requestID := ctx.Param("request_id")
+
+	if requestID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Request ID is required"})
+		return
+	}
+
+	// Decode the request JSON data into MockExample object
+	var req models.MockExample
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedExampleResponse(err.Error()))
+		return
+	}
+	req.RequestID = requestID
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedExampleResponse(err.Error()))
+		return
+	}
+
+	// Create the example
+	createdExample, err := h.Mocks.CreateExample(ctx.Request.Context(), middlewares.GetUserID(ctx), &req)
+	if errors.Is(err, requestUsecases.ErrRequestNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Request not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedExampleResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, helpers.ReturnSucessCreateExampleResponse(createdExample))
+}
+
+func (h *MockHttpHandler) UpdateExample(ctx *gin.Context) {
+	validate := validator.New()
+
+	requestID := ctx.Param("request_id")
+	exampleID := ctx.Param("example_id")
+
+	if requestID == "" || exampleID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Request ID and Example ID are required"})
+		return
+	}
+
+	// Decode the request JSON data into MockExample object
+	var req models.MockExample
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedExampleResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedExampleResponse(err.Error()))
+		return
+	}
+
+	// Get the existing example from usecase
+	existingExample, err := h.Mocks.GetExampleByID(middlewares.GetUserID(ctx), requestID, exampleID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Example not found"})
+		return
+	}
+
+	existingExample.Name = req.Name
+	existingExample.Priority = req.Priority
+	existingExample.MatchQuery = req.MatchQuery
+	existingExample.MatchHeaders = req.MatchHeaders
+	existingExample.MatchBody = req.MatchBody
+	existingExample.Status = req.Status
+	existingExample.ResponseHeaders = req.ResponseHeaders
+	existingExample.Body = req.Body
+	existingExample.Delay = req.Delay
+
+	// Save the updated example
+	updatedExample, err := h.Mocks.UpdateExample(existingExample)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedExampleResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessCreateExampleResponse(updatedExample))
+}
+
+func (h *MockHttpHandler) DeleteExample(ctx *gin.Context) {
+
+	requestID := ctx.Param("request_id")
+	exampleID := ctx.Param("example_id")
+
+	if requestID == "" || exampleID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Request ID and Example ID are required"})
+		return
+	}
+
+	// Get the existing example from usecase
+	existingExample, err := h.Mocks.GetExampleByID(middlewares.GetUserID(ctx), requestID, exampleID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Example not found"})
+		return
+	}
+
+	// Delete the example
+	if err := h.Mocks.DeleteExample(existingExample); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedExampleResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessDeleteExampleResponse("Deleted Example Successfully"))
+}
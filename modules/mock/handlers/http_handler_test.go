@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jeksilaen/api-builder/db/dbtest"
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	"github.com/jeksilaen/api-builder/modules/mock/models"
+	"github.com/jeksilaen/api-builder/modules/mock/repositories"
+	"github.com/jeksilaen/api-builder/modules/mock/usecases"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+	userModels "github.com/jeksilaen/api-builder/modules/user/models"
+	"gorm.io/gorm"
+)
+
+// newMockServer returns a router serving the mocks of a new mocked
+// collection, and the database to add its requests to.
+func newMockServer(t *testing.T) (*gin.Engine, *gorm.DB, *collectionModels.Collection) {
+	t.Helper()
+	gormDB := dbtest.Open(t)
+
+	user := &userModels.User{Email: "alice@example.com", Username: "alice", Password: "hash"}
+	if err := gormDB.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	collection := &collectionModels.Collection{UserID: user.ID, Name: "Orders", Mocked: true}
+	if err := gormDB.Create(collection).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := NewMockHttpHandler(usecases.NewMockCommandUsecase(repositories.NewGormMockRepository(gormDB), nil))
+	router.Any("/mock/:collection_id/*path", h.ServeMock)
+	return router, gormDB, collection
+}
+
+func serve(router *gin.Engine, method string, target string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(method, target, nil))
+	return recorder
+}
+
+// checkMockHeaders fails unless the response keeps the stored headers that
+// are harmless and drops the others.
+func checkMockHeaders(t *testing.T, header http.Header) {
+	t.Helper()
+	if got := header.Get("X-Rate-Limit"); got != "10" {
+		t.Errorf("X-Rate-Limit = %q, want 10", got)
+	}
+	for _, key := range []string{"Set-Cookie", "Location", "Access-Control-Allow-Origin", "Access-Control-Allow-Credentials"} {
+		if got := header.Get(key); got != "" {
+			t.Errorf("%s = %q, want it dropped", key, got)
+		}
+	}
+	if got := header.Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Errorf("X-Content-Type-Options = %q, want nosniff", got)
+	}
+	if got := header.Get("Content-Security-Policy"); got != "sandbox" {
+		t.Errorf("Content-Security-Policy = %q, want sandbox", got)
+	}
+}
+
+// storedHeaders are headers saved with a response, the harmful ones included.
+var storedHeaders = requestModels.JSONMap{
+	"X-Rate-Limit":                     "10",
+	"Set-Cookie":                       "session=stolen; Path=/",
+	"Location":                         "https://evil.example.com",
+	"access-control-allow-origin":      "https://evil.example.com",
+	"Access-Control-Allow-Credentials": "true",
+	"Content-Security-Policy":          "default-src *",
+	"Content-Type":                     "text/html",
+}
+
+func TestServeMockReplaysRequest(t *testing.T) {
+	router, gormDB, collection := newMockServer(t)
+	request := &requestModels.Request{
+		ID:           uuid.New().String(),
+		CollectionID: collection.ID,
+		Name:         "Get order",
+		URL:          "{{baseUrl}}/orders/:id",
+		Method:       "GET",
+		Response:     requestModels.JSONMap{"id": "<script>alert(1)</script>"},
+		MockStatus:   http.StatusCreated,
+		MockHeaders:  storedHeaders,
+	}
+	if err := gormDB.Create(request).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := serve(router, "GET", "/mock/"+collection.ID+"/orders/7")
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusCreated, recorder.Body)
+	}
+	checkMockHeaders(t, recorder.Header())
+	if got := recorder.Header().Get("Content-Type"); got != "application/json; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want JSON", got)
+	}
+}
+
+func TestServeMockKeepsPassiveContentType(t *testing.T) {
+	router, gormDB, collection := newMockServer(t)
+	request := &requestModels.Request{
+		ID:           uuid.New().String(),
+		CollectionID: collection.ID,
+		Name:         "Get problem",
+		URL:          "/problem",
+		Method:       "GET",
+		Response:     requestModels.JSONMap{"title": "Not allowed"},
+		MockHeaders:  requestModels.JSONMap{"Content-Type": "application/problem+json"},
+	}
+	if err := gormDB.Create(request).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := serve(router, "GET", "/mock/"+collection.ID+"/problem")
+	if got := recorder.Header().Get("Content-Type"); got != "application/problem+json" {
+		t.Errorf("Content-Type = %q, want application/problem+json", got)
+	}
+}
+
+func TestServeMockReplaysExample(t *testing.T) {
+	router, gormDB, collection := newMockServer(t)
+	request := &requestModels.Request{
+		ID:           uuid.New().String(),
+		CollectionID: collection.ID,
+		Name:         "Get page",
+		URL:          "/page",
+		Method:       "GET",
+	}
+	if err := gormDB.Create(request).Error; err != nil {
+		t.Fatal(err)
+	}
+	example := &models.MockExample{
+		RequestID:       request.ID,
+		Name:            "HTML page",
+		Status:          http.StatusOK,
+		ResponseHeaders: storedHeaders,
+		Body:            "<script>alert(document.cookie)</script>",
+	}
+	if err := gormDB.Create(example).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := serve(router, "GET", "/mock/"+collection.ID+"/page")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", recorder.Code, recorder.Body)
+	}
+	checkMockHeaders(t, recorder.Header())
+	if got := recorder.Header().Get("Content-Type"); got != "text/plain; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want text/plain instead of the stored text/html", got)
+	}
+	if recorder.Body.String() != example.Body {
+		t.Errorf("body = %q, want %q", recorder.Body, example.Body)
+	}
+}
+
+func TestServeMockPicksMatchingExample(t *testing.T) {
+	router, gormDB, collection := newMockServer(t)
+	request := &requestModels.Request{
+		ID:           uuid.New().String(),
+		CollectionID: collection.ID,
+		Name:         "List orders",
+		URL:          "/orders",
+		Method:       "GET",
+	}
+	if err := gormDB.Create(request).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, example := range []*models.MockExample{
+		{RequestID: request.ID, Name: "Any", Body: `{"page":"any"}`},
+		{RequestID: request.ID, Name: "Page 2", Priority: 1, MatchQuery: requestModels.JSONMap{"page": "2"}, Body: `{"page":2}`},
+	} {
+		if err := gormDB.Create(example).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recorder := serve(router, "GET", "/mock/"+collection.ID+"/orders?page=2")
+	if recorder.Body.String() != `{"page":2}` {
+		t.Errorf("page 2 body = %s, want the Page 2 example", recorder.Body)
+	}
+	if got := recorder.Header().Get("Content-Type"); got != "application/json; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want JSON", got)
+	}
+
+	recorder = serve(router, "GET", "/mock/"+collection.ID+"/orders?page=3")
+	if recorder.Body.String() != `{"page":"any"}` {
+		t.Errorf("page 3 body = %s, want the Any example", recorder.Body)
+	}
+}
+
+func TestServeMockListsNearMisses(t *testing.T) {
+	router, gormDB, collection := newMockServer(t)
+	request := &requestModels.Request{
+		ID:           uuid.New().String(),
+		CollectionID: collection.ID,
+		Name:         "List orders",
+		URL:          "/orders",
+		Method:       "GET",
+	}
+	if err := gormDB.Create(request).Error; err != nil {
+		t.Fatal(err)
+	}
+	example := &models.MockExample{RequestID: request.ID, Name: "Page 2", MatchQuery: requestModels.JSONMap{"page": "2"}}
+	if err := gormDB.Create(example).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := serve(router, "GET", "/mock/"+collection.ID+"/orders")
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", recorder.Code)
+	}
+	var response models.FailedResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.NearMisses) != 1 || response.NearMisses[0].ExampleID != example.ID {
+		t.Errorf("near misses = %+v, want the Page 2 example", response.NearMisses)
+	}
+
+	if recorder := serve(router, "GET", "/mock/"+uuid.New().String()+"/orders"); recorder.Code != http.StatusNotFound {
+		t.Errorf("status of an unknown collection = %d, want 404", recorder.Code)
+	}
+}
+
+func TestServeMockReplaysRawResponse(t *testing.T) {
+	router, gormDB, collection := newMockServer(t)
+	request := &requestModels.Request{
+		ID:              uuid.New().String(),
+		CollectionID:    collection.ID,
+		Name:            "Health",
+		URL:             "/health",
+		Method:          "GET",
+		RawResponse:     "OK\n",
+		ResponseHeaders: requestModels.JSONMap{"content-type": "text/csv"},
+	}
+	if err := gormDB.Create(request).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := serve(router, "GET", "/mock/"+collection.ID+"/health")
+	if recorder.Body.String() != "OK\n" || recorder.Header().Get("Content-Type") != "text/csv" {
+		t.Errorf("response = %q as %q, want the raw text as text/csv", recorder.Body, recorder.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,89 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jeksilaen/api-builder/modules/mock/models"
+)
+
+// MatchExample checks every rule of example against the incoming request
+// and returns a description of each rule that failed. body is the decoded
+// JSON body, or nil when the body is empty or not JSON.
+func MatchExample(example *models.MockExample, req *http.Request, body interface{}) []string {
+	var failures []string
+
+	query := req.URL.Query()
+	for name, expected := range example.MatchQuery {
+		values, ok := query[name]
+		if !ok {
+			failures = append(failures, fmt.Sprintf("query %s is missing", name))
+		} else if want := fmt.Sprint(expected); want != "*" && !containsString(values, want) {
+			failures = append(failures, fmt.Sprintf("query %s is %q, want %q", name, strings.Join(values, ","), want))
+		}
+	}
+
+	for name, expected := range example.MatchHeaders {
+		value := req.Header.Get(name)
+		if value == "" {
+			failures = append(failures, fmt.Sprintf("header %s is missing", name))
+		} else if want := fmt.Sprint(expected); want != "*" && value != want {
+			failures = append(failures, fmt.Sprintf("header %s is %q, want %q", name, value, want))
+		}
+	}
+
+	for path, expected := range example.MatchBody {
+		if body == nil {
+			failures = append(failures, fmt.Sprintf("body %s is missing, the body is not JSON", path))
+			continue
+		}
+
+		value, found, err := LookupJSONPath(body, path)
+		if err != nil {
+			failures = append(failures, err.Error())
+		} else if !found {
+			failures = append(failures, fmt.Sprintf("body %s is missing", path))
+		} else if expected != "*" && !jsonEqual(value, expected) {
+			failures = append(failures, fmt.Sprintf("body %s is %s, want %s", path, jsonText(value), jsonText(expected)))
+		}
+	}
+
+	return failures
+}
+
+// RuleCount is the number of rules an example has, used to prefer the more
+// specific example when priorities are equal.
+func RuleCount(example *models.MockExample) int {
+	return len(example.MatchQuery) + len(example.MatchHeaders) + len(example.MatchBody)
+}
+
+func containsString(values []string, want string) bool {
+	for _, value := range values {
+		if value == want {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonEqual compares two decoded JSON values, also accepting a string rule
+// for a number or boolean in the body.
+func jsonEqual(value, expected interface{}) bool {
+	if jsonText(value) == jsonText(expected) {
+		return true
+	}
+	if text, ok := expected.(string); ok {
+		return jsonText(value) == text
+	}
+	return false
+}
+
+func jsonText(value interface{}) string {
+	text, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(text)
+}
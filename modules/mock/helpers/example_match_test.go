@@ -0,0 +1,48 @@
+package helpers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jeksilaen/api-builder/modules/mock/models"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+)
+
+func TestMatchExample(t *testing.T) {
+	example := &models.MockExample{
+		MatchQuery:   requestModels.JSONMap{"page": "2", "sort": "*"},
+		MatchHeaders: requestModels.JSONMap{"X-Tenant": "acme"},
+		MatchBody:    requestModels.JSONMap{"$.user.id": float64(7), "$.user.admin": "true", "$.note": "*"},
+	}
+	body := map[string]interface{}{
+		"user": map[string]interface{}{"id": float64(7), "admin": true},
+		"note": "anything",
+	}
+
+	req := httptest.NewRequest("POST", "/users?page=2&sort=name", nil)
+	req.Header.Set("X-Tenant", "acme")
+	if failures := MatchExample(example, req, body); len(failures) != 0 {
+		t.Errorf("failures = %v, want none", failures)
+	}
+
+	req = httptest.NewRequest("POST", "/users?page=3", nil)
+	req.Header.Set("X-Tenant", "other")
+	failures := MatchExample(example, req, map[string]interface{}{"user": map[string]interface{}{"id": float64(8)}})
+	if len(failures) != 6 {
+		t.Errorf("got %d failures, want 6: %v", len(failures), failures)
+	}
+
+	if failures := MatchExample(&models.MockExample{MatchBody: requestModels.JSONMap{"$.id": "1"}}, req, nil); len(failures) != 1 {
+		t.Errorf("body rule on a non JSON body: failures = %v", failures)
+	}
+}
+
+func TestRuleCount(t *testing.T) {
+	example := &models.MockExample{
+		MatchQuery:   requestModels.JSONMap{"page": "1"},
+		MatchHeaders: requestModels.JSONMap{"X-Tenant": "acme", "Accept": "*"},
+	}
+	if count := RuleCount(example); count != 3 {
+		t.Errorf("RuleCount = %d, want 3", count)
+	}
+}
@@ -0,0 +1,74 @@
+package helpers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LookupJSONPath resolves a simple JSONPath such as $.user.id,
+// $.items[0].name or $['first name'] against decoded JSON. Filters,
+// wildcards and recursive descent are not supported.
+func LookupJSONPath(document interface{}, path string) (interface{}, bool, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, false, fmt.Errorf("JSONPath %q must start with $", path)
+	}
+
+	current := document
+	rest := path[1:]
+	for rest != "" {
+		var key string
+		index := -1
+
+		switch {
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			key = rest[:end]
+			rest = rest[end:]
+		case strings.HasPrefix(rest, "['"):
+			end := strings.Index(rest, "']")
+			if end == -1 {
+				return nil, false, fmt.Errorf("JSONPath %q has an unclosed bracket", path)
+			}
+			key = rest[2:end]
+			rest = rest[end+2:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, false, fmt.Errorf("JSONPath %q has an unclosed bracket", path)
+			}
+			var err error
+			index, err = strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, false, fmt.Errorf("JSONPath %q has an invalid index", path)
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, false, fmt.Errorf("JSONPath %q is not supported", path)
+		}
+
+		if index >= 0 {
+			list, ok := current.([]interface{})
+			if !ok || index >= len(list) {
+				return nil, false, nil
+			}
+			current = list[index]
+			continue
+		}
+
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false, nil
+		}
+		current, ok = object[key]
+		if !ok {
+			return nil, false, nil
+		}
+	}
+
+	return current, true, nil
+}
@@ -0,0 +1,59 @@
+package helpers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLookupJSONPath(t *testing.T) {
+	var document interface{}
+	err := json.Unmarshal([]byte(`{
+		"user": {"id": 7, "first name": "Alice"},
+		"items": [{"name": "pen"}, {"name": "ink"}],
+		"active": true
+	}`), &document)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path  string
+		want  string
+		found bool
+	}{
+		{"$", "", true},
+		{"$.user.id", "7", true},
+		{"$['user']['first name']", `"Alice"`, true},
+		{"$.items[1].name", `"ink"`, true},
+		{"$.active", "true", true},
+		{"$.items[2]", "", false},
+		{"$.user.missing", "", false},
+		{"$.active.id", "", false},
+		{"$.items.name", "", false},
+	}
+
+	for _, test := range tests {
+		value, found, err := LookupJSONPath(document, test.path)
+		if err != nil {
+			t.Errorf("%s: %v", test.path, err)
+			continue
+		}
+		if found != test.found {
+			t.Errorf("%s: found = %v, want %v", test.path, found, test.found)
+			continue
+		}
+		if found && test.want != "" && jsonText(value) != test.want {
+			t.Errorf("%s = %s, want %s", test.path, jsonText(value), test.want)
+		}
+	}
+}
+
+func TestLookupJSONPathErrors(t *testing.T) {
+	document := map[string]interface{}{"items": []interface{}{"a"}}
+
+	for _, path := range []string{"items", "$.items[-1]", "$.items[x]", "$.items[0", "$['items'", "$*"} {
+		if _, _, err := LookupJSONPath(document, path); err == nil {
+			t.Errorf("%s: no error", path)
+		}
+	}
+}
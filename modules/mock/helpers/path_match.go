@@ -0,0 +1,61 @@
+package helpers
+
+import (
+	"net/url"
+	"strings"
+)
+
+// RequestPath returns the path part of a saved request URL. URLs that start
+// with a variable such as {{baseUrl}} are treated as if the variable were
+// the scheme and host.
+func RequestPath(rawURL string) string {
+	if strings.HasPrefix(rawURL, "{{") {
+		if end := strings.Index(rawURL, "}}"); end != -1 {
+			rawURL = rawURL[end+2:]
+		}
+	} else if parsedURL, err := url.Parse(rawURL); err == nil && parsedURL.Host != "" {
+		rawURL = parsedURL.EscapedPath()
+	}
+
+	if index := strings.IndexAny(rawURL, "?#"); index != -1 {
+		rawURL = rawURL[:index]
+	}
+	return "/" + strings.Trim(rawURL, "/")
+}
+
+// MatchPath compares an incoming path against a saved request path. Segments
+// written as :id, {id}, {{id}} or * match any value. The score is higher for
+// literal matches so /users/me wins over /users/:id.
+func MatchPath(pattern, path string) (int, bool) {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+
+	if len(patternSegments) != len(pathSegments) {
+		return 0, false
+	}
+
+	score := 0
+	for i, segment := range patternSegments {
+		if isPathVariable(segment) {
+			score++
+			continue
+		}
+
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			unescaped = segment
+		}
+		if unescaped != pathSegments[i] {
+			return 0, false
+		}
+		score += 2
+	}
+
+	return score, true
+}
+
+func isPathVariable(segment string) bool {
+	return segment == "*" ||
+		strings.HasPrefix(segment, ":") ||
+		(strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"))
+}
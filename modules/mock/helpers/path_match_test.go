@@ -0,0 +1,45 @@
+package helpers
+
+import (
+	"testing"
+)
+
+func TestRequestPath(t *testing.T) {
+	tests := map[string]string{
+		"https://api.example.com/users/7?page=1": "/users/7",
+		"https://api.example.com":                "/",
+		"{{baseUrl}}/users/:id#top":              "/users/:id",
+		"/orders/":                               "/orders",
+	}
+
+	for rawURL, want := range tests {
+		if path := RequestPath(rawURL); path != want {
+			t.Errorf("RequestPath(%q) = %q, want %q", rawURL, path, want)
+		}
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		score   int
+		ok      bool
+	}{
+		{"/users/me", "/users/me", 4, true},
+		{"/users/:id", "/users/me", 3, true},
+		{"/users/{id}", "/users/7", 3, true},
+		{"/users/{{id}}/orders", "/users/7/orders/", 5, true},
+		{"/files/*", "/files/a b", 3, true},
+		{"/files/a%20b", "/files/a b", 4, true},
+		{"/users/:id", "/users/7/orders", 0, false},
+		{"/users/me", "/users/you", 0, false},
+	}
+
+	for _, test := range tests {
+		score, ok := MatchPath(test.pattern, test.path)
+		if ok != test.ok || score != test.score {
+			t.Errorf("MatchPath(%q, %q) = %d, %v, want %d, %v", test.pattern, test.path, score, ok, test.score, test.ok)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package helpers
+
+import (
+	"github.com/jeksilaen/api-builder/modules/mock/models"
+)
+
+func ReturnFailedMockResponse(message string, method string, path string) *models.FailedResponse {
+	return &models.FailedResponse{
+		Error:   "Mock not found",
+		Message: message,
+		Method:  method,
+		Path:    path,
+		Links: []models.Link{
+			{
+				Rel:  "mock collection",
+				Href: "/users/v1/collection/:id/mock",
+			},
+		},
+	}
+}
+
+func ReturnFailedMatchResponse(method string, path string, nearMisses []models.NearMiss) *models.FailedResponse {
+	return &models.FailedResponse{
+		Error:      "Mock not found",
+		Message:    "No example matches " + method + " " + path,
+		Method:     method,
+		Path:       path,
+		NearMisses: nearMisses,
+		Links: []models.Link{
+			{
+				Rel:  "mock examples",
+				Href: "/users/v1/request/:request_id/examples",
+			},
+		},
+	}
+}
+
+func ReturnFailedExampleResponse(message string) *models.FailedResponse {
+	return &models.FailedResponse{
+		Error:   "Save example failed",
+		Message: message,
+		Links: []models.Link{
+			{
+				Rel:  "mock examples",
+				Href: "/users/v1/request/:request_id/examples",
+			},
+		},
+	}
+}
+
+func toExampleResponse(example *models.MockExample) models.MockExampleResponse {
+	return models.MockExampleResponse{
+		ID:              example.ID,
+		RequestID:       example.RequestID,
+		Name:            example.Name,
+		Priority:        example.Priority,
+		MatchQuery:      example.MatchQuery,
+		MatchHeaders:    example.MatchHeaders,
+		MatchBody:       example.MatchBody,
+		Status:          example.Status,
+		ResponseHeaders: example.ResponseHeaders,
+		Body:            example.Body,
+		Delay:           example.Delay,
+	}
+}
+
+func ReturnSucessCreateExampleResponse(example *models.MockExample) *models.SucessCreateResponse {
+	return &models.SucessCreateResponse{
+		Message: "Save example sucessfully",
+		Data:    toExampleResponse(example),
+		Links: []models.Link{
+			{
+				Rel:  "get examples",
+				Href: "/users/v1/request/" + example.RequestID + "/examples",
+			},
+		},
+	}
+}
+
+func ReturnSucessGetExamplesResponse(examples []*models.MockExample) *models.SucessGetResponse {
+	exampleResponses := []models.MockExampleResponse{}
+	for _, example := range examples {
+		exampleResponses = append(exampleResponses, toExampleResponse(example))
+	}
+
+	return &models.SucessGetResponse{
+		Message: "Get examples sucessfully",
+		Data:    exampleResponses,
+		Links: []models.Link{
+			{
+				Rel:  "create example",
+				Href: "/users/v1/request/:request_id/examples",
+			},
+		},
+	}
+}
+
+func ReturnSucessDeleteExampleResponse(message string) *models.SucessDeleteResponse {
+	return &models.SucessDeleteResponse{
+		Message: message,
+		Links: []models.Link{
+			{
+				Rel:  "create example",
+				Href: "/users/v1/request/:request_id/examples",
+			},
+		},
+	}
+}
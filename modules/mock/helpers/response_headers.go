@@ -0,0 +1,83 @@
+package helpers
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// blockedHeaders are the stored headers never replayed: mocks are served
+// unauthenticated on the API's own origin, so they must not set cookies,
+// redirect, or change what browsers allow on it.
+var blockedHeaders = map[string]bool{
+	"Set-Cookie":                          true,
+	"Set-Cookie2":                         true,
+	"Location":                            true,
+	"Refresh":                             true,
+	"Link":                                true,
+	"Content-Security-Policy":             true,
+	"Content-Security-Policy-Report-Only": true,
+	"Strict-Transport-Security":           true,
+	"Public-Key-Pins":                     true,
+	"X-Frame-Options":                     true,
+	"X-Content-Type-Options":              true,
+	"X-Xss-Protection":                    true,
+	"Permissions-Policy":                  true,
+	"Feature-Policy":                      true,
+	"Referrer-Policy":                     true,
+	"Clear-Site-Data":                     true,
+	"Service-Worker-Allowed":              true,
+	"Origin-Agent-Cluster":                true,
+	"Www-Authenticate":                    true,
+	"Alt-Svc":                             true,
+	"Content-Type":                        true,
+	"Content-Length":                      true,
+	"Content-Encoding":                    true,
+	"Transfer-Encoding":                   true,
+	"Connection":                          true,
+}
+
+// blockedHeaderPrefixes are the families of headers never replayed.
+var blockedHeaderPrefixes = []string{"Access-Control-", "Cross-Origin-", "Sec-"}
+
+// passiveContentTypes are the media types browsers neither render as a
+// document nor run.
+var passiveContentTypes = map[string]bool{
+	"application/json":         true,
+	"application/octet-stream": true,
+	"text/plain":               true,
+	"text/csv":                 true,
+	"image/png":                true,
+	"image/jpeg":               true,
+	"image/gif":                true,
+	"image/webp":               true,
+	"image/avif":               true,
+}
+
+// ReplayableHeader reports whether a stored response header may be sent by
+// the mock server.
+func ReplayableHeader(key string) bool {
+	key = http.CanonicalHeaderKey(key)
+	if blockedHeaders[key] {
+		return false
+	}
+	for _, prefix := range blockedHeaderPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// PassiveContentType returns contentType if it is a passive media type,
+// JSON ones with a +json suffix included, and fallback otherwise.
+func PassiveContentType(contentType string, fallback string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fallback
+	}
+	if passiveContentTypes[mediaType] || (strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json")) {
+		return contentType
+	}
+	return fallback
+}
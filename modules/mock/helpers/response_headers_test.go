@@ -0,0 +1,51 @@
+package helpers
+
+import (
+	"testing"
+)
+
+func TestReplayableHeader(t *testing.T) {
+	tests := map[string]bool{
+		"X-Rate-Limit":                     true,
+		"cache-control":                    true,
+		"ETag":                             true,
+		"set-cookie":                       false,
+		"Location":                         false,
+		"Access-Control-Allow-Origin":      false,
+		"access-control-allow-credentials": false,
+		"Cross-Origin-Opener-Policy":       false,
+		"Content-Security-Policy":          false,
+		"Strict-Transport-Security":        false,
+		"Content-Type":                     false,
+	}
+
+	for key, want := range tests {
+		if got := ReplayableHeader(key); got != want {
+			t.Errorf("ReplayableHeader(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestPassiveContentType(t *testing.T) {
+	const fallback = "text/plain; charset=utf-8"
+	tests := map[string]string{
+		"application/json":          "application/json",
+		"application/problem+json":  "application/problem+json",
+		"text/plain; charset=utf-8": "text/plain; charset=utf-8",
+		"image/png":                 "image/png",
+		"text/html":                 fallback,
+		"TEXT/HTML; charset=utf-8":  fallback,
+		"image/svg+xml":             fallback,
+		"application/javascript":    fallback,
+		"application/xml":           fallback,
+		"application/xhtml+xml":     fallback,
+		"text/plain, text/html":     fallback,
+		"":                          fallback,
+	}
+
+	for contentType, want := range tests {
+		if got := PassiveContentType(contentType, fallback); got != want {
+			t.Errorf("PassiveContentType(%q) = %q, want %q", contentType, got, want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+	"gorm.io/gorm"
+)
+
+// MockExample is one named response of a request on the mock server. It is
+// served when every match rule holds; among matching examples the highest
+// Priority wins.
+type MockExample struct {
+	gorm.Model
+	ID              string                `gorm:"type:uuid;primaryKey"`
+	RequestID       string                `json:"request_id" gorm:"type:uuid;not null;index"`
+	Name            string                `json:"name" validate:"required"`
+	Priority        int                   `json:"priority"`
+	MatchQuery      requestModels.JSONMap `json:"match_query" gorm:"type:json"`
+	MatchHeaders    requestModels.JSONMap `json:"match_headers" gorm:"type:json"`
+	MatchBody       requestModels.JSONMap `json:"match_body" gorm:"type:json"`
+	Status          int                   `json:"status" validate:"omitempty,min=100,max=599"`
+	ResponseHeaders requestModels.JSONMap `json:"response_headers" gorm:"type:json"`
+	Body            string                `json:"body"`
+	Delay           int                   `json:"delay" validate:"min=0,max=10000"`
+	Request         requestModels.Request `gorm:"foreignKey:RequestID" json:"-" validate:"-"`
+}
+
+type MockExampleResponse struct {
+	ID              string                `json:"id"`
+	RequestID       string                `json:"request_id"`
+	Name            string                `json:"name"`
+	Priority        int                   `json:"priority"`
+	MatchQuery      requestModels.JSONMap `json:"match_query"`
+	MatchHeaders    requestModels.JSONMap `json:"match_headers"`
+	MatchBody       requestModels.JSONMap `json:"match_body"`
+	Status          int                   `json:"status"`
+	ResponseHeaders requestModels.JSONMap `json:"response_headers"`
+	Body            string                `json:"body"`
+	Delay           int                   `json:"delay"`
+}
+
+// NearMiss is an example that matched the method and path but failed some
+// of its rules.
+type NearMiss struct {
+	RequestID   string   `json:"request_id"`
+	ExampleID   string   `json:"example_id"`
+	ExampleName string   `json:"example_name"`
+	Failures    []string `json:"failures"`
+}
+
+type SucessCreateResponse struct {
+	Message string              `json:"message"`
+	Data    MockExampleResponse `json:"data"`
+	Links   []Link              `json:"links"`
+}
+
+type SucessGetResponse struct {
+	Message string                `json:"message"`
+	Data    []MockExampleResponse `json:"data"`
+	Links   []Link                `json:"links"`
+}
+
+type SucessDeleteResponse struct {
+	Message string `json:"message"`
+	Links   []Link `json:"links"`
+}
+
+type FailedResponse struct {
+	Error      string     `json:"error"`
+	Message    string     `json:"message"`
+	Method     string     `json:"method,omitempty"`
+	Path       string     `json:"path,omitempty"`
+	NearMisses []NearMiss `json:"near_misses,omitempty"`
+	Links      []Link     `json:"links"`
+}
+
+type Link struct {
+	Rel  string `json:"rel"`
+	Href string `json:"href"`
+}
+
+func (example *MockExample) BeforeCreate(tx *gorm.DB) error {
+	example.ID = uuid.New().String()
+	return nil
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	"github.com/jeksilaen/api-builder/modules/mock/models"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+	"gorm.io/gorm"
+)
+
+// GormMockRepository stores mock examples with gorm, on any of the databases
+// of db.Open.
+type GormMockRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormMockRepository(db *gorm.DB) *GormMockRepository {
+	return &GormMockRepository{DB: db}
+}
+
+func (r *GormMockRepository) FindMockedCollection(collectionID string) (*collectionModels.Collection, error) {
+	var collection collectionModels.Collection
+	if err := r.DB.Where("id = ? AND mocked = ?", collectionID, true).First(&collection).Error; err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+func (r *GormMockRepository) FindRequestsByMethod(collectionID string, method string) ([]*requestModels.Request, error) {
+	var requests []*requestModels.Request
+	err := r.DB.Where("collection_id = ? AND method = ?", collectionID, method).Find(&requests).Error
+	return requests, err
+}
+
+func (r *GormMockRepository) FindExamples(requestID string) ([]*models.MockExample, error) {
+	var examples []*models.MockExample
+	err := r.DB.Where("request_id = ?", requestID).Order("priority desc").Find(&examples).Error
+	return examples, err
+}
+
+func (r *GormMockRepository) FindExample(requestID string, exampleID string) (*models.MockExample, error) {
+	var example models.MockExample
+	if err := r.DB.Where("id = ? AND request_id = ?", exampleID, requestID).First(&example).Error; err != nil {
+		return nil, err
+	}
+	return &example, nil
+}
+
+func (r *GormMockRepository) Create(example *models.MockExample) error {
+	return r.DB.Omit("Request").Create(example).Error
+}
+
+func (r *GormMockRepository) Save(example *models.MockExample) error {
+	return r.DB.Omit("Request").Save(example).Error
+}
+
+func (r *GormMockRepository) Delete(example *models.MockExample) error {
+	return r.DB.Delete(example).Error
+}
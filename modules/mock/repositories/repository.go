@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	"github.com/jeksilaen/api-builder/modules/mock/models"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+)
+
+// MockRepository stores the examples of requests and finds what a mock
+// server answers with. Lookups of a missing record fail with db.ErrNotFound.
+type MockRepository interface {
+	FindMockedCollection(collectionID string) (*collectionModels.Collection, error)
+	FindRequestsByMethod(collectionID string, method string) ([]*requestModels.Request, error)
+	// FindExamples returns the examples of a request, highest priority first
+	FindExamples(requestID string) ([]*models.MockExample, error)
+	FindExample(requestID string, exampleID string) (*models.MockExample, error)
+	Create(example *models.MockExample) error
+	Save(example *models.MockExample) error
+	Delete(example *models.MockExample) error
+}
@@ -0,0 +1,155 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+
+	"github.com/jeksilaen/api-builder/db"
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	"github.com/jeksilaen/api-builder/modules/mock/helpers"
+	"github.com/jeksilaen/api-builder/modules/mock/models"
+	"github.com/jeksilaen/api-builder/modules/mock/repositories"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+	requestUsecases "github.com/jeksilaen/api-builder/modules/request/usecases"
+)
+
+// Errors returned when serving a mock. They say no more than that nothing
+// is mocked there.
+var (
+	ErrMockedCollectionNotFound = errors.New("Mocked collection not found")
+	ErrNoMatchingRequest        = errors.New("No request in the collection matches")
+)
+
+type MockCommandUsecase struct {
+	Repo     repositories.MockRepository
+	Requests *requestUsecases.RequestCommandUsecase
+}
+
+func NewMockCommandUsecase(repo repositories.MockRepository, requests *requestUsecases.RequestCommandUsecase) *MockCommandUsecase {
+	return &MockCommandUsecase{
+		Repo:     repo,
+		Requests: requests,
+	}
+}
+
+// GetMockedCollection returns the collection if it is mocked. Any other
+// failure, such as an ID the database refuses, is reported as not found.
+func (uc *MockCommandUsecase) GetMockedCollection(ctx context.Context, collectionID string) (*collectionModels.Collection, error) {
+	collection, err := uc.Repo.FindMockedCollection(collectionID)
+	if err != nil {
+		if !errors.Is(err, db.ErrNotFound) {
+			slog.WarnContext(ctx, "Error finding mocked collection", "collection_id", collectionID, "error", err)
+		}
+		return nil, ErrMockedCollectionNotFound
+	}
+
+	return collection, nil
+}
+
+// FindMockRequests returns the requests of the collection whose method and
+// path match the incoming call, best match first.
+func (uc *MockCommandUsecase) FindMockRequests(collectionID string, method string, path string) ([]*requestModels.Request, error) {
+	requests, err := uc.Repo.FindRequestsByMethod(collectionID, method)
+	if err != nil {
+		return nil, err
+	}
+
+	type scoredRequest struct {
+		request *requestModels.Request
+		score   int
+	}
+	var matches []scoredRequest
+	for _, request := range requests {
+		if score, ok := helpers.MatchPath(helpers.RequestPath(request.URL), path); ok {
+			matches = append(matches, scoredRequest{request: request, score: score})
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil, ErrNoMatchingRequest
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	matched := make([]*requestModels.Request, 0, len(matches))
+	for _, match := range matches {
+		matched = append(matched, match.request)
+	}
+	return matched, nil
+}
+
+// GetExamplesByRequestID returns the examples of a request, highest priority
+// first.
+func (uc *MockCommandUsecase) GetExamplesByRequestID(requestID string) ([]*models.MockExample, error) {
+	examples, err := uc.Repo.FindExamples(requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	return examples, nil
+}
+
+// GetOwnedExamplesByRequestID returns the examples of a request that belongs
+// to userID, highest priority first.
+func (uc *MockCommandUsecase) GetOwnedExamplesByRequestID(userID string, requestID string) ([]*models.MockExample, error) {
+	if _, err := uc.getOwnedRequest(userID, requestID); err != nil {
+		return nil, err
+	}
+
+	return uc.GetExamplesByRequestID(requestID)
+}
+
+func (uc *MockCommandUsecase) GetExampleByID(userID string, requestID string, exampleID string) (*models.MockExample, error) {
+	if _, err := uc.getOwnedRequest(userID, requestID); err != nil {
+		return nil, errors.New("Example not found")
+	}
+
+	example, err := uc.Repo.FindExample(requestID, exampleID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, errors.New("Example not found")
+		}
+		return nil, err
+	}
+
+	return example, nil
+}
+
+func (uc *MockCommandUsecase) CreateExample(ctx context.Context, userID string, example *models.MockExample) (*models.MockExample, error) {
+	if _, err := uc.getOwnedRequest(userID, example.RequestID); err != nil {
+		return nil, err
+	}
+
+	err := uc.Repo.Create(example)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidReference) {
+			return nil, errors.New("Request Id Not Found")
+		}
+
+		slog.ErrorContext(ctx, "Error creating example", "error", err)
+		return nil, err
+	}
+
+	return example, nil
+}
+
+func (uc *MockCommandUsecase) UpdateExample(example *models.MockExample) (*models.MockExample, error) {
+	err := uc.Repo.Save(example)
+	if err != nil {
+		return nil, err
+	}
+	return example, nil
+}
+
+func (uc *MockCommandUsecase) DeleteExample(example *models.MockExample) error {
+	return uc.Repo.Delete(example)
+}
+
+// getOwnedRequest returns the request if its collection belongs to userID.
+func (uc *MockCommandUsecase) getOwnedRequest(userID string, requestID string) (*requestModels.Request, error) {
+	return uc.Requests.GetRequestByIDWithoutPreload(userID, requestID)
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/jeksilaen/api-builder/middlewares"
+	collectionUsecases "github.com/jeksilaen/api-builder/modules/collection/usecases"
+	"github.com/jeksilaen/api-builder/modules/monitor/helpers"
+	"github.com/jeksilaen/api-builder/modules/monitor/models"
+	"github.com/jeksilaen/api-builder/modules/monitor/usecases"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+)
+
+// MonitorHttpHandler serves the monitor routes with the usecases it is given.
+type MonitorHttpHandler struct {
+	Monitors *usecases.MonitorCommandUsecase
+}
+
+func NewMonitorHttpHandler(monitors *usecases.MonitorCommandUsecase) *MonitorHttpHandler {
+	return &MonitorHttpHandler{
+		Monitors: monitors,
+	}
+}
+
+func InitMonitorHttpHandler(router *gin.Engine, auth *middlewares.Auth, h *MonitorHttpHandler) {
+	ofMonitor := auth.WorkspaceOfRecord("monitors", "id")
+
+	router.GET("/users/v1/monitor_by_collection/:collection_id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermRead, auth.WorkspaceOfCollection("collection_id")), h.GetMonitorsByCollection)
+	router.GET("/users/v1/monitor/:id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermRead, ofMonitor), h.GetMonitor)
+	router.POST("/users/v1/monitor", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, auth.WorkspaceOfBodyCollection("collection_id")), h.CreateMonitor)
+	router.PUT("/users/v1/monitor/:id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, ofMonitor), h.UpdateMonitor)
+	router.DELETE("/users/v1/monitor/:id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, ofMonitor), h.DeleteMonitor)
+	router.POST("/users/v1/monitor/:id/run", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermRun, ofMonitor), h.RunMonitor)
+	router.GET("/users/v1/monitor/:id/runs", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermRead, ofMonitor), h.GetMonitorRuns)
+	router.GET("/users/v1/monitor/:id/trends", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermRead, ofMonitor), h.GetMonitorTrends)
+}
+
+func (h *MonitorHttpHandler) GetMonitorsByCollection(ctx *gin.Context) {
+
+	collectionID := ctx.Param("collection_id")
+
+	if collectionID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Collection ID is required"})
+		return
+	}
+
+	// Get the monitors from usecase
+	monitors, err := h.Monitors.GetMonitorsByCollectionID(middlewares.GetUserID(ctx), collectionID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Monitors not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessGetResponse(monitors))
+}
+
+func (h *MonitorHttpHandler) GetMonitor(ctx *gin.Context) {
+
+	monitorID := ctx.Param("id")
+
+	if monitorID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Monitor ID is required"})
+		return
+	}
+
+	// Get the monitor from usecase
+	monitor, err := h.Monitors.GetMonitorByID(middlewares.GetUserID(ctx), monitorID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Monitor not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessCreateResponse(monitor))
+}
+
+func (h *MonitorHttpHandler) CreateMonitor(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into MonitorRequest object
+	var req models.MonitorRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Monitors are enabled unless asked otherwise
+	monitor := &models.Monitor{
+		CollectionID:  req.CollectionID,
+		EnvironmentID: req.EnvironmentID,
+		Name:          req.Name,
+		Cron:          req.Cron,
+		Enabled:       req.Enabled == nil || *req.Enabled,
+	}
+
+	// Create the monitor
+	createdMonitor, err := h.Monitors.CreateMonitor(ctx.Request.Context(), middlewares.GetUserID(ctx), monitor)
+	if errors.Is(err, collectionUsecases.ErrCollectionNotFound) {
+		ctx.JSON(http.StatusNotFound, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, helpers.ReturnSucessCreateResponse(createdMonitor))
+}
+
+func (h *MonitorHttpHandler) UpdateMonitor(ctx *gin.Context) {
+	validate := validator.New()
+
+	monitorID := ctx.Param("id")
+
+	if monitorID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Monitor ID is required"})
+		return
+	}
+
+	// Decode the request JSON data into MonitorRequest object
+	var req models.MonitorRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.StructPartial(req, "Name", "Cron")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Get the existing monitor from usecase
+	existingMonitor, err := h.Monitors.GetMonitorByID(middlewares.GetUserID(ctx), monitorID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Monitor not found"})
+		return
+	}
+
+	existingMonitor.Name = req.Name
+	existingMonitor.Cron = req.Cron
+	existingMonitor.EnvironmentID = req.EnvironmentID
+	if req.Enabled != nil {
+		existingMonitor.Enabled = *req.Enabled
+	}
+
+	// Save the updated monitor
+	updatedMonitor, err := h.Monitors.UpdateMonitor(middlewares.GetUserID(ctx), existingMonitor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessCreateResponse(updatedMonitor))
+}
+
+func (h *MonitorHttpHandler) DeleteMonitor(ctx *gin.Context) {
+
+	monitorID := ctx.Param("id")
+
+	if monitorID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Monitor ID is required"})
+		return
+	}
+
+	// Delete the monitor with its reports
+	err := h.Monitors.DeleteMonitor(middlewares.GetUserID(ctx), monitorID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Monitor not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessDeleteResponse("Deleted Monitor Successfully"))
+}
+
+func (h *MonitorHttpHandler) RunMonitor(ctx *gin.Context) {
+
+	monitorID := ctx.Param("id")
+
+	if monitorID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Monitor ID is required"})
+		return
+	}
+
+	monitor, err := h.Monitors.GetMonitorByID(middlewares.GetUserID(ctx), monitorID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Monitor not found"})
+		return
+	}
+
+	// Run the monitor now, outside of its schedule
+	run, err := h.Monitors.RunMonitor(ctx.Request.Context(), monitor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedRunResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessRunResponse(run))
+}
+
+func (h *MonitorHttpHandler) GetMonitorRuns(ctx *gin.Context) {
+
+	monitorID := ctx.Param("id")
+
+	if monitorID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Monitor ID is required"})
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+		return
+	}
+
+	// Get the latest reports from usecase
+	runs, err := h.Monitors.GetRunsByMonitorID(middlewares.GetUserID(ctx), monitorID, limit)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Monitor runs not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessGetRunsResponse(runs))
+}
+
+func (h *MonitorHttpHandler) GetMonitorTrends(ctx *gin.Context) {
+
+	monitorID := ctx.Param("id")
+
+	if monitorID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Monitor ID is required"})
+		return
+	}
+
+	bucket := ctx.DefaultQuery("bucket", "hour")
+	if _, ok := helpers.BucketSize[bucket]; !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "bucket must be hour or day"})
+		return
+	}
+
+	// Default to the last 7 days
+	since := time.Now().Add(-7 * 24 * time.Hour)
+	if value := ctx.Query("since"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "since must be an RFC 3339 time"})
+			return
+		}
+		since = parsed
+	}
+
+	// Aggregate the reports from usecase
+	trend, err := h.Monitors.GetTrend(middlewares.GetUserID(ctx), monitorID, since, bucket)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Monitor runs not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessTrendResponse(trend))
+}
@@ -0,0 +1,17 @@
+package helpers
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// NextRun returns the first time after from matched by a standard five
+// field cron expression. Descriptors such as @hourly or @every 5m work too.
+func NextRun(expression string, from time.Time) (time.Time, error) {
+	schedule, err := cron.ParseStandard(expression)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.Next(from), nil
+}
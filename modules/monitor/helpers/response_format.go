@@ -0,0 +1,149 @@
+package helpers
+
+import (
+	"github.com/jeksilaen/api-builder/modules/monitor/models"
+)
+
+func ReturnFailedCreateResponse(message string) *models.FailedResponse {
+	return &models.FailedResponse{
+		Error:   "Create failed",
+		Message: message,
+		Links: []models.Link{
+			{
+				Rel:  "create monitor",
+				Href: "/users/v1/monitor",
+			},
+		},
+	}
+}
+
+func ReturnFailedRunResponse(message string) *models.FailedResponse {
+	return &models.FailedResponse{
+		Error:   "Run failed",
+		Message: message,
+		Links: []models.Link{
+			{
+				Rel:  "get monitor runs",
+				Href: "/users/v1/monitor/:id/runs",
+			},
+		},
+	}
+}
+
+func toMonitorResponse(monitor *models.Monitor) models.MonitorResponse {
+	return models.MonitorResponse{
+		ID:            monitor.ID,
+		CollectionID:  monitor.CollectionID,
+		EnvironmentID: monitor.EnvironmentID,
+		Name:          monitor.Name,
+		Cron:          monitor.Cron,
+		Enabled:       monitor.Enabled,
+		LastRunAt:     monitor.LastRunAt,
+		NextRunAt:     monitor.NextRunAt,
+	}
+}
+
+func toMonitorRunResponse(run *models.MonitorRun) models.MonitorRunResponse {
+	return models.MonitorRunResponse{
+		ID:        run.ID,
+		MonitorID: run.MonitorID,
+		StartedAt: run.StartedAt,
+		Duration:  run.Duration,
+		Total:     run.Total,
+		Passed:    run.Passed,
+		Failed:    run.Failed,
+		Success:   run.Success,
+		Results:   run.Results,
+	}
+}
+
+func ReturnSucessCreateResponse(monitor *models.Monitor) *models.SucessCreateResponse {
+	return &models.SucessCreateResponse{
+		Message: "Save Monitor sucessfully",
+		Data:    toMonitorResponse(monitor),
+		Links: []models.Link{
+			{
+				Rel:  "get monitor runs",
+				Href: "/users/v1/monitor/" + monitor.ID + "/runs",
+			},
+			{
+				Rel:  "get monitor trends",
+				Href: "/users/v1/monitor/" + monitor.ID + "/trends",
+			},
+		},
+	}
+}
+
+func ReturnSucessGetResponse(monitors []*models.Monitor) *models.SucessGetResponse {
+	monitorResponses := []models.MonitorResponse{}
+	for _, monitor := range monitors {
+		monitorResponses = append(monitorResponses, toMonitorResponse(monitor))
+	}
+
+	return &models.SucessGetResponse{
+		Message: "Get Monitor sucessfully",
+		Data:    monitorResponses,
+		Links: []models.Link{
+			{
+				Rel:  "create monitor",
+				Href: "/users/v1/monitor",
+			},
+		},
+	}
+}
+
+func ReturnSucessRunResponse(run *models.MonitorRun) *models.SucessRunResponse {
+	return &models.SucessRunResponse{
+		Message: "Run Monitor sucessfully",
+		Data:    toMonitorRunResponse(run),
+		Links: []models.Link{
+			{
+				Rel:  "get monitor runs",
+				Href: "/users/v1/monitor/" + run.MonitorID + "/runs",
+			},
+		},
+	}
+}
+
+func ReturnSucessGetRunsResponse(runs []*models.MonitorRun) *models.SucessGetRunsResponse {
+	runResponses := []models.MonitorRunResponse{}
+	for _, run := range runs {
+		runResponses = append(runResponses, toMonitorRunResponse(run))
+	}
+
+	return &models.SucessGetRunsResponse{
+		Message: "Get Monitor Runs sucessfully",
+		Data:    runResponses,
+		Links: []models.Link{
+			{
+				Rel:  "get monitor trends",
+				Href: "/users/v1/monitor/:id/trends",
+			},
+		},
+	}
+}
+
+func ReturnSucessTrendResponse(trend models.TrendResponse) *models.SucessTrendResponse {
+	return &models.SucessTrendResponse{
+		Message: "Get Monitor Trends sucessfully",
+		Data:    trend,
+		Links: []models.Link{
+			{
+				Rel:  "get monitor runs",
+				Href: "/users/v1/monitor/" + trend.MonitorID + "/runs",
+			},
+		},
+	}
+}
+
+func ReturnSucessDeleteResponse(message string) *models.SucessDeleteResponse {
+	return &models.SucessDeleteResponse{
+		Message: message,
+		Links: []models.Link{
+			{
+				Rel:  "create monitor",
+				Href: "/users/v1/monitor",
+			},
+		},
+	}
+}
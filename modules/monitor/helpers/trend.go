@@ -0,0 +1,92 @@
+package helpers
+
+import (
+	"time"
+
+	"github.com/jeksilaen/api-builder/modules/monitor/models"
+)
+
+// BucketSize maps the bucket query value to its duration.
+var BucketSize = map[string]time.Duration{
+	"hour": time.Hour,
+	"day":  24 * time.Hour,
+}
+
+// BuildTrend groups runs, oldest first, into buckets and computes the
+// success rate and latency of each bucket and of the whole range.
+func BuildTrend(monitorID string, runs []*models.MonitorRun, since time.Time, bucket string) models.TrendResponse {
+	trend := models.TrendResponse{
+		MonitorID: monitorID,
+		Since:     since,
+		Bucket:    bucket,
+		Points:    []models.TrendPoint{},
+	}
+
+	var total trendTotals
+	var current trendTotals
+	var point *models.TrendPoint
+
+	for _, run := range runs {
+		bucketStart := run.StartedAt.UTC().Truncate(BucketSize[bucket])
+		if point == nil || !point.BucketStart.Equal(bucketStart) {
+			if point != nil {
+				current.apply(point)
+				trend.Points = append(trend.Points, *point)
+			}
+			point = &models.TrendPoint{BucketStart: bucketStart}
+			current = trendTotals{}
+		}
+
+		current.add(run)
+		total.add(run)
+	}
+
+	if point != nil {
+		current.apply(point)
+		trend.Points = append(trend.Points, *point)
+	}
+
+	trend.Runs = total.runs
+	if total.runs > 0 {
+		trend.SuccessRate = float64(total.successes) / float64(total.runs)
+		trend.AvgDuration = float64(total.duration) / float64(total.runs)
+	}
+	if total.responses > 0 {
+		trend.AvgResponseTime = float64(total.responseTime) / float64(total.responses)
+	}
+
+	return trend
+}
+
+type trendTotals struct {
+	runs         int
+	successes    int
+	duration     int64
+	responses    int
+	responseTime int64
+}
+
+func (totals *trendTotals) add(run *models.MonitorRun) {
+	totals.runs++
+	if run.Success {
+		totals.successes++
+	}
+	totals.duration += run.Duration
+	for _, result := range run.Results {
+		if result.Status != 0 {
+			totals.responses++
+			totals.responseTime += result.ResponseTime
+		}
+	}
+}
+
+func (totals *trendTotals) apply(point *models.TrendPoint) {
+	point.Runs = totals.runs
+	if totals.runs > 0 {
+		point.SuccessRate = float64(totals.successes) / float64(totals.runs)
+		point.AvgDuration = float64(totals.duration) / float64(totals.runs)
+	}
+	if totals.responses > 0 {
+		point.AvgResponseTime = float64(totals.responseTime) / float64(totals.responses)
+	}
+}
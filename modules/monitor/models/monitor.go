@@ -0,0 +1,146 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+	"gorm.io/gorm"
+)
+
+// Monitor runs a collection on a cron schedule, optionally with the
+// variables of one of its environments.
+type Monitor struct {
+	gorm.Model
+	ID            string                      `gorm:"type:uuid;primaryKey"`
+	CollectionID  string                      `json:"collection_id" gorm:"type:uuid;not null;index"`
+	EnvironmentID *string                     `json:"environment_id" gorm:"type:uuid"`
+	Name          string                      `json:"name"`
+	Cron          string                      `json:"cron"`
+	Enabled       bool                        `json:"enabled"`
+	LastRunAt     *time.Time                  `json:"last_run_at"`
+	NextRunAt     *time.Time                  `json:"next_run_at" gorm:"index"`
+	Collection    collectionModels.Collection `gorm:"foreignKey:CollectionID" json:"-"`
+}
+
+// MonitorRun is the stored report of one monitor execution.
+type MonitorRun struct {
+	gorm.Model
+	ID        string                   `gorm:"type:uuid;primaryKey"`
+	MonitorID string                   `json:"monitor_id" gorm:"type:uuid;not null;index"`
+	StartedAt time.Time                `json:"started_at" gorm:"index"`
+	Duration  int64                    `json:"duration"`
+	Total     int                      `json:"total"`
+	Passed    int                      `json:"passed"`
+	Failed    int                      `json:"failed"`
+	Success   bool                     `json:"success"`
+	Results   requestModels.RunResults `json:"results" gorm:"type:json"`
+}
+
+type MonitorRequest struct {
+	CollectionID  string  `json:"collection_id" validate:"required"`
+	EnvironmentID *string `json:"environment_id"`
+	Name          string  `json:"name" validate:"required"`
+	Cron          string  `json:"cron" validate:"required"`
+	Enabled       *bool   `json:"enabled"`
+}
+
+type MonitorResponse struct {
+	ID            string     `json:"id"`
+	CollectionID  string     `json:"collection_id"`
+	EnvironmentID *string    `json:"environment_id"`
+	Name          string     `json:"name"`
+	Cron          string     `json:"cron"`
+	Enabled       bool       `json:"enabled"`
+	LastRunAt     *time.Time `json:"last_run_at"`
+	NextRunAt     *time.Time `json:"next_run_at"`
+}
+
+type MonitorRunResponse struct {
+	ID        string                   `json:"id"`
+	MonitorID string                   `json:"monitor_id"`
+	StartedAt time.Time                `json:"started_at"`
+	Duration  int64                    `json:"duration"`
+	Total     int                      `json:"total"`
+	Passed    int                      `json:"passed"`
+	Failed    int                      `json:"failed"`
+	Success   bool                     `json:"success"`
+	Results   requestModels.RunResults `json:"results"`
+}
+
+// TrendPoint aggregates the runs that started within one bucket.
+type TrendPoint struct {
+	BucketStart     time.Time `json:"bucket_start"`
+	Runs            int       `json:"runs"`
+	SuccessRate     float64   `json:"success_rate"`
+	AvgDuration     float64   `json:"avg_duration"`
+	AvgResponseTime float64   `json:"avg_response_time"`
+}
+
+type TrendResponse struct {
+	MonitorID       string       `json:"monitor_id"`
+	Since           time.Time    `json:"since"`
+	Bucket          string       `json:"bucket"`
+	Runs            int          `json:"runs"`
+	SuccessRate     float64      `json:"success_rate"`
+	AvgDuration     float64      `json:"avg_duration"`
+	AvgResponseTime float64      `json:"avg_response_time"`
+	Points          []TrendPoint `json:"points"`
+}
+
+type SucessCreateResponse struct {
+	Message string          `json:"message"`
+	Data    MonitorResponse `json:"data"`
+	Links   []Link          `json:"links"`
+}
+
+type SucessGetResponse struct {
+	Message string            `json:"message"`
+	Data    []MonitorResponse `json:"data"`
+	Links   []Link            `json:"links"`
+}
+
+type SucessRunResponse struct {
+	Message string             `json:"message"`
+	Data    MonitorRunResponse `json:"data"`
+	Links   []Link             `json:"links"`
+}
+
+type SucessGetRunsResponse struct {
+	Message string               `json:"message"`
+	Data    []MonitorRunResponse `json:"data"`
+	Links   []Link               `json:"links"`
+}
+
+type SucessTrendResponse struct {
+	Message string        `json:"message"`
+	Data    TrendResponse `json:"data"`
+	Links   []Link        `json:"links"`
+}
+
+type SucessDeleteResponse struct {
+	Message string `json:"message"`
+	Links   []Link `json:"links"`
+}
+
+type FailedResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+	Links   []Link `json:"links"`
+}
+
+type Link struct {
+	Rel  string `json:"rel"`
+	Href string `json:"href"`
+}
+
+func (monitor *Monitor) BeforeCreate(tx *gorm.DB) error {
+	monitor.ID = uuid.New().String()
+	return nil
+}
+
+func (run *MonitorRun) BeforeCreate(tx *gorm.DB) error {
+	run.ID = uuid.New().String()
+	return nil
+}
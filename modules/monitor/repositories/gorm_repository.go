@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"time"
+
+	collectionRepositories "github.com/jeksilaen/api-builder/modules/collection/repositories"
+	"github.com/jeksilaen/api-builder/modules/monitor/models"
+	"gorm.io/gorm"
+)
+
+// GormMonitorRepository stores monitors with gorm, on any of the databases
+// of db.Open.
+type GormMonitorRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormMonitorRepository(db *gorm.DB) *GormMonitorRepository {
+	return &GormMonitorRepository{DB: db}
+}
+
+func (r *GormMonitorRepository) FindAccessibleByCollectionID(userID string, collectionID string) ([]*models.Monitor, error) {
+	var monitors []*models.Monitor
+	err := r.DB.Scopes(collectionRepositories.CollectionAccessibleBy(userID)).Where("collection_id = ?", collectionID).Find(&monitors).Error
+	return monitors, err
+}
+
+func (r *GormMonitorRepository) FindAccessible(userID string, monitorID string) (*models.Monitor, error) {
+	var monitor models.Monitor
+	err := r.DB.Scopes(collectionRepositories.CollectionAccessibleBy(userID)).Where("id = ?", monitorID).First(&monitor).Error
+	if err != nil {
+		return nil, err
+	}
+	return &monitor, nil
+}
+
+func (r *GormMonitorRepository) FindDue(now time.Time) ([]*models.Monitor, error) {
+	var monitors []*models.Monitor
+	err := r.DB.Where("enabled = ? AND next_run_at <= ?", true, now).Find(&monitors).Error
+	return monitors, err
+}
+
+func (r *GormMonitorRepository) Create(monitor *models.Monitor) error {
+	return r.DB.Omit("Collection").Create(monitor).Error
+}
+
+func (r *GormMonitorRepository) Save(monitor *models.Monitor) error {
+	return r.DB.Omit("Collection").Save(monitor).Error
+}
+
+func (r *GormMonitorRepository) UpdateSchedule(monitor *models.Monitor, lastRunAt time.Time) error {
+	return r.DB.Model(monitor).Updates(map[string]interface{}{
+		"last_run_at": lastRunAt,
+		"next_run_at": monitor.NextRunAt,
+	}).Error
+}
+
+func (r *GormMonitorRepository) Delete(monitor *models.Monitor) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("monitor_id = ?", monitor.ID).Delete(&models.MonitorRun{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(monitor).Error
+	})
+}
+
+func (r *GormMonitorRepository) DeleteByCollections(collectionIDs []string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		monitorIDs := tx.Unscoped().Model(&models.Monitor{}).Select("id").Where("collection_id IN ?", collectionIDs)
+		if err := tx.Unscoped().Where("monitor_id IN (?)", monitorIDs).Delete(&models.MonitorRun{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("collection_id IN ?", collectionIDs).Delete(&models.Monitor{}).Error
+	})
+}
+
+func (r *GormMonitorRepository) CreateRun(run *models.MonitorRun) error {
+	return r.DB.Create(run).Error
+}
+
+func (r *GormMonitorRepository) FindRuns(monitorID string, limit int) ([]*models.MonitorRun, error) {
+	var runs []*models.MonitorRun
+	err := r.DB.Where("monitor_id = ?", monitorID).Order("started_at desc").Limit(limit).Find(&runs).Error
+	return runs, err
+}
+
+func (r *GormMonitorRepository) FindRunsSince(monitorID string, since time.Time) ([]*models.MonitorRun, error) {
+	var runs []*models.MonitorRun
+	err := r.DB.Where("monitor_id = ? AND started_at >= ?", monitorID, since).Order("started_at").Find(&runs).Error
+	return runs, err
+}
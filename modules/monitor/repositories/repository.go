@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/jeksilaen/api-builder/modules/monitor/models"
+)
+
+// MonitorRepository stores the monitors of collections and the reports of
+// their runs. Lookups of a missing record fail with db.ErrNotFound.
+type MonitorRepository interface {
+	// FindAccessibleByCollectionID returns the monitors of a collection in
+	// a workspace of userID
+	FindAccessibleByCollectionID(userID string, collectionID string) ([]*models.Monitor, error)
+	// FindAccessible returns the monitor if its collection is in a
+	// workspace of userID
+	FindAccessible(userID string, monitorID string) (*models.Monitor, error)
+	// FindDue returns the enabled monitors whose next run is at or before now
+	FindDue(now time.Time) ([]*models.Monitor, error)
+	Create(monitor *models.Monitor) error
+	Save(monitor *models.Monitor) error
+	// UpdateSchedule stores the last run and the NextRunAt of the monitor
+	UpdateSchedule(monitor *models.Monitor, lastRunAt time.Time) error
+	// Delete deletes the monitor with its runs
+	Delete(monitor *models.Monitor) error
+	// DeleteByCollections deletes for good the monitors of the collections,
+	// soft deleted ones too, with their runs
+	DeleteByCollections(collectionIDs []string) error
+	CreateRun(run *models.MonitorRun) error
+	// FindRuns returns the last runs of a monitor, newest first
+	FindRuns(monitorID string, limit int) ([]*models.MonitorRun, error)
+	// FindRunsSince returns the runs of a monitor started since a time,
+	// oldest first
+	FindRunsSince(monitorID string, since time.Time) ([]*models.MonitorRun, error)
+}
@@ -0,0 +1,226 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jeksilaen/api-builder/db"
+	collectionUsecases "github.com/jeksilaen/api-builder/modules/collection/usecases"
+	environmentUsecases "github.com/jeksilaen/api-builder/modules/environment/usecases"
+	"github.com/jeksilaen/api-builder/modules/monitor/helpers"
+	"github.com/jeksilaen/api-builder/modules/monitor/models"
+	"github.com/jeksilaen/api-builder/modules/monitor/repositories"
+	requestUsecases "github.com/jeksilaen/api-builder/modules/request/usecases"
+	webhookModels "github.com/jeksilaen/api-builder/modules/webhook/models"
+	webhookUsecases "github.com/jeksilaen/api-builder/modules/webhook/usecases"
+)
+
+type MonitorCommandUsecase struct {
+	Repo         repositories.MonitorRepository
+	Collections  *collectionUsecases.CollectionCommandUsecase
+	Environments *environmentUsecases.EnvironmentCommandUsecase
+	Requests     *requestUsecases.RequestCommandUsecase
+	Webhooks     *webhookUsecases.WebhookDispatcher
+}
+
+func NewMonitorCommandUsecase(repo repositories.MonitorRepository, collections *collectionUsecases.CollectionCommandUsecase, environments *environmentUsecases.EnvironmentCommandUsecase, requests *requestUsecases.RequestCommandUsecase, webhooks *webhookUsecases.WebhookDispatcher) *MonitorCommandUsecase {
+	return &MonitorCommandUsecase{
+		Repo:         repo,
+		Collections:  collections,
+		Environments: environments,
+		Requests:     requests,
+		Webhooks:     webhooks,
+	}
+}
+
+func (uc *MonitorCommandUsecase) GetMonitorsByCollectionID(userID string, collectionID string) ([]*models.Monitor, error) {
+	monitors, err := uc.Repo.FindAccessibleByCollectionID(userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return monitors, nil
+}
+
+func (uc *MonitorCommandUsecase) GetMonitorByID(userID string, monitorID string) (*models.Monitor, error) {
+	monitor, err := uc.Repo.FindAccessible(userID, monitorID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, errors.New("Monitor not found")
+		}
+		return nil, err
+	}
+
+	return monitor, nil
+}
+
+// CreateMonitor validates the cron expression and schedules the first run.
+func (uc *MonitorCommandUsecase) CreateMonitor(ctx context.Context, userID string, monitor *models.Monitor) (*models.Monitor, error) {
+	if err := uc.checkOwnership(userID, monitor); err != nil {
+		return nil, err
+	}
+
+	if err := scheduleNextRun(monitor, time.Now()); err != nil {
+		return nil, err
+	}
+
+	err := uc.Repo.Create(monitor)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidReference) {
+			return nil, errors.New("Collection Id Not Found")
+		}
+
+		slog.ErrorContext(ctx, "Error creating monitor", "error", err)
+		return nil, err
+	}
+
+	return monitor, nil
+}
+
+// UpdateMonitor validates the cron expression and reschedules the next run.
+func (uc *MonitorCommandUsecase) UpdateMonitor(userID string, monitor *models.Monitor) (*models.Monitor, error) {
+	if err := uc.checkOwnership(userID, monitor); err != nil {
+		return nil, err
+	}
+
+	if err := scheduleNextRun(monitor, time.Now()); err != nil {
+		return nil, err
+	}
+
+	err := uc.Repo.Save(monitor)
+	if err != nil {
+		return nil, err
+	}
+	return monitor, nil
+}
+
+func (uc *MonitorCommandUsecase) DeleteMonitor(userID string, monitorID string) error {
+	monitor, err := uc.GetMonitorByID(userID, monitorID)
+	if err != nil {
+		return err
+	}
+
+	// Delete the monitor with its stored reports
+	return uc.Repo.Delete(monitor)
+}
+
+// GetDueMonitors returns the enabled monitors whose next run is due.
+func (uc *MonitorCommandUsecase) GetDueMonitors(now time.Time) ([]*models.Monitor, error) {
+	monitors, err := uc.Repo.FindDue(now)
+	if err != nil {
+		return nil, err
+	}
+
+	return monitors, nil
+}
+
+// AdvanceMonitor moves the next run of a monitor past now, so the scheduler
+// doesn't pick it up again while it is running.
+func (uc *MonitorCommandUsecase) AdvanceMonitor(monitor *models.Monitor, now time.Time) error {
+	if err := scheduleNextRun(monitor, now); err != nil {
+		return err
+	}
+
+	return uc.Repo.UpdateSchedule(monitor, now)
+}
+
+// RunMonitor runs the collection of a monitor with its environment, whoever
+// created the monitor, and stores the report. Failed runs are sent to the
+// collection webhooks.
+func (uc *MonitorCommandUsecase) RunMonitor(ctx context.Context, monitor *models.Monitor) (*models.MonitorRun, error) {
+	if _, err := uc.Collections.GetCollectionByID(monitor.CollectionID); err != nil {
+		return nil, err
+	}
+
+	variables := map[string]string{}
+	if monitor.EnvironmentID != nil && *monitor.EnvironmentID != "" {
+		environment, err := uc.Environments.GetCollectionEnvironment(monitor.CollectionID, *monitor.EnvironmentID)
+		if err != nil {
+			return nil, err
+		}
+		variables = environment.Values()
+	}
+
+	report, err := uc.Requests.RunCollectionByID(ctx, monitor.CollectionID, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &models.MonitorRun{
+		MonitorID: monitor.ID,
+		StartedAt: report.StartedAt,
+		Duration:  report.Duration,
+		Total:     report.Total,
+		Passed:    report.Passed,
+		Failed:    report.Failed,
+		Success:   report.Failed == 0,
+		Results:   report.Results,
+	}
+
+	if err := uc.Repo.CreateRun(run); err != nil {
+		slog.ErrorContext(ctx, "Error saving monitor run", "error", err)
+		return nil, err
+	}
+
+	if !run.Success {
+		uc.Webhooks.NotifyRunFailure(ctx, webhookModels.EventMonitorFailed, monitor.ID, report)
+	}
+
+	return run, nil
+}
+
+func (uc *MonitorCommandUsecase) GetRunsByMonitorID(userID string, monitorID string, limit int) ([]*models.MonitorRun, error) {
+	if _, err := uc.GetMonitorByID(userID, monitorID); err != nil {
+		return nil, err
+	}
+
+	runs, err := uc.Repo.FindRuns(monitorID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// GetTrend aggregates the runs of a monitor started since the given time.
+func (uc *MonitorCommandUsecase) GetTrend(userID string, monitorID string, since time.Time, bucket string) (models.TrendResponse, error) {
+	if _, err := uc.GetMonitorByID(userID, monitorID); err != nil {
+		return models.TrendResponse{}, err
+	}
+
+	runs, err := uc.Repo.FindRunsSince(monitorID, since)
+	if err != nil {
+		return models.TrendResponse{}, err
+	}
+
+	return helpers.BuildTrend(monitorID, runs, since, bucket), nil
+}
+
+func scheduleNextRun(monitor *models.Monitor, from time.Time) error {
+	next, err := helpers.NextRun(monitor.Cron, from)
+	if err != nil {
+		return errors.New("Invalid cron expression: " + err.Error())
+	}
+
+	monitor.NextRunAt = &next
+	return nil
+}
+
+// checkOwnership makes sure the collection of a monitor belongs to userID and
+// that its environment, if any, is one of that collection.
+func (uc *MonitorCommandUsecase) checkOwnership(userID string, monitor *models.Monitor) error {
+	if _, err := uc.Collections.GetCollectionByIDWithoutPreload(userID, monitor.CollectionID); err != nil {
+		return err
+	}
+
+	if monitor.EnvironmentID == nil || *monitor.EnvironmentID == "" {
+		return nil
+	}
+	environment, err := uc.Environments.GetEnvironmentByID(userID, *monitor.EnvironmentID)
+	if err != nil || environment.CollectionID != monitor.CollectionID {
+		return errors.New("Environment not found")
+	}
+	return nil
+}
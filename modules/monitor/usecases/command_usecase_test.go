@@ -0,0 +1,196 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jeksilaen/api-builder/db/dbtest"
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	collectionRepositories "github.com/jeksilaen/api-builder/modules/collection/repositories"
+	collectionUsecases "github.com/jeksilaen/api-builder/modules/collection/usecases"
+	environmentModels "github.com/jeksilaen/api-builder/modules/environment/models"
+	environmentRepositories "github.com/jeksilaen/api-builder/modules/environment/repositories"
+	environmentUsecases "github.com/jeksilaen/api-builder/modules/environment/usecases"
+	"github.com/jeksilaen/api-builder/modules/monitor/models"
+	"github.com/jeksilaen/api-builder/modules/monitor/repositories"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+	requestRepositories "github.com/jeksilaen/api-builder/modules/request/repositories"
+	requestUsecases "github.com/jeksilaen/api-builder/modules/request/usecases"
+	userModels "github.com/jeksilaen/api-builder/modules/user/models"
+	webhookHelpers "github.com/jeksilaen/api-builder/modules/webhook/helpers"
+	webhookModels "github.com/jeksilaen/api-builder/modules/webhook/models"
+	webhookRepositories "github.com/jeksilaen/api-builder/modules/webhook/repositories"
+	webhookUsecases "github.com/jeksilaen/api-builder/modules/webhook/usecases"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+)
+
+// fixture is a collection of bob's team workspace, which alice can only
+// read, with an environment pointing its requests at a local API. Carol is
+// in no workspace.
+type fixture struct {
+	uc                *MonitorCommandUsecase
+	dispatcher        *webhookUsecases.WebhookDispatcher
+	alice, bob, carol *userModels.User
+	collection        *collectionModels.Collection
+	other             *collectionModels.Collection
+	environment       *environmentModels.Environment
+
+	mu     sync.Mutex
+	alerts []webhookModels.WebhookPayload
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	f := &fixture{}
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ok" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(api.Close)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhookModels.WebhookPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		f.mu.Lock()
+		f.alerts = append(f.alerts, payload)
+		f.mu.Unlock()
+	}))
+	t.Cleanup(receiver.Close)
+
+	gormDB := dbtest.Open(t)
+	create := func(record interface{}) {
+		if err := gormDB.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	user := func(name string) *userModels.User {
+		user := &userModels.User{Email: name + "@example.com", Username: name, Password: "hash"}
+		create(user)
+		return user
+	}
+	f.alice, f.bob, f.carol = user("alice"), user("bob"), user("carol")
+
+	team := &workspaceModels.Workspace{Name: "Team", OwnerID: f.bob.ID}
+	create(team)
+	create(&workspaceModels.WorkspaceMember{WorkspaceID: team.ID, UserID: f.bob.ID, Role: workspaceModels.RoleOwner})
+	create(&workspaceModels.WorkspaceMember{WorkspaceID: team.ID, UserID: f.alice.ID, Role: workspaceModels.RoleViewer})
+
+	f.collection = &collectionModels.Collection{UserID: f.bob.ID, WorkspaceID: team.ID, Name: "Orders"}
+	f.other = &collectionModels.Collection{UserID: f.bob.ID, WorkspaceID: team.ID, Name: "Users"}
+	create(f.collection)
+	create(f.other)
+	for _, path := range []string{"/ok", "/fail"} {
+		create(&requestModels.Request{ID: uuid.New().String(), CollectionID: f.collection.ID, Name: path, Method: "GET", URL: "{{baseUrl}}" + path})
+	}
+	f.environment = &environmentModels.Environment{CollectionID: f.collection.ID, Name: "Local", Variables: requestModels.JSONMap{"baseUrl": api.URL}}
+	create(f.environment)
+	create(&webhookModels.Webhook{CollectionID: f.collection.ID, Name: "Alerts", URL: receiver.URL, Secret: "whsec_test", Enabled: true})
+
+	collections := collectionUsecases.NewCollectionCommandUsecase(collectionRepositories.NewGormCollectionRepository(gormDB))
+	environments := environmentUsecases.NewEnvironmentCommandUsecase(environmentRepositories.NewGormEnvironmentRepository(gormDB), collections)
+	requests := requestUsecases.NewRequestCommandUsecase(requestRepositories.NewGormRequestRepository(gormDB), collections, api.Client(), 0)
+	f.dispatcher = webhookUsecases.NewWebhookDispatcher(webhookRepositories.NewGormWebhookRepository(gormDB), webhookHelpers.NewClient(time.Second, true))
+	f.uc = NewMonitorCommandUsecase(repositories.NewGormMonitorRepository(gormDB), collections, environments, requests, f.dispatcher)
+	return f
+}
+
+func (f *fixture) createMonitor(t *testing.T) *models.Monitor {
+	t.Helper()
+	monitor, err := f.uc.CreateMonitor(context.Background(), f.bob.ID, &models.Monitor{
+		CollectionID:  f.collection.ID,
+		EnvironmentID: &f.environment.ID,
+		Name:          "Every 5 minutes",
+		Cron:          "*/5 * * * *",
+		Enabled:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return monitor
+}
+
+func TestCreateMonitor(t *testing.T) {
+	f := newFixture(t)
+	now := time.Now()
+
+	monitor := f.createMonitor(t)
+	if monitor.NextRunAt == nil || !monitor.NextRunAt.After(now) || monitor.NextRunAt.After(now.Add(5*time.Minute)) {
+		t.Errorf("next run at %v, want within 5 minutes", monitor.NextRunAt)
+	}
+
+	tests := []struct {
+		name    string
+		userID  string
+		monitor models.Monitor
+	}{
+		{"invalid cron", f.bob.ID, models.Monitor{CollectionID: f.collection.ID, Cron: "every minute"}},
+		{"collection of another workspace", f.carol.ID, models.Monitor{CollectionID: f.collection.ID, Cron: "@hourly"}},
+		{"environment of another collection", f.bob.ID, models.Monitor{CollectionID: f.other.ID, EnvironmentID: &f.environment.ID, Cron: "@hourly"}},
+	}
+	for _, test := range tests {
+		if _, err := f.uc.CreateMonitor(context.Background(), test.userID, &test.monitor); err == nil {
+			t.Errorf("%s: monitor created", test.name)
+		}
+	}
+}
+
+func TestRunMonitor(t *testing.T) {
+	f := newFixture(t)
+	monitor := f.createMonitor(t)
+
+	run, err := f.uc.RunMonitor(context.Background(), monitor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Total != 2 || run.Passed != 1 || run.Failed != 1 || run.Success {
+		t.Errorf("run = %+v, want /ok passed and /fail failed", run)
+	}
+
+	// The failed run is sent to the webhooks of the collection
+	f.dispatcher.Wait()
+	f.mu.Lock()
+	if len(f.alerts) != 1 || f.alerts[0].Event != webhookModels.EventMonitorFailed || f.alerts[0].MonitorID != monitor.ID {
+		t.Errorf("alerts = %+v, want one monitor.failed", f.alerts)
+	}
+	f.mu.Unlock()
+
+	// Members of the workspace read the runs, others don't
+	runs, err := f.uc.GetRunsByMonitorID(f.alice.ID, monitor.ID, 10)
+	if err != nil || len(runs) != 1 || runs[0].ID != run.ID {
+		t.Errorf("runs = %v, %v, want the run", runs, err)
+	}
+	if _, err := f.uc.GetRunsByMonitorID(f.carol.ID, monitor.ID, 10); err == nil {
+		t.Error("carol read the runs")
+	}
+
+	trend, err := f.uc.GetTrend(f.alice.ID, monitor.ID, time.Now().Add(-time.Hour), "hour")
+	if err != nil || trend.Runs != 1 || trend.SuccessRate != 0 {
+		t.Errorf("trend = %+v, %v, want one failed run", trend, err)
+	}
+}
+
+func TestDueMonitors(t *testing.T) {
+	f := newFixture(t)
+	monitor := f.createMonitor(t)
+	now := monitor.NextRunAt.Add(time.Second)
+
+	due, err := f.uc.GetDueMonitors(now)
+	if err != nil || len(due) != 1 || due[0].ID != monitor.ID {
+		t.Fatalf("due = %v, %v, want the monitor", due, err)
+	}
+
+	if err := f.uc.AdvanceMonitor(due[0], now); err != nil {
+		t.Fatal(err)
+	}
+	if due, err := f.uc.GetDueMonitors(now); err != nil || len(due) != 0 {
+		t.Errorf("due after advancing = %v, %v, want none", due, err)
+	}
+}
@@ -0,0 +1,126 @@
+package usecases
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jeksilaen/api-builder/db"
+	"github.com/jeksilaen/api-builder/logging"
+	"github.com/jeksilaen/api-builder/modules/monitor/models"
+	"gorm.io/gorm"
+)
+
+// monitorLockKey is the leader lock held by the instance that runs
+// monitors. Only the holder schedules runs, so several api-builder
+// instances can share one database.
+const monitorLockKey int64 = 0x6d6f6e69746f72
+
+// MonitorScheduler checks for due monitors on every tick while it holds the
+// leader lock.
+type MonitorScheduler struct {
+	Monitors *MonitorCommandUsecase
+	Lock     db.LeaderLock
+	Interval time.Duration
+	// Tasks also run on every tick of the leader, for the housekeeping of
+	// the other modules such as pruning old records
+	Tasks []func(ctx context.Context) error
+
+	leading bool
+	running sync.WaitGroup
+}
+
+func NewMonitorScheduler(monitors *MonitorCommandUsecase, gormDB *gorm.DB) *MonitorScheduler {
+	return &MonitorScheduler{
+		Monitors: monitors,
+		Lock:     db.NewLeaderLock(gormDB, monitorLockKey),
+		Interval: 30 * time.Second,
+	}
+}
+
+// Start runs the scheduler loop until ctx is cancelled.
+func (s *MonitorScheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.Interval)
+		defer ticker.Stop()
+		defer s.releaseLeadership()
+
+		for {
+			if s.acquireLeadership(ctx) {
+				s.runDueMonitors(ctx)
+				s.runTasks(ctx)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until the monitor runs already started have finished.
+func (s *MonitorScheduler) Wait() {
+	s.running.Wait()
+}
+
+// acquireLeadership reports whether this instance holds the leader lock.
+func (s *MonitorScheduler) acquireLeadership(ctx context.Context) bool {
+	leading := s.Lock.Acquire(ctx)
+	if leading && !s.leading {
+		slog.Info("Monitor scheduler is now the leader")
+	}
+	s.leading = leading
+	return leading
+}
+
+func (s *MonitorScheduler) releaseLeadership() {
+	s.Lock.Release()
+	s.leading = false
+}
+
+func (s *MonitorScheduler) runTasks(ctx context.Context) {
+	for _, task := range s.Tasks {
+		if err := task(ctx); err != nil {
+			slog.Error("Scheduled task failed", "error", err)
+		}
+	}
+}
+
+func (s *MonitorScheduler) runDueMonitors(ctx context.Context) {
+	monitorUsecase := s.Monitors
+
+	now := time.Now()
+	monitors, err := monitorUsecase.GetDueMonitors(now)
+	if err != nil {
+		slog.Error("Monitor scheduler failed to load due monitors", "error", err)
+		return
+	}
+
+	for _, monitor := range monitors {
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Move the schedule forward before running so a slow run isn't started twice
+		if err := monitorUsecase.AdvanceMonitor(monitor, now); err != nil {
+			slog.Error("Monitor scheduler failed to advance monitor", "monitor_id", monitor.ID, "error", err)
+			continue
+		}
+
+		// Each run gets its own request ID and isn't cancelled on shutdown
+		runCtx := logging.WithRequestID(context.Background(), uuid.New().String())
+
+		s.running.Add(1)
+		go func(monitor *models.Monitor) {
+			defer s.running.Done()
+
+			if _, err := monitorUsecase.RunMonitor(runCtx, monitor); err != nil {
+				slog.ErrorContext(runCtx, "Monitor run failed", "monitor_id", monitor.ID, "error", err)
+			}
+		}(monitor)
+	}
+}
@@ -1,26 +1,75 @@
 package handlers
 
 import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	// "github.com/go-playground/validator/v10"
+	"github.com/go-playground/validator/v10"
 	"github.com/jeksilaen/api-builder/middlewares"
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	collectionUsecases "github.com/jeksilaen/api-builder/modules/collection/usecases"
+	environmentUsecases "github.com/jeksilaen/api-builder/modules/environment/usecases"
 	"github.com/jeksilaen/api-builder/modules/request/helpers"	
 	"github.com/jeksilaen/api-builder/modules/request/models"	
 	"github.com/jeksilaen/api-builder/modules/request/usecases"
+	webhookModels "github.com/jeksilaen/api-builder/modules/webhook/models"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+	workspaceUsecases "github.com/jeksilaen/api-builder/modules/workspace/usecases"
+	webhookUsecases "github.com/jeksilaen/api-builder/modules/webhook/usecases"
 )
 
-func InitRequestHttpHandler(router *gin.Engine) {	
-	router.GET("/users/v1/request/:request_id", middlewares.VerifyToken, GetRequestById)
-	router.GET("/users/v1/request_by_collection/:collection_id", middlewares.VerifyToken, GetRequestByCollection)
-	router.POST("/users/v1/request", middlewares.VerifyToken,CreateRequest)
-	router.PUT("/users/v1/request/:request_id", middlewares.VerifyToken,UpdateRequest)
-	router.DELETE("/users/v1/request/:request_id", middlewares.VerifyToken,DeleteRequest)
+// Limits on the uploaded archives, so a small zip can't unpack into more
+// than the server is willing to hold.
+const (
+	maxImportFileSize  = 10 << 20
+	maxImportTotalSize = 50 << 20
+	maxImportFiles     = 1000
+)
+
+// RequestHttpHandler serves the request routes with the usecases it is given.
+type RequestHttpHandler struct {
+	Requests     *usecases.RequestCommandUsecase
+	Workspaces   *workspaceUsecases.WorkspaceCommandUsecase
+	Environments *environmentUsecases.EnvironmentCommandUsecase
+	Webhooks     *webhookUsecases.WebhookDispatcher
+}
+
+func NewRequestHttpHandler(requests *usecases.RequestCommandUsecase, workspaces *workspaceUsecases.WorkspaceCommandUsecase, environments *environmentUsecases.EnvironmentCommandUsecase, webhooks *webhookUsecases.WebhookDispatcher) *RequestHttpHandler {
+	return &RequestHttpHandler{
+		Requests:     requests,
+		Workspaces:   workspaces,
+		Environments: environments,
+		Webhooks:     webhooks,
+	}
+}
+
+func InitRequestHttpHandler(router *gin.Engine, auth *middlewares.Auth, h *RequestHttpHandler) {	
+	ofRequest := auth.WorkspaceOfRecord("requests", "request_id")
+	ofCollection := auth.WorkspaceOfCollection("collection_id")
+
+	router.GET("/users/v1/request/:request_id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermRead, ofRequest), h.GetRequestById)
+	router.GET("/users/v1/request_by_collection/:collection_id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermRead, ofCollection), h.GetRequestByCollection)
+	router.POST("/users/v1/request", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, auth.WorkspaceOfBodyCollection("CollectionID")), h.CreateRequest)
+	router.POST("/users/v1/request/import/curl", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, auth.WorkspaceOfBodyCollection("collection_id")), h.ImportCurlRequest)
+	router.GET("/users/v1/request/:request_id/export/curl", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermRead, ofRequest), h.ExportCurlRequest)
+	router.PUT("/users/v1/request/:request_id/mock", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, ofRequest), h.UpdateRequestMock)
+	router.GET("/users/v1/request/:request_id/code", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermRead, ofRequest), h.GetRequestCodeSnippet)
+	router.POST("/users/v1/request/import/har", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, auth.WorkspaceFromBody("workspace_id")), h.ImportHARCollection)
+	router.POST("/users/v1/request/import/insomnia", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, auth.WorkspaceFromBody("workspace_id")), h.ImportInsomniaCollection)
+	router.POST("/users/v1/request/import/bruno", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, auth.WorkspaceFromForm("workspace_id")), h.ImportBrunoCollection)
+	router.GET("/users/v1/request_by_collection/:collection_id/export/har", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermRead, ofCollection), h.ExportHARCollection)
+	router.POST("/users/v1/request_by_collection/:collection_id/run", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermRun, ofCollection), h.RunCollection)
+	router.PUT("/users/v1/request/:request_id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, ofRequest), h.UpdateRequest)
+	router.DELETE("/users/v1/request/:request_id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, ofRequest), h.DeleteRequest)
 }
 
-func GetRequestById(ctx *gin.Context) {
-	requestUsecase := usecases.NewRequestCommandUsecase()
+func (h *RequestHttpHandler) GetRequestById(ctx *gin.Context) {
 
 	requestID := ctx.Param("request_id")
 
@@ -30,18 +79,22 @@ func GetRequestById(ctx *gin.Context) {
 	}
 
 	// Get the request data from usecase
-	request, err := requestUsecase.GetRequestByRequestID(requestID)
+	request, err := h.Requests.GetRequestByRequestID(middlewares.GetUserID(ctx), requestID)
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Request not found"})
 		return
 	}
 
+	// Hide the credentials from members who can't see secrets
+	if !middlewares.HasPermission(ctx, workspaceModels.PermSecrets) {
+		helpers.RedactRequests([]*models.Request{request})
+	}
+
 	// Return the request data as response
 	ctx.JSON(http.StatusCreated, helpers.ReturnSucessGetResponse([]*models.Request{request}))
 }
 
-func GetRequestByCollection(ctx *gin.Context) {
-	requestUsecase := usecases.NewRequestCommandUsecase()
+func (h *RequestHttpHandler) GetRequestByCollection(ctx *gin.Context) {
 
 	collectionID := ctx.Param("collection_id")
 
@@ -51,19 +104,23 @@ func GetRequestByCollection(ctx *gin.Context) {
 	}
 
 	// Get the request data from usecase
-	request, err := requestUsecase.GetRequestByCollectionID(collectionID)
+	request, err := h.Requests.GetRequestByCollectionID(middlewares.GetUserID(ctx), collectionID)
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Request not found"})
 		return
 	}
 
+	// Hide the credentials from members who can't see secrets
+	if !middlewares.HasPermission(ctx, workspaceModels.PermSecrets) {
+		helpers.RedactRequests(request)
+	}
+
 	// Return the request data as response
 	ctx.JSON(http.StatusCreated, helpers.ReturnSucessGetResponse(request))
 }
 
 
-func CreateRequest(ctx *gin.Context) {
-	requestUsecase := usecases.NewRequestCommandUsecase()
+func (h *RequestHttpHandler) CreateRequest(ctx *gin.Context) {
 	// validate := validator.New()
 
 	// Decode the request JSON data into User object
@@ -81,18 +138,21 @@ func CreateRequest(ctx *gin.Context) {
 	// }
 	
 	// Create the user
-	createdCollection, _ := requestUsecase.CreateRequest(&req)
-	// if err != nil {
-	// 	ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateRequestResponse(err.Error()))
-	// 	return
-	// }
+	createdCollection, err := h.Requests.CreateRequest(ctx.Request.Context(), middlewares.GetUserID(ctx), &req)
+	if errors.Is(err, collectionUsecases.ErrCollectionNotFound) {
+		ctx.JSON(http.StatusNotFound, helpers.ReturnFailedCreateRequestResponse(err.Error()))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateRequestResponse(err.Error()))
+		return
+	}
 
 	ctx.JSON(http.StatusCreated, helpers.ReturnSucessCreateRequestResponse(createdCollection))
 	
 }
 
-func UpdateRequest(ctx *gin.Context) {
-    requestUsecase := usecases.NewRequestCommandUsecase()
+func (h *RequestHttpHandler) UpdateRequest(ctx *gin.Context) {
     // validate := validator.New()
 
     // Get collection ID from path parameter
@@ -119,7 +179,7 @@ func UpdateRequest(ctx *gin.Context) {
     // }
 
     // Get the existing collection data from usecase without preloading the User field
-    existingRequest, err := requestUsecase.GetRequestByIDWithoutPreload(requestID)
+    existingRequest, err := h.Requests.GetRequestByIDWithoutPreload(middlewares.GetUserID(ctx), requestID)
     if err != nil {
         ctx.JSON(http.StatusNotFound, gin.H{"error": "Request not found"})
         return
@@ -129,21 +189,71 @@ func UpdateRequest(ctx *gin.Context) {
     existingRequest.Name = req.Name
 	existingRequest.URL = req.URL
 	existingRequest.Method = req.Method
+	existingRequest.Headers = helpers.KeepSecretHeaders(existingRequest.Headers, req.Headers, middlewares.HasPermission(ctx, workspaceModels.PermSecrets))
 	existingRequest.Payload = req.Payload
+	existingRequest.RawBody = req.RawBody
 	existingRequest.Response = req.Response
 
     // Save the updated request
-    updatedRequest, err := requestUsecase.UpdateRequest(existingRequest)
+    updatedRequest, err := h.Requests.UpdateRequest(ctx.Request.Context(), existingRequest)
     if err != nil {
         ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateRequestResponse(err.Error()))
         return
     }
 
+    // Hide the credentials from members who can't see secrets
+    if !middlewares.HasPermission(ctx, workspaceModels.PermSecrets) {
+        helpers.RedactRequests([]*models.Request{updatedRequest})
+    }
+
     ctx.JSON(http.StatusOK, helpers.ReturnSucessUpdateRequestResponse(updatedRequest))
 }
 
-func DeleteRequest(ctx *gin.Context) {
-	requestUsecase := usecases.NewRequestCommandUsecase()
+func (h *RequestHttpHandler) UpdateRequestMock(ctx *gin.Context) {
+	validate := validator.New()
+
+	requestID := ctx.Param("request_id")
+
+	if requestID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Request ID is required"})
+		return
+	}
+
+	// Decode the request JSON data into MockConfigRequest object
+	var req models.MockConfigRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateRequestResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateRequestResponse(err.Error()))
+		return
+	}
+
+	existingRequest, err := h.Requests.GetRequestByIDWithoutPreload(middlewares.GetUserID(ctx), requestID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Request not found"})
+		return
+	}
+
+	// Update how the mock server replays the stored response
+	existingRequest.MockStatus = req.Status
+	existingRequest.MockHeaders = req.Headers
+	existingRequest.MockDelay = req.Delay
+
+	updatedRequest, err := h.Requests.SaveRequest(existingRequest)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateRequestResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessUpdateRequestResponse(updatedRequest))
+}
+
+func (h *RequestHttpHandler) DeleteRequest(ctx *gin.Context) {
 
 	requestID := ctx.Param("request_id")
 
@@ -153,7 +263,7 @@ func DeleteRequest(ctx *gin.Context) {
 	}
 
 	// Get the request data from usecase
-	request, err := requestUsecase.DeleteRequestByRequestID(requestID)
+	request, err := h.Requests.DeleteRequestByRequestID(middlewares.GetUserID(ctx), requestID)
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Request not found"})
 		return
@@ -161,4 +271,363 @@ func DeleteRequest(ctx *gin.Context) {
 
 	// Return the request data as response
 	ctx.JSON(http.StatusCreated, helpers.ReturnSucessDeleteResponse([]*models.Request{request}))
-}
\ No newline at end of file
+}
+
+func (h *RequestHttpHandler) ImportCurlRequest(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into ImportCurlRequest object
+	var req models.ImportCurlRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedImportRequestResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedImportRequestResponse(err.Error()))
+		return
+	}
+
+	// Parse the curl command into a request
+	request, err := helpers.ParseCurlCommand(req.Command)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedImportRequestResponse(err.Error()))
+		return
+	}
+
+	request.CollectionID = req.CollectionID
+	if req.Name != "" {
+		request.Name = req.Name
+	}
+
+	// Save the request without executing it
+	importedRequest, err := h.Requests.ImportRequest(ctx.Request.Context(), middlewares.GetUserID(ctx), request)
+	if errors.Is(err, collectionUsecases.ErrCollectionNotFound) {
+		ctx.JSON(http.StatusNotFound, helpers.ReturnFailedImportRequestResponse(err.Error()))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedImportRequestResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, helpers.ReturnSucessCreateRequestResponse(importedRequest))
+}
+
+func (h *RequestHttpHandler) ExportCurlRequest(ctx *gin.Context) {
+
+	requestID := ctx.Param("request_id")
+
+	if requestID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Request ID is required"})
+		return
+	}
+
+	// Get the request data from usecase
+	request, err := h.Requests.GetRequestByIDWithoutPreload(middlewares.GetUserID(ctx), requestID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Request not found"})
+		return
+	}
+
+	// Hide the credentials from members who can't see secrets
+	if !middlewares.HasPermission(ctx, workspaceModels.PermSecrets) {
+		helpers.RedactRequests([]*models.Request{request})
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessExportCurlResponse(request))
+}
+
+func (h *RequestHttpHandler) ImportHARCollection(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into ImportHARRequest object
+	var req models.ImportHARRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedImportRequestResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedImportRequestResponse(err.Error()))
+		return
+	}
+
+	if len(req.HAR.Log.Entries) == 0 {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedImportRequestResponse("HAR file has no entries"))
+		return
+	}
+
+	// Map every HAR entry to an imported request
+	imported, err := helpers.ParseHAR(req.HAR)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedImportRequestResponse(err.Error()))
+		return
+	}
+	imported.Name = req.Name
+
+	h.importCollection(ctx, req.WorkspaceID, imported)
+}
+
+func (h *RequestHttpHandler) ImportInsomniaCollection(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into ImportInsomniaRequest object
+	var req models.ImportInsomniaRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedImportRequestResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedImportRequestResponse(err.Error()))
+		return
+	}
+
+	// Map the Insomnia resources to imported requests
+	imported, err := helpers.ParseInsomniaExport(req.Insomnia)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedImportRequestResponse(err.Error()))
+		return
+	}
+	if req.Name != "" {
+		imported.Name = req.Name
+	}
+
+	h.importCollection(ctx, req.WorkspaceID, imported)
+}
+
+func (h *RequestHttpHandler) ImportBrunoCollection(ctx *gin.Context) {
+
+	// Read the zipped Bruno collection folder
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedImportRequestResponse("file is required: "+err.Error()))
+		return
+	}
+
+	files, err := readZipFiles(fileHeader)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedImportRequestResponse(err.Error()))
+		return
+	}
+
+	// Map the .bru files to imported requests
+	imported, err := helpers.ParseBrunoCollection(files)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedImportRequestResponse(err.Error()))
+		return
+	}
+	if name := ctx.PostForm("name"); name != "" {
+		imported.Name = name
+	}
+
+	h.importCollection(ctx, ctx.PostForm("workspace_id"), imported)
+}
+
+// importCollection saves an imported collection in a workspace of the
+// logged in user, their personal one when workspaceID is empty, and writes
+// the response shared by every collection importer.
+func (h *RequestHttpHandler) importCollection(ctx *gin.Context, workspaceID string, imported *helpers.ImportedCollection) {
+	if imported.Name == "" {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedImportRequestResponse("collection name is required"))
+		return
+	}
+
+	userID := middlewares.GetUserID(ctx)
+	if workspaceID == "" {
+		workspace, err := h.Workspaces.EnsurePersonalWorkspace(ctx.Request.Context(), userID)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedImportRequestResponse(err.Error()))
+			return
+		}
+		workspaceID = workspace.ID
+	}
+
+	requests := imported.ToRequests()
+
+	// Create the collection together with its requests
+	collection := &collectionModels.Collection{
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		Name:        imported.Name,
+	}
+	importedCollection, err := h.Requests.ImportCollection(ctx.Request.Context(), userID, collection, requests)
+	if errors.Is(err, workspaceUsecases.ErrWorkspaceNotFound) {
+		ctx.JSON(http.StatusNotFound, helpers.ReturnFailedImportRequestResponse(err.Error()))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedImportRequestResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, helpers.ReturnSucessImportCollectionResponse(importedCollection, requests))
+}
+
+// readZipFiles returns the regular files of an uploaded zip archive keyed by
+// their slash separated path.
+func readZipFiles(fileHeader *multipart.FileHeader) (map[string]string, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	archive, err := zip.NewReader(file, fileHeader.Size)
+	if err != nil {
+		return nil, fmt.Errorf("file must be a zip archive: %v", err)
+	}
+
+	if len(archive.File) > maxImportFiles {
+		return nil, fmt.Errorf("archive has more than %d entries", maxImportFiles)
+	}
+
+	files := make(map[string]string)
+	var total int64
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+
+		// The sizes in the headers can lie, so count what is actually read
+		reader, err := entry.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(io.LimitReader(reader, maxImportFileSize+1))
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		if len(content) > maxImportFileSize {
+			return nil, fmt.Errorf("%s is larger than %d MiB", entry.Name, maxImportFileSize>>20)
+		}
+		total += int64(len(content))
+		if total > maxImportTotalSize {
+			return nil, fmt.Errorf("archive unpacks to more than %d MiB", maxImportTotalSize>>20)
+		}
+
+		files[entry.Name] = string(content)
+	}
+
+	return files, nil
+}
+
+func (h *RequestHttpHandler) ExportHARCollection(ctx *gin.Context) {
+
+	collectionID := ctx.Param("collection_id")
+
+	if collectionID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Collection ID is required"})
+		return
+	}
+
+	// Get the request data from usecase
+	requests, err := h.Requests.GetRequestByCollectionID(middlewares.GetUserID(ctx), collectionID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Request not found"})
+		return
+	}
+
+	// Hide the credentials from members who can't see secrets
+	if !middlewares.HasPermission(ctx, workspaceModels.PermSecrets) {
+		helpers.RedactRequests(requests)
+	}
+
+	ctx.JSON(http.StatusOK, helpers.BuildHAR(requests))
+}
+
+func (h *RequestHttpHandler) RunCollection(ctx *gin.Context) {
+
+	collectionID := ctx.Param("collection_id")
+
+	if collectionID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Collection ID is required"})
+		return
+	}
+
+	// The body is optional, a run without one uses no variables
+	var req models.RunCollectionRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.BindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	variables := map[string]string{}
+	if req.EnvironmentID != "" {
+		environment, err := h.Environments.GetEnvironmentByID(middlewares.GetUserID(ctx), req.EnvironmentID)
+		if err != nil || environment.CollectionID != collectionID {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Environment not found"})
+			return
+		}
+		variables = environment.Values()
+	}
+	for name, value := range req.Variables {
+		variables[name] = value
+	}
+
+	// Run every request of the collection
+	report, err := h.Requests.RunCollection(ctx.Request.Context(), middlewares.GetUserID(ctx), collectionID, variables)
+	if errors.Is(err, collectionUsecases.ErrCollectionNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx.Request.Context(), "Error running collection", "collection_id", collectionID, "error", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Could not run the collection"})
+		return
+	}
+
+	// Alert the collection webhooks about failing requests
+	if report.Failed > 0 {
+		h.Webhooks.NotifyRunFailure(ctx.Request.Context(), webhookModels.EventRunFailed, "", report)
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessRunCollectionResponse(report))
+}
+
+func (h *RequestHttpHandler) GetRequestCodeSnippet(ctx *gin.Context) {
+
+	requestID := ctx.Param("request_id")
+
+	if requestID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Request ID is required"})
+		return
+	}
+
+	lang := ctx.Query("lang")
+	if lang == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "lang is required", "languages": helpers.SnippetLanguages()})
+		return
+	}
+
+	// Get the request data from usecase
+	request, err := h.Requests.GetRequestByIDWithoutPreload(middlewares.GetUserID(ctx), requestID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Request not found"})
+		return
+	}
+
+	// Hide the credentials from members who can't see secrets
+	if !middlewares.HasPermission(ctx, workspaceModels.PermSecrets) {
+		helpers.RedactRequests([]*models.Request{request})
+	}
+
+	// Render the request as client code
+	code, err := helpers.GenerateCodeSnippet(request, lang)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "languages": helpers.SnippetLanguages()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessCodeSnippetResponse(request, lang, code))
+}
@@ -0,0 +1,252 @@
+package helpers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// brunoMethods are the block names Bruno uses for the request line.
+var brunoMethods = []string{"get", "post", "put", "patch", "delete", "options", "head"}
+
+// brunoBodyBlocks maps the body mode of a request to the block holding it.
+var brunoBodyBlocks = map[string]string{
+	"json":           "body:json",
+	"text":           "body:text",
+	"xml":            "body:xml",
+	"formUrlEncoded": "body:form-urlencoded",
+	"multipartForm":  "body:multipart-form",
+	"graphql":        "body:graphql",
+}
+
+// brunoBlock is one top-level "name { ... }" section of a .bru file.
+type brunoBlock struct {
+	lines []string
+}
+
+// dict reads the block as "key: value" lines, leaving out disabled (~) ones.
+func (block brunoBlock) dict() []ImportedField {
+	var fields []ImportedField
+	for _, line := range block.lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "~") {
+			continue
+		}
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		fields = append(fields, ImportedField{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+	}
+	return fields
+}
+
+func (block brunoBlock) get(name string) string {
+	for _, field := range block.dict() {
+		if field.Name == name {
+			return field.Value
+		}
+	}
+	return ""
+}
+
+// text reads the block as free text, removing Bruno's two space indent.
+func (block brunoBlock) text() string {
+	lines := make([]string, len(block.lines))
+	for i, line := range block.lines {
+		lines[i] = strings.TrimPrefix(line, "  ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// ParseBrunoCollection reads a Bruno collection folder given as a map of
+// slash separated paths to file contents. Requests are ordered by folder and
+// then by their meta seq; environments and folder settings are skipped.
+func ParseBrunoCollection(files map[string]string) (*ImportedCollection, error) {
+	collection := &ImportedCollection{}
+
+	// Paths may be nested under the folder name when a directory was zipped
+	root := ""
+	for name, content := range files {
+		if path.Base(name) != "bruno.json" {
+			continue
+		}
+		if root == "" || len(path.Dir(name)) < len(root) {
+			root = path.Dir(name)
+			var config struct {
+				Name string `json:"name"`
+			}
+			if err := json.Unmarshal([]byte(content), &config); err != nil {
+				return nil, fmt.Errorf("invalid bruno.json: %v", err)
+			}
+			collection.Name = config.Name
+		}
+	}
+
+	type brunoRequest struct {
+		folder  string
+		seq     int
+		request ImportedRequest
+	}
+	var requests []brunoRequest
+
+	for name, content := range files {
+		if path.Ext(name) != ".bru" {
+			continue
+		}
+
+		relative := strings.TrimPrefix(name, root+"/")
+		base := path.Base(relative)
+		if strings.HasPrefix(relative, "environments/") || base == "collection.bru" || base == "folder.bru" {
+			continue
+		}
+
+		blocks, err := parseBruFile(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", relative, err)
+		}
+
+		imported, seq, ok := brunoRequestFromBlocks(blocks)
+		if !ok {
+			continue
+		}
+		if imported.Name == "" {
+			imported.Name = strings.TrimSuffix(base, ".bru")
+		}
+
+		folder := path.Dir(relative)
+		if folder != "." {
+			imported.Name = strings.ReplaceAll(folder, "/", " / ") + " / " + imported.Name
+		}
+
+		requests = append(requests, brunoRequest{folder: folder, seq: seq, request: imported})
+	}
+
+	if len(requests) == 0 {
+		return nil, errors.New("Bruno collection has no requests")
+	}
+
+	sort.SliceStable(requests, func(i, j int) bool {
+		if requests[i].folder != requests[j].folder {
+			return requests[i].folder < requests[j].folder
+		}
+		if requests[i].seq != requests[j].seq {
+			return requests[i].seq < requests[j].seq
+		}
+		return requests[i].request.Name < requests[j].request.Name
+	})
+
+	for _, request := range requests {
+		collection.Requests = append(collection.Requests, request.request)
+	}
+
+	return collection, nil
+}
+
+// brunoRequestFromBlocks maps the blocks of a .bru file to a request. It
+// reports false for files that hold no HTTP request.
+func brunoRequestFromBlocks(blocks map[string]brunoBlock) (ImportedRequest, int, bool) {
+	imported := ImportedRequest{}
+
+	meta := blocks["meta"]
+	imported.Name = meta.get("name")
+	seq, _ := strconv.Atoi(meta.get("seq"))
+
+	var methodBlock brunoBlock
+	found := false
+	for _, method := range brunoMethods {
+		if block, ok := blocks[method]; ok {
+			imported.Method = strings.ToUpper(method)
+			methodBlock = block
+			found = true
+			break
+		}
+	}
+	if !found {
+		return imported, 0, false
+	}
+
+	imported.URL = methodBlock.get("url")
+	if !strings.Contains(imported.URL, "?") {
+		imported.Query = blocks["params:query"].dict()
+	}
+	imported.Headers = blocks["headers"].dict()
+
+	switch methodBlock.get("auth") {
+	case "bearer":
+		imported.BearerToken = blocks["auth:bearer"].get("token")
+	case "basic":
+		basic := blocks["auth:basic"]
+		imported.BasicAuth = &ImportedBasicAuth{Username: basic.get("username"), Password: basic.get("password")}
+	}
+
+	switch mode := methodBlock.get("body"); mode {
+	case "formUrlEncoded":
+		imported.Form = blocks[brunoBodyBlocks[mode]].dict()
+		imported.ContentType = "application/x-www-form-urlencoded"
+	case "multipartForm":
+		imported.Form = blocks[brunoBodyBlocks[mode]].dict()
+		imported.ContentType = "multipart/form-data"
+	case "json":
+		imported.Body = blocks[brunoBodyBlocks[mode]].text()
+		imported.ContentType = "application/json"
+	case "xml":
+		imported.Body = blocks[brunoBodyBlocks[mode]].text()
+		imported.ContentType = "application/xml"
+	case "text":
+		imported.Body = blocks[brunoBodyBlocks[mode]].text()
+		imported.ContentType = "text/plain"
+	case "graphql":
+		query := blocks["body:graphql"].text()
+		variables := blocks["body:graphql:vars"].text()
+		body := map[string]interface{}{"query": query}
+		if variables != "" {
+			body["variables"] = json.RawMessage(variables)
+		}
+		if bodyJSON, err := json.Marshal(body); err == nil {
+			imported.Body = string(bodyJSON)
+		}
+		imported.ContentType = "application/json"
+	}
+
+	return imported, seq, true
+}
+
+// parseBruFile splits a .bru file into its top-level blocks. A block opens
+// with "name {" at the start of a line and closes with a lone "}".
+func parseBruFile(content string) (map[string]brunoBlock, error) {
+	blocks := make(map[string]brunoBlock)
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		if line == "" {
+			continue
+		}
+		if !strings.HasSuffix(line, "{") || strings.HasPrefix(line, " ") {
+			return nil, fmt.Errorf("unexpected line %d: %q", i+1, line)
+		}
+
+		name := strings.TrimSpace(strings.TrimSuffix(line, "{"))
+		block := brunoBlock{}
+		closed := false
+		for i++; i < len(lines); i++ {
+			if strings.TrimRight(lines[i], " \t") == "}" {
+				closed = true
+				break
+			}
+			block.lines = append(block.lines, lines[i])
+		}
+		if !closed {
+			return nil, fmt.Errorf("block %q is not closed", name)
+		}
+
+		blocks[name] = block
+	}
+
+	return blocks, nil
+}
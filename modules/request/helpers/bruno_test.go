@@ -0,0 +1,128 @@
+package helpers
+
+import (
+	"testing"
+)
+
+func TestParseBrunoCollection(t *testing.T) {
+	files := map[string]string{
+		"shop/bruno.json": `{"version":"1","name":"Shop","type":"collection"}`,
+		"shop/collection.bru": `auth {
+  mode: none
+}
+`,
+		"shop/environments/local.bru": `vars {
+  host: http://localhost
+}
+`,
+		"shop/users/Create user.bru": `meta {
+  name: Create user
+  type: http
+  seq: 2
+}
+
+post {
+  url: https://api.example.com/users
+  body: json
+  auth: bearer
+}
+
+headers {
+  X-Trace: 1
+  ~X-Debug: 1
+}
+
+auth:bearer {
+  token: abc123
+}
+
+body:json {
+  {
+    "name": "alice"
+  }
+}
+`,
+		"shop/users/List users.bru": `meta {
+  name: List users
+  seq: 1
+}
+
+get {
+  url: https://api.example.com/users
+  auth: basic
+}
+
+params:query {
+  page: 2
+}
+
+auth:basic {
+  username: admin
+  password: secret
+}
+`,
+	}
+
+	collection, err := ParseBrunoCollection(files)
+	if err != nil {
+		t.Fatalf("ParseBrunoCollection: %v", err)
+	}
+	if collection.Name != "Shop" {
+		t.Errorf("Name = %q, want Shop", collection.Name)
+	}
+
+	requests := collection.ToRequests()
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+
+	list := requests[0]
+	if list.Name != "users / List users" {
+		t.Errorf("Name = %q", list.Name)
+	}
+	if list.URL != "https://api.example.com/users?page=2" {
+		t.Errorf("URL = %q", list.URL)
+	}
+	if list.Headers["Authorization"] != "Basic YWRtaW46c2VjcmV0" {
+		t.Errorf("Authorization = %v", list.Headers["Authorization"])
+	}
+
+	create := requests[1]
+	if create.Method != "POST" {
+		t.Errorf("Method = %q, want POST", create.Method)
+	}
+	if create.BearerToken != "abc123" {
+		t.Errorf("BearerToken = %q", create.BearerToken)
+	}
+	if create.Headers["X-Trace"] != "1" {
+		t.Errorf("Headers = %v", create.Headers)
+	}
+	if _, ok := create.Headers["X-Debug"]; ok {
+		t.Error("disabled header X-Debug was kept")
+	}
+	if create.Payload["name"] != "alice" {
+		t.Errorf("Payload = %v", create.Payload)
+	}
+}
+
+func TestParseBrunoCollectionErrors(t *testing.T) {
+	tests := map[string]map[string]string{
+		"no requests": {
+			"bruno.json": `{"name":"Empty"}`,
+		},
+		"unclosed block": {
+			"bruno.json": `{"name":"Broken"}`,
+			"a.bru":      "get {\n  url: https://api.example.com\n",
+		},
+		"invalid bruno.json": {
+			"bruno.json": `{`,
+			"a.bru":      "get {\n  url: https://api.example.com\n}\n",
+		},
+	}
+
+	for name, files := range tests {
+		if _, err := ParseBrunoCollection(files); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
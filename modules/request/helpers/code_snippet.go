@@ -0,0 +1,321 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/jeksilaen/api-builder/modules/request/models"
+)
+
+// SnippetGenerator renders a saved request as client code in one language.
+type SnippetGenerator struct {
+	Lang     string
+	Label    string
+	template *template.Template
+}
+
+// SnippetHeader is a single header passed to the snippet templates.
+type SnippetHeader struct {
+	Name  string
+	Value string
+}
+
+// SnippetData is what every snippet template is executed with.
+type SnippetData struct {
+	Method  string
+	URL     string
+	Headers []SnippetHeader
+	Body    string
+	HasBody bool
+}
+
+var snippetGenerators = map[string]*SnippetGenerator{}
+
+// snippetFuncs quote values for each target language.
+var snippetFuncs = template.FuncMap{
+	"goString":   strconv.Quote,
+	"jsString":   jsonString,
+	"pyString":   jsonString,
+	"javaString": jsonString,
+	"phpString":  phpString,
+	"lower":      strings.ToLower,
+}
+
+// RegisterSnippetGenerator adds a template for lang, replacing any generator
+// already registered under that name. The template is executed with a
+// SnippetData value.
+func RegisterSnippetGenerator(lang, label, text string) error {
+	tmpl, err := template.New(lang).Funcs(snippetFuncs).Parse(text)
+	if err != nil {
+		return err
+	}
+
+	snippetGenerators[lang] = &SnippetGenerator{
+		Lang:     lang,
+		Label:    label,
+		template: tmpl,
+	}
+	return nil
+}
+
+// SnippetLanguages lists the registered languages in a stable order.
+func SnippetLanguages() []string {
+	langs := make([]string, 0, len(snippetGenerators))
+	for lang := range snippetGenerators {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// GenerateCodeSnippet renders request with the generator registered for lang.
+func GenerateCodeSnippet(request *models.Request, lang string) (string, error) {
+	generator, ok := snippetGenerators[lang]
+	if !ok {
+		return "", fmt.Errorf("unsupported lang %q, use one of: %s", lang, strings.Join(SnippetLanguages(), ", "))
+	}
+
+	var buf bytes.Buffer
+	if err := generator.template.Execute(&buf, newSnippetData(request)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func newSnippetData(request *models.Request) SnippetData {
+	data := SnippetData{
+		Method: request.Method,
+		URL:    request.URL,
+	}
+
+	contentType := fmt.Sprint(request.Headers[headerKey(request.Headers, "Content-Type")])
+	if headerKey(request.Headers, "Content-Type") == "" {
+		contentType = ""
+	}
+
+	// The snippets send multipart payloads as a urlencoded form
+	if strings.HasPrefix(contentType, "multipart/form-data") {
+		contentType = "application/x-www-form-urlencoded"
+	}
+
+	if request.RawBody != "" {
+		data.Body = request.RawBody
+	} else if len(request.Payload) > 0 {
+		if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+			form := url.Values{}
+			for key, value := range request.Payload {
+				form.Set(key, fmt.Sprint(value))
+			}
+			data.Body = form.Encode()
+		} else if payloadJSON, err := json.MarshalIndent(request.Payload, "", "  "); err == nil {
+			data.Body = string(payloadJSON)
+			if contentType == "" {
+				contentType = "application/json"
+			}
+		}
+	}
+	data.HasBody = data.Body != ""
+
+	for _, name := range sortedKeys(request.Headers) {
+		if strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+		data.Headers = append(data.Headers, SnippetHeader{Name: name, Value: fmt.Sprint(request.Headers[name])})
+	}
+	if data.HasBody && contentType != "" {
+		data.Headers = append(data.Headers, SnippetHeader{Name: "Content-Type", Value: contentType})
+	}
+	if request.BearerToken != "" {
+		data.Headers = append(data.Headers, SnippetHeader{Name: "Authorization", Value: "Bearer " + request.BearerToken})
+	}
+
+	return data
+}
+
+// jsonString quotes s as a JSON string, which is also a valid string
+// literal in Python, JavaScript and Java.
+func jsonString(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
+
+// phpString quotes s as a single-quoted PHP string.
+func phpString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
+
+func init() {
+	for _, generator := range []struct {
+		lang, label, text string
+	}{
+		{"go", "Go net/http", goSnippet},
+		{"python", "Python requests", pythonSnippet},
+		{"javascript", "JavaScript fetch", javascriptSnippet},
+		{"node", "Node.js axios", nodeSnippet},
+		{"java", "Java HttpClient", javaSnippet},
+		{"php", "PHP cURL", phpSnippet},
+	} {
+		if err := RegisterSnippetGenerator(generator.lang, generator.label, generator.text); err != nil {
+			panic(err)
+		}
+	}
+}
+
+const goSnippet = `package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+{{- if .HasBody}}
+	"strings"
+{{- end}}
+)
+
+func main() {
+{{- if .HasBody}}
+	body := strings.NewReader({{goString .Body}})
+	req, err := http.NewRequest({{goString .Method}}, {{goString .URL}}, body)
+{{- else}}
+	req, err := http.NewRequest({{goString .Method}}, {{goString .URL}}, nil)
+{{- end}}
+	if err != nil {
+		panic(err)
+	}
+{{- range .Headers}}
+	req.Header.Set({{goString .Name}}, {{goString .Value}})
+{{- end}}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		panic(err)
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(res.Status)
+	fmt.Println(string(resBody))
+}
+`
+
+const pythonSnippet = `import requests
+
+url = {{pyString .URL}}
+headers = {
+{{- range .Headers}}
+    {{pyString .Name}}: {{pyString .Value}},
+{{- end}}
+}
+{{- if .HasBody}}
+payload = {{pyString .Body}}
+
+response = requests.request({{pyString .Method}}, url, headers=headers, data=payload)
+{{- else}}
+
+response = requests.request({{pyString .Method}}, url, headers=headers)
+{{- end}}
+print(response.status_code)
+print(response.text)
+`
+
+const javascriptSnippet = `const response = await fetch({{jsString .URL}}, {
+  method: {{jsString .Method}},
+  headers: {
+{{- range .Headers}}
+    {{jsString .Name}}: {{jsString .Value}},
+{{- end}}
+  },
+{{- if .HasBody}}
+  body: {{jsString .Body}},
+{{- end}}
+});
+
+console.log(response.status);
+console.log(await response.text());
+`
+
+const nodeSnippet = `const axios = require("axios");
+
+axios({
+  method: {{jsString (lower .Method)}},
+  url: {{jsString .URL}},
+  headers: {
+{{- range .Headers}}
+    {{jsString .Name}}: {{jsString .Value}},
+{{- end}}
+  },
+{{- if .HasBody}}
+  data: {{jsString .Body}},
+{{- end}}
+})
+  .then((response) => {
+    console.log(response.status);
+    console.log(response.data);
+  })
+  .catch((error) => {
+    console.error(error);
+  });
+`
+
+const javaSnippet = `import java.net.URI;
+import java.net.http.HttpClient;
+import java.net.http.HttpRequest;
+import java.net.http.HttpResponse;
+
+public class Main {
+    public static void main(String[] args) throws Exception {
+        HttpClient client = HttpClient.newHttpClient();
+        HttpRequest request = HttpRequest.newBuilder()
+            .uri(URI.create({{javaString .URL}}))
+{{- range .Headers}}
+            .header({{javaString .Name}}, {{javaString .Value}})
+{{- end}}
+{{- if .HasBody}}
+            .method({{javaString .Method}}, HttpRequest.BodyPublishers.ofString({{javaString .Body}}))
+{{- else}}
+            .method({{javaString .Method}}, HttpRequest.BodyPublishers.noBody())
+{{- end}}
+            .build();
+
+        HttpResponse<String> response = client.send(request, HttpResponse.BodyHandlers.ofString());
+        System.out.println(response.statusCode());
+        System.out.println(response.body());
+    }
+}
+`
+
+const phpSnippet = `<?php
+
+$curl = curl_init();
+
+curl_setopt_array($curl, [
+    CURLOPT_URL => {{phpString .URL}},
+    CURLOPT_RETURNTRANSFER => true,
+    CURLOPT_CUSTOMREQUEST => {{phpString .Method}},
+    CURLOPT_HTTPHEADER => [
+{{- range .Headers}}
+        {{phpString (printf "%s: %s" .Name .Value)}},
+{{- end}}
+    ],
+{{- if .HasBody}}
+    CURLOPT_POSTFIELDS => {{phpString .Body}},
+{{- end}}
+]);
+
+$response = curl_exec($curl);
+$status = curl_getinfo($curl, CURLINFO_HTTP_CODE);
+curl_close($curl);
+
+echo $status . PHP_EOL;
+echo $response . PHP_EOL;
+`
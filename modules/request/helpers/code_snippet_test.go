@@ -0,0 +1,61 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jeksilaen/api-builder/modules/request/models"
+)
+
+func TestGenerateCodeSnippet(t *testing.T) {
+	request := &models.Request{
+		Method:      "POST",
+		URL:         "https://api.example.com/users",
+		BearerToken: "abc123",
+		Headers:     models.JSONMap{"X-Note": `it's "quoted"`},
+		Payload:     models.JSONMap{"name": "alice"},
+	}
+
+	for _, lang := range SnippetLanguages() {
+		snippet, err := GenerateCodeSnippet(request, lang)
+		if err != nil {
+			t.Errorf("%s: %v", lang, err)
+			continue
+		}
+		for _, want := range []string{"https://api.example.com/users", "Bearer abc123", "application/json", "alice"} {
+			if !strings.Contains(snippet, want) {
+				t.Errorf("%s snippet lacks %q:\n%s", lang, want, snippet)
+			}
+		}
+	}
+
+	if _, err := GenerateCodeSnippet(request, "cobol"); err == nil {
+		t.Error("no error for an unknown language")
+	}
+}
+
+func TestSnippetQuoting(t *testing.T) {
+	if quoted := phpString(`it's a \ path`); quoted != `'it\'s a \\ path'` {
+		t.Errorf("phpString = %s", quoted)
+	}
+	if quoted := jsonString("line\n\"quote\""); quoted != `"line\n\"quote\""` {
+		t.Errorf("jsonString = %s", quoted)
+	}
+}
+
+func TestSnippetFormBody(t *testing.T) {
+	data := newSnippetData(&models.Request{
+		Method:  "POST",
+		URL:     "https://api.example.com/login",
+		Headers: models.JSONMap{"content-type": "multipart/form-data"},
+		Payload: models.JSONMap{"user": "alice", "note": "a b"},
+	})
+
+	if data.Body != "note=a+b&user=alice" {
+		t.Errorf("Body = %q", data.Body)
+	}
+	last := data.Headers[len(data.Headers)-1]
+	if last.Name != "Content-Type" || last.Value != "application/x-www-form-urlencoded" {
+		t.Errorf("Headers = %v", data.Headers)
+	}
+}
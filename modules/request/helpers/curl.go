@@ -0,0 +1,370 @@
+package helpers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/jeksilaen/api-builder/modules/request/models"
+)
+
+// curlValueFlags are the curl options that don't change the request but
+// take a value, which is skipped along with them.
+var curlValueFlags = map[string]bool{
+	"-o": true, "--output": true, "--output-dir": true,
+	"-w": true, "--write-out": true,
+	"-m": true, "--max-time": true, "--connect-timeout": true,
+	"--retry": true, "--retry-delay": true, "--retry-max-time": true,
+	"--max-redirs": true, "--max-filesize": true, "--limit-rate": true,
+	"-y": true, "--speed-time": true, "-Y": true, "--speed-limit": true,
+	"-x": true, "--proxy": true, "-U": true, "--proxy-user": true, "--noproxy": true,
+	"-c": true, "--cookie-jar": true, "-D": true, "--dump-header": true,
+	"-E": true, "--cert": true, "--cert-type": true, "--key": true, "--key-type": true,
+	"--pass": true, "--cacert": true, "--capath": true, "--ciphers": true, "--pinnedpubkey": true,
+	"--resolve": true, "--connect-to": true, "--interface": true, "--local-port": true,
+	"--dns-servers": true, "--unix-socket": true, "--abstract-unix-socket": true,
+	"-r": true, "--range": true, "-C": true, "--continue-at": true,
+	"-z": true, "--time-cond": true, "-K": true, "--config": true,
+	"--trace": true, "--trace-ascii": true, "--stderr": true,
+	"--proto": true, "--proto-redir": true, "--tls-max": true,
+	"--expect100-timeout": true, "--keepalive-time": true,
+}
+
+// ParseCurlCommand turns a curl command line, as copied from browser
+// devtools or a terminal, into an unsaved Request.
+func ParseCurlCommand(command string) (*models.Request, error) {
+	imported, err := parseCurlCommand(command)
+	if err != nil {
+		return nil, err
+	}
+	return imported.ToRequest(), nil
+}
+
+func parseCurlCommand(command string) (*ImportedRequest, error) {
+	args, err := splitShellWords(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 || args[0] != "curl" {
+		return nil, errors.New("command must start with curl")
+	}
+
+	imported := &ImportedRequest{}
+	var data []string
+	getMode := false
+
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+
+		// Read the value of an option, either attached (-XPOST) or as the next argument
+		value := func(short, long string) (string, bool, error) {
+			if arg == short || (long != "" && arg == long) {
+				if i+1 >= len(args) {
+					return "", true, fmt.Errorf("missing value for %s", arg)
+				}
+				i++
+				return args[i], true, nil
+			}
+			if short != "" && strings.HasPrefix(arg, short) && !strings.HasPrefix(arg, "--") {
+				return arg[len(short):], true, nil
+			}
+			return "", false, nil
+		}
+
+		if v, ok, err := value("-X", "--request"); ok {
+			if err != nil {
+				return nil, err
+			}
+			imported.Method = v
+		} else if v, ok, err := value("-H", "--header"); ok {
+			if err != nil {
+				return nil, err
+			}
+			name, headerValue, found := strings.Cut(v, ":")
+			if !found {
+				return nil, fmt.Errorf("invalid header %q", v)
+			}
+			imported.Headers = append(imported.Headers, ImportedField{Name: strings.TrimSpace(name), Value: strings.TrimSpace(headerValue)})
+		} else if arg == "--data" || arg == "--data-raw" || arg == "--data-binary" || arg == "--data-ascii" || arg == "--data-urlencode" {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("missing value for %s", arg)
+			}
+			i++
+			if arg == "--data-urlencode" {
+				data = append(data, encodeDataURLEncode(args[i]))
+			} else {
+				data = append(data, args[i])
+			}
+		} else if v, ok, err := value("-d", ""); ok {
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, v)
+		} else if v, ok, err := value("-F", "--form"); ok {
+			if err != nil {
+				return nil, err
+			}
+			name, fieldValue, _ := strings.Cut(v, "=")
+			imported.Form = append(imported.Form, ImportedField{Name: name, Value: fieldValue})
+			imported.ContentType = "multipart/form-data"
+		} else if v, ok, err := value("-u", "--user"); ok {
+			if err != nil {
+				return nil, err
+			}
+			username, password, _ := strings.Cut(v, ":")
+			imported.BasicAuth = &ImportedBasicAuth{Username: username, Password: password}
+		} else if v, ok, err := value("-b", "--cookie"); ok {
+			if err != nil {
+				return nil, err
+			}
+			imported.Headers = append(imported.Headers, ImportedField{Name: "Cookie", Value: v})
+		} else if v, ok, err := value("-A", "--user-agent"); ok {
+			if err != nil {
+				return nil, err
+			}
+			imported.Headers = append(imported.Headers, ImportedField{Name: "User-Agent", Value: v})
+		} else if v, ok, err := value("-e", "--referer"); ok {
+			if err != nil {
+				return nil, err
+			}
+			imported.Headers = append(imported.Headers, ImportedField{Name: "Referer", Value: v})
+		} else if arg == "--url" {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("missing value for %s", arg)
+			}
+			i++
+			imported.URL = args[i]
+		} else if arg == "-G" || arg == "--get" {
+			getMode = true
+		} else if arg == "--compressed" {
+			// Go's HTTP client already negotiates and decodes gzip responses
+			continue
+		} else if curlValueFlags[arg] {
+			// The value of a flag such as -o or --max-time is not the URL
+			i++
+		} else if strings.HasPrefix(arg, "-") {
+			// Flags such as -s, -L, -k or -v don't change the request itself,
+			// and neither do the value flags with their value attached (-m5)
+			continue
+		} else if imported.URL == "" {
+			imported.URL = arg
+		}
+	}
+
+	if imported.URL == "" {
+		return nil, errors.New("curl command has no URL")
+	}
+
+	if getMode && len(data) > 0 {
+		separator := "?"
+		if strings.Contains(imported.URL, "?") {
+			separator = "&"
+		}
+		imported.URL += separator + strings.Join(data, "&")
+		if imported.Method == "" {
+			imported.Method = "GET"
+		}
+	} else if len(data) > 0 {
+		imported.Body = strings.Join(data, "&")
+	}
+
+	return imported, nil
+}
+
+// BuildCurlCommand renders a Request as a curl command that is safe to paste
+// into a POSIX shell.
+func BuildCurlCommand(request *models.Request) string {
+	parts := []string{"curl", "-X", request.Method, shellQuote(request.URL)}
+
+	// Sort the headers so the command is stable between exports
+	names := make([]string, 0, len(request.Headers))
+	for name := range request.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	contentType := ""
+	for _, name := range names {
+		value := fmt.Sprint(request.Headers[name])
+		if strings.EqualFold(name, "Content-Type") {
+			contentType = value
+			// curl sets the multipart boundary itself
+			if strings.HasPrefix(value, "multipart/form-data") {
+				continue
+			}
+		}
+		parts = append(parts, "-H", shellQuote(name+": "+value))
+	}
+
+	if request.BearerToken != "" {
+		parts = append(parts, "-H", shellQuote("Authorization: Bearer "+request.BearerToken))
+	}
+
+	if request.RawBody != "" {
+		parts = append(parts, "--data-raw", shellQuote(request.RawBody))
+	} else if len(request.Payload) > 0 {
+		if strings.HasPrefix(contentType, "multipart/form-data") {
+			for _, key := range sortedKeys(request.Payload) {
+				parts = append(parts, "-F", shellQuote(key+"="+fmt.Sprint(request.Payload[key])))
+			}
+		} else if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+			form := url.Values{}
+			for key, value := range request.Payload {
+				form.Set(key, fmt.Sprint(value))
+			}
+			parts = append(parts, "--data-raw", shellQuote(form.Encode()))
+		} else {
+			payloadJSON, err := json.Marshal(request.Payload)
+			if err == nil {
+				if contentType == "" {
+					parts = append(parts, "-H", shellQuote("Content-Type: application/json"))
+				}
+				parts = append(parts, "--data-raw", shellQuote(string(payloadJSON)))
+			}
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// shellQuote wraps s in single quotes, escaping embedded single quotes.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func sortedKeys(m models.JSONMap) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// headerKey returns the key under which a header is stored, ignoring case.
+func headerKey(headers models.JSONMap, name string) string {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return ""
+}
+
+// encodeDataURLEncode mirrors curl's --data-urlencode handling of
+// "content", "=content" and "name=content".
+func encodeDataURLEncode(value string) string {
+	name, content, found := strings.Cut(value, "=")
+	if !found {
+		return url.QueryEscape(value)
+	}
+	if name == "" {
+		return url.QueryEscape(content)
+	}
+	return name + "=" + url.QueryEscape(content)
+}
+
+// splitShellWords splits a command line the way a POSIX shell would,
+// handling single quotes, double quotes, $'...' strings and line
+// continuations.
+func splitShellWords(command string) ([]string, error) {
+	var words []string
+	var current strings.Builder
+	inWord := false
+
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case r == '\\':
+			if i+1 < len(runes) {
+				i++
+				// A backslash before a newline continues the line
+				if runes[i] != '\n' && runes[i] != '\r' {
+					current.WriteRune(runes[i])
+					inWord = true
+				} else if runes[i] == '\r' && i+1 < len(runes) && runes[i+1] == '\n' {
+					i++
+				}
+			}
+		case r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != '\'' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, errors.New("unterminated single quote")
+			}
+			current.WriteString(string(runes[i+1 : end]))
+			inWord = true
+			i = end
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			i += 2
+			closed := false
+			for ; i < len(runes); i++ {
+				if runes[i] == '\'' {
+					closed = true
+					break
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					switch runes[i] {
+					case 'n':
+						current.WriteRune('\n')
+					case 't':
+						current.WriteRune('\t')
+					case 'r':
+						current.WriteRune('\r')
+					default:
+						current.WriteRune(runes[i])
+					}
+					continue
+				}
+				current.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, errors.New("unterminated $' quote")
+			}
+			inWord = true
+		case r == '"':
+			i++
+			closed := false
+			for ; i < len(runes); i++ {
+				if runes[i] == '"' {
+					closed = true
+					break
+				}
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+				}
+				current.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, errors.New("unterminated double quote")
+			}
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		words = append(words, current.String())
+	}
+
+	return words, nil
+}
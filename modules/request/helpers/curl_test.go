@@ -0,0 +1,112 @@
+package helpers
+
+import (
+	"testing"
+)
+
+func TestParseCurlCommand(t *testing.T) {
+	request, err := ParseCurlCommand(`curl -X POST 'https://api.example.com/users?page=1' \
+  -H 'Content-Type: application/json' \
+  -H 'Authorization: Bearer abc123' \
+  --data-raw '{"name":"alice"}'`)
+	if err != nil {
+		t.Fatalf("ParseCurlCommand: %v", err)
+	}
+
+	if request.Method != "POST" {
+		t.Errorf("Method = %q, want POST", request.Method)
+	}
+	if request.URL != "https://api.example.com/users?page=1" {
+		t.Errorf("URL = %q", request.URL)
+	}
+	if request.BearerToken != "abc123" {
+		t.Errorf("BearerToken = %q, want abc123", request.BearerToken)
+	}
+	if request.Headers["Content-Type"] != "application/json" {
+		t.Errorf("Content-Type = %v", request.Headers["Content-Type"])
+	}
+	if request.Payload["name"] != "alice" {
+		t.Errorf("Payload = %v", request.Payload)
+	}
+	if request.Name != "POST /users" {
+		t.Errorf("Name = %q, want POST /users", request.Name)
+	}
+}
+
+func TestParseCurlCommandSkipsFlagValues(t *testing.T) {
+	tests := []string{
+		`curl -s -o /dev/null -w '%{http_code}' https://api.example.com/health`,
+		`curl --max-time 5 --connect-timeout 2 https://api.example.com/health`,
+		`curl -m5 -L -k https://api.example.com/health`,
+		`curl --retry 3 -x http://proxy:8080 https://api.example.com/health`,
+		`curl --url https://api.example.com/health -D headers.txt`,
+	}
+
+	for _, command := range tests {
+		request, err := ParseCurlCommand(command)
+		if err != nil {
+			t.Errorf("%s: %v", command, err)
+			continue
+		}
+		if request.URL != "https://api.example.com/health" {
+			t.Errorf("%s: URL = %q", command, request.URL)
+		}
+		if request.Method != "GET" {
+			t.Errorf("%s: Method = %q, want GET", command, request.Method)
+		}
+	}
+}
+
+func TestParseCurlCommandGetMode(t *testing.T) {
+	request, err := ParseCurlCommand(`curl -G https://api.example.com/search?lang=en -d q=go --data-urlencode 'tag=a b'`)
+	if err != nil {
+		t.Fatalf("ParseCurlCommand: %v", err)
+	}
+
+	if request.Method != "GET" {
+		t.Errorf("Method = %q, want GET", request.Method)
+	}
+	if request.URL != "https://api.example.com/search?lang=en&q=go&tag=a+b" {
+		t.Errorf("URL = %q", request.URL)
+	}
+	if request.Payload != nil || request.RawBody != "" {
+		t.Errorf("body = %v %q, want none", request.Payload, request.RawBody)
+	}
+}
+
+func TestParseCurlCommandFormAndAuth(t *testing.T) {
+	request, err := ParseCurlCommand(`curl -u admin:secret -F name=alice -F role=admin https://api.example.com/upload`)
+	if err != nil {
+		t.Fatalf("ParseCurlCommand: %v", err)
+	}
+
+	if request.Method != "POST" {
+		t.Errorf("Method = %q, want POST", request.Method)
+	}
+	if request.Headers["Authorization"] != "Basic YWRtaW46c2VjcmV0" {
+		t.Errorf("Authorization = %v", request.Headers["Authorization"])
+	}
+	if request.Headers["Content-Type"] != "multipart/form-data" {
+		t.Errorf("Content-Type = %v", request.Headers["Content-Type"])
+	}
+	if request.Payload["name"] != "alice" || request.Payload["role"] != "admin" {
+		t.Errorf("Payload = %v", request.Payload)
+	}
+}
+
+func TestParseCurlCommandErrors(t *testing.T) {
+	tests := []string{
+		``,
+		`wget https://api.example.com`,
+		`curl -s`,
+		`curl -H 'no colon' https://api.example.com`,
+		`curl https://api.example.com -X`,
+		`curl 'https://api.example.com`,
+	}
+
+	for _, command := range tests {
+		if _, err := ParseCurlCommand(command); err == nil {
+			t.Errorf("%q: no error", command)
+		}
+	}
+}
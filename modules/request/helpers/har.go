@@ -0,0 +1,181 @@
+package helpers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jeksilaen/api-builder/modules/request/models"
+)
+
+// ParseHAR reads every entry of a HAR document, keeping each recorded
+// response as the latest run of its request.
+func ParseHAR(har models.HAR) (*ImportedCollection, error) {
+	collection := &ImportedCollection{}
+
+	for _, entry := range har.Log.Entries {
+		if entry.Request.URL == "" {
+			return nil, errors.New("HAR entry has no URL")
+		}
+
+		imported := ImportedRequest{
+			Method:  entry.Request.Method,
+			URL:     entry.Request.URL,
+			Headers: harFields(entry.Request.Headers),
+		}
+
+		if postData := entry.Request.PostData; postData != nil {
+			imported.ContentType = postData.MimeType
+			if postData.Text != "" {
+				imported.Body = postData.Text
+			} else {
+				imported.Form = harFields(postData.Params)
+			}
+		}
+
+		if entry.Response.Status != 0 {
+			imported.Response = &ImportedResponse{
+				Status:      entry.Response.Status,
+				Headers:     harFields(entry.Response.Headers),
+				Body:        entry.Response.Content.Text,
+				ContentType: entry.Response.Content.MimeType,
+				Time:        int64(entry.Time),
+			}
+			if startedAt, err := time.Parse(time.RFC3339Nano, entry.StartedDateTime); err == nil {
+				imported.Response.StartedAt = &startedAt
+			}
+		}
+
+		collection.Requests = append(collection.Requests, imported)
+	}
+
+	return collection, nil
+}
+
+func harFields(values []models.HARNameValue) []ImportedField {
+	fields := make([]ImportedField, 0, len(values))
+	for _, value := range values {
+		fields = append(fields, ImportedField{Name: value.Name, Value: value.Value})
+	}
+	return fields
+}
+
+// BuildHAR wraps the latest run of every request in a HAR 1.2 document.
+// Requests that have never been run are left out.
+func BuildHAR(requests []*models.Request) *models.HAR {
+	har := &models.HAR{
+		Log: models.HARLog{
+			Version: "1.2",
+			Creator: models.HARCreator{
+				Name:    "api-builder",
+				Version: "1.0",
+			},
+			Entries: []models.HAREntry{},
+		},
+	}
+
+	for _, request := range requests {
+		if request.LastRunAt == nil {
+			continue
+		}
+		har.Log.Entries = append(har.Log.Entries, buildHAREntry(request))
+	}
+
+	return har
+}
+
+// buildHAREntry renders a request and its latest run as a HAR entry.
+func buildHAREntry(request *models.Request) models.HAREntry {
+	harRequest := models.HARRequest{
+		Method:      request.Method,
+		URL:         request.URL,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []models.HARNameValue{},
+		Headers:     []models.HARNameValue{},
+		QueryString: []models.HARNameValue{},
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+
+	for _, name := range sortedKeys(request.Headers) {
+		harRequest.Headers = append(harRequest.Headers, models.HARNameValue{Name: name, Value: fmt.Sprint(request.Headers[name])})
+	}
+	if request.BearerToken != "" {
+		harRequest.Headers = append(harRequest.Headers, models.HARNameValue{Name: "Authorization", Value: "Bearer " + request.BearerToken})
+	}
+
+	if parsedURL, err := url.Parse(request.URL); err == nil {
+		for key, values := range parsedURL.Query() {
+			for _, value := range values {
+				harRequest.QueryString = append(harRequest.QueryString, models.HARNameValue{Name: key, Value: value})
+			}
+		}
+	}
+
+	if request.RawBody != "" || len(request.Payload) > 0 {
+		postData := &models.HARPostData{
+			MimeType: fmt.Sprint(request.Headers[headerKey(request.Headers, "Content-Type")]),
+		}
+		if headerKey(request.Headers, "Content-Type") == "" {
+			postData.MimeType = "application/json"
+		}
+
+		if request.RawBody != "" {
+			postData.Text = request.RawBody
+		} else if strings.HasPrefix(postData.MimeType, "application/x-www-form-urlencoded") || strings.HasPrefix(postData.MimeType, "multipart/form-data") {
+			form := url.Values{}
+			for _, key := range sortedKeys(request.Payload) {
+				value := fmt.Sprint(request.Payload[key])
+				form.Set(key, value)
+				postData.Params = append(postData.Params, models.HARNameValue{Name: key, Value: value})
+			}
+			postData.Text = form.Encode()
+		} else if payloadJSON, err := json.Marshal(request.Payload); err == nil {
+			postData.Text = string(payloadJSON)
+		}
+
+		harRequest.PostData = postData
+	}
+
+	harResponse := models.HARResponse{
+		Status:      request.ResponseStatus,
+		StatusText:  http.StatusText(request.ResponseStatus),
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []models.HARNameValue{},
+		Headers:     []models.HARNameValue{},
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+
+	for _, name := range sortedKeys(request.ResponseHeaders) {
+		harResponse.Headers = append(harResponse.Headers, models.HARNameValue{Name: name, Value: fmt.Sprint(request.ResponseHeaders[name])})
+	}
+
+	harResponse.Content.MimeType = fmt.Sprint(request.ResponseHeaders[headerKey(request.ResponseHeaders, "Content-Type")])
+	if headerKey(request.ResponseHeaders, "Content-Type") == "" {
+		harResponse.Content.MimeType = "application/json"
+	}
+	if request.RawResponse != "" {
+		harResponse.Content.Text = request.RawResponse
+		harResponse.Content.Size = len(request.RawResponse)
+	} else if request.Response != nil {
+		if responseJSON, err := json.Marshal(request.Response); err == nil {
+			harResponse.Content.Text = string(responseJSON)
+			harResponse.Content.Size = len(responseJSON)
+		}
+	}
+
+	return models.HAREntry{
+		StartedDateTime: request.LastRunAt.Format(time.RFC3339Nano),
+		Time:            float64(request.ResponseTime),
+		Request:         harRequest,
+		Response:        harResponse,
+		Timings: models.HARTimings{
+			Wait: float64(request.ResponseTime),
+		},
+	}
+}
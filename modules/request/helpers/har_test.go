@@ -0,0 +1,137 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/jeksilaen/api-builder/modules/request/models"
+)
+
+func TestParseHAR(t *testing.T) {
+	har := models.HAR{Log: models.HARLog{Entries: []models.HAREntry{
+		{
+			StartedDateTime: "2024-05-01T10:00:00.000Z",
+			Time:            42,
+			Request: models.HARRequest{
+				Method: "POST",
+				URL:    "https://api.example.com/users",
+				Headers: []models.HARNameValue{
+					{Name: ":authority", Value: "api.example.com"},
+					{Name: "Content-Length", Value: "16"},
+					{Name: "Authorization", Value: "Bearer abc123"},
+					{Name: "X-Trace", Value: "1"},
+				},
+				PostData: &models.HARPostData{MimeType: "application/json", Text: `{"name":"alice"}`},
+			},
+			Response: models.HARResponse{
+				Status:  201,
+				Headers: []models.HARNameValue{{Name: "Content-Type", Value: "application/json"}},
+				Content: models.HARContent{Text: `{"id":"1"}`},
+			},
+		},
+		{
+			Request: models.HARRequest{
+				Method:   "POST",
+				URL:      "https://api.example.com/login",
+				PostData: &models.HARPostData{MimeType: "application/x-www-form-urlencoded", Params: []models.HARNameValue{{Name: "user", Value: "alice"}}},
+			},
+		},
+	}}}
+
+	collection, err := ParseHAR(har)
+	if err != nil {
+		t.Fatalf("ParseHAR: %v", err)
+	}
+	requests := collection.ToRequests()
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+
+	created := requests[0]
+	if created.BearerToken != "abc123" {
+		t.Errorf("BearerToken = %q, want abc123", created.BearerToken)
+	}
+	for _, name := range []string{":authority", "Content-Length", "Authorization"} {
+		if _, ok := created.Headers[name]; ok {
+			t.Errorf("header %s was kept", name)
+		}
+	}
+	if created.Headers["X-Trace"] != "1" {
+		t.Errorf("Headers = %v", created.Headers)
+	}
+	if created.Payload["name"] != "alice" {
+		t.Errorf("Payload = %v", created.Payload)
+	}
+	if created.ResponseStatus != 201 || created.ResponseTime != 42 || created.LastRunAt == nil {
+		t.Errorf("response = %d %d %v", created.ResponseStatus, created.ResponseTime, created.LastRunAt)
+	}
+	if created.Response["id"] != "1" {
+		t.Errorf("Response = %v", created.Response)
+	}
+
+	login := requests[1]
+	if login.Payload["user"] != "alice" {
+		t.Errorf("Payload = %v", login.Payload)
+	}
+	if login.Headers["Content-Type"] != "application/x-www-form-urlencoded" {
+		t.Errorf("Content-Type = %v", login.Headers["Content-Type"])
+	}
+	if login.ResponseStatus != 0 {
+		t.Errorf("ResponseStatus = %d, want none", login.ResponseStatus)
+	}
+}
+
+func TestParseHARWithoutURL(t *testing.T) {
+	har := models.HAR{Log: models.HARLog{Entries: []models.HAREntry{{Request: models.HARRequest{Method: "GET"}}}}}
+	if _, err := ParseHAR(har); err == nil {
+		t.Error("no error for an entry without URL")
+	}
+}
+
+func TestBuildHARLeavesOutRequestsNeverRun(t *testing.T) {
+	collection, err := ParseHAR(models.HAR{Log: models.HARLog{Entries: []models.HAREntry{
+		{
+			StartedDateTime: "2024-05-01T10:00:00.000Z",
+			Request:         models.HARRequest{Method: "GET", URL: "https://api.example.com/users"},
+			Response:        models.HARResponse{Status: 200, Content: models.HARContent{Text: `{"ok":true}`}},
+		},
+		{Request: models.HARRequest{Method: "GET", URL: "https://api.example.com/never"}},
+	}}})
+	if err != nil {
+		t.Fatalf("ParseHAR: %v", err)
+	}
+
+	har := BuildHAR(collection.ToRequests())
+	if len(har.Log.Entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(har.Log.Entries))
+	}
+	entry := har.Log.Entries[0]
+	if entry.Request.URL != "https://api.example.com/users" || entry.Response.Status != 200 {
+		t.Errorf("entry = %s %d", entry.Request.URL, entry.Response.Status)
+	}
+}
+
+func TestHARKeepsTextResponses(t *testing.T) {
+	collection, err := ParseHAR(models.HAR{Log: models.HARLog{Entries: []models.HAREntry{
+		{
+			StartedDateTime: "2024-05-01T10:00:00.000Z",
+			Request:         models.HARRequest{Method: "GET", URL: "https://api.example.com/health"},
+			Response:        models.HARResponse{Status: 200, Content: models.HARContent{MimeType: "text/plain", Text: "OK\n"}},
+		},
+	}}})
+	if err != nil {
+		t.Fatalf("ParseHAR: %v", err)
+	}
+
+	request := collection.ToRequests()[0]
+	if request.RawResponse != "OK\n" || request.Response != nil {
+		t.Errorf("RawResponse = %q, Response = %v, want the text as is", request.RawResponse, request.Response)
+	}
+	if request.ResponseHeaders["Content-Type"] != "text/plain" {
+		t.Errorf("ResponseHeaders = %v, want the content type kept", request.ResponseHeaders)
+	}
+
+	content := BuildHAR([]*models.Request{request}).Log.Entries[0].Response.Content
+	if content.MimeType != "text/plain" || content.Text != "OK\n" || content.Size != 3 {
+		t.Errorf("exported content = %+v, want the text/plain body back", content)
+	}
+}
@@ -0,0 +1,215 @@
+package helpers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jeksilaen/api-builder/modules/request/models"
+)
+
+// ImportedCollection is the format-neutral result of every importer (curl,
+// HAR, Insomnia, Bruno). Importers only translate their own syntax into it;
+// how it maps onto models.Request is decided here.
+type ImportedCollection struct {
+	Name     string
+	Requests []ImportedRequest
+}
+
+// ImportedRequest is a single request read from an external format.
+type ImportedRequest struct {
+	Name        string
+	Method      string
+	URL         string
+	Query       []ImportedField
+	Headers     []ImportedField
+	BearerToken string
+	BasicAuth   *ImportedBasicAuth
+	ContentType string
+	Body        string
+	Form        []ImportedField
+	Response    *ImportedResponse
+}
+
+// ImportedField is a name/value pair such as a header or a form field.
+type ImportedField struct {
+	Name  string
+	Value string
+}
+
+type ImportedBasicAuth struct {
+	Username string
+	Password string
+}
+
+// ImportedResponse is a response recorded by the source tool, kept as the
+// latest run of the imported request.
+type ImportedResponse struct {
+	Status      int
+	Headers     []ImportedField
+	Body        string
+	ContentType string
+	Time        int64
+	StartedAt   *time.Time
+}
+
+// importSkippedHeaders are recorded by browsers and clients but are
+// recomputed by the HTTP client when the request is executed again.
+var importSkippedHeaders = map[string]bool{
+	"content-length":    true,
+	"host":              true,
+	"connection":        true,
+	"accept-encoding":   true,
+	"transfer-encoding": true,
+}
+
+// ToRequests maps every imported request onto an unsaved Request.
+func (collection ImportedCollection) ToRequests() []*models.Request {
+	requests := make([]*models.Request, 0, len(collection.Requests))
+	for _, imported := range collection.Requests {
+		requests = append(requests, imported.ToRequest())
+	}
+	return requests
+}
+
+// ToRequest maps an imported request onto an unsaved Request.
+func (imported ImportedRequest) ToRequest() *models.Request {
+	request := &models.Request{
+		Method:      strings.ToUpper(imported.Method),
+		URL:         imported.URL,
+		BearerToken: imported.BearerToken,
+		Headers:     models.JSONMap{},
+	}
+
+	if request.Method == "" {
+		request.Method = "GET"
+		if imported.Body != "" || len(imported.Form) > 0 {
+			request.Method = "POST"
+		}
+	}
+
+	// Query parameters listed apart from the URL are appended to it
+	if len(imported.Query) > 0 {
+		query := url.Values{}
+		for _, field := range imported.Query {
+			query.Add(field.Name, field.Value)
+		}
+		separator := "?"
+		if strings.Contains(request.URL, "?") {
+			separator = "&"
+		}
+		request.URL += separator + query.Encode()
+	}
+
+	request.Name = imported.Name
+	if request.Name == "" {
+		if parsedURL, err := url.Parse(request.URL); err == nil && parsedURL.Path != "" {
+			request.Name = request.Method + " " + parsedURL.Path
+		} else {
+			request.Name = request.Method + " " + request.URL
+		}
+	}
+
+	for _, header := range imported.Headers {
+		// HTTP/2 pseudo headers such as :authority are not real headers
+		if strings.HasPrefix(header.Name, ":") || importSkippedHeaders[strings.ToLower(header.Name)] {
+			continue
+		}
+		if strings.EqualFold(header.Name, "Authorization") && strings.HasPrefix(header.Value, "Bearer ") {
+			if request.BearerToken == "" {
+				request.BearerToken = strings.TrimPrefix(header.Value, "Bearer ")
+			}
+			continue
+		}
+		request.Headers[header.Name] = header.Value
+	}
+
+	if imported.BasicAuth != nil {
+		credentials := imported.BasicAuth.Username + ":" + imported.BasicAuth.Password
+		request.Headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+	}
+
+	if imported.ContentType != "" && headerKey(request.Headers, "Content-Type") == "" {
+		request.Headers["Content-Type"] = imported.ContentType
+	}
+
+	if len(imported.Form) > 0 {
+		request.Payload = models.JSONMap{}
+		for _, field := range imported.Form {
+			request.Payload[field.Name] = field.Value
+		}
+		if headerKey(request.Headers, "Content-Type") == "" {
+			request.Headers["Content-Type"] = "application/x-www-form-urlencoded"
+		}
+	} else if imported.Body != "" {
+		setRequestBody(request, imported.Body)
+	}
+
+	if len(request.Headers) == 0 {
+		request.Headers = nil
+	}
+
+	if response := imported.Response; response != nil && response.Status != 0 {
+		request.ResponseStatus = response.Status
+		request.ResponseTime = response.Time
+		request.LastRunAt = response.StartedAt
+		request.ResponseHeaders = models.JSONMap{}
+		for _, header := range response.Headers {
+			request.ResponseHeaders[header.Name] = header.Value
+		}
+
+		if response.ContentType != "" && headerKey(request.ResponseHeaders, "Content-Type") == "" {
+			request.ResponseHeaders["Content-Type"] = response.ContentType
+		}
+
+		// Bodies other than JSON objects are kept as they were received
+		var responseData models.JSONMap
+		if err := json.Unmarshal([]byte(response.Body), &responseData); err == nil && responseData != nil {
+			request.Response = responseData
+		} else {
+			request.RawResponse = response.Body
+		}
+	}
+
+	return request
+}
+
+// setRequestBody stores body as a JSON Payload, a form Payload or a RawBody,
+// filling in the Content-Type header when it is missing.
+func setRequestBody(request *models.Request, body string) {
+	if request.Headers == nil {
+		request.Headers = models.JSONMap{}
+	}
+	contentType := headerKey(request.Headers, "Content-Type")
+
+	var payload models.JSONMap
+	if err := json.Unmarshal([]byte(body), &payload); err == nil && payload != nil {
+		request.Payload = payload
+		if contentType == "" {
+			request.Headers["Content-Type"] = "application/json"
+		}
+	} else if values, err := url.ParseQuery(body); err == nil && isFormBody(body) && (contentType == "" || strings.HasPrefix(fmt.Sprint(request.Headers[contentType]), "application/x-www-form-urlencoded")) {
+		request.Payload = models.JSONMap{}
+		for key := range values {
+			request.Payload[key] = values.Get(key)
+		}
+		if contentType == "" {
+			request.Headers["Content-Type"] = "application/x-www-form-urlencoded"
+		}
+	} else {
+		request.RawBody = body
+	}
+}
+
+// isFormBody reports whether every &-separated part of body is a name=value pair.
+func isFormBody(body string) bool {
+	for _, part := range strings.Split(body, "&") {
+		if !strings.Contains(part, "=") || strings.ContainsAny(part, " \n") {
+			return false
+		}
+	}
+	return true
+}
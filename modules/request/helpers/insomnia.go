@@ -0,0 +1,93 @@
+package helpers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jeksilaen/api-builder/modules/request/models"
+)
+
+// ParseInsomniaExport reads the requests of an Insomnia v4 export. Requests
+// inside folders are named after their folder path, e.g. "Users / Create".
+func ParseInsomniaExport(export models.InsomniaExport) (*ImportedCollection, error) {
+	if export.ExportFormat != 4 {
+		return nil, errors.New("only Insomnia v4 exports are supported")
+	}
+
+	resources := make(map[string]models.InsomniaResource)
+	for _, resource := range export.Resources {
+		resources[resource.ID] = resource
+	}
+
+	collection := &ImportedCollection{}
+	for _, resource := range export.Resources {
+		switch resource.Type {
+		case "workspace":
+			if collection.Name == "" {
+				collection.Name = resource.Name
+			}
+		case "request":
+			collection.Requests = append(collection.Requests, insomniaRequest(resource, resources))
+		}
+	}
+
+	if len(collection.Requests) == 0 {
+		return nil, errors.New("Insomnia export has no requests")
+	}
+
+	return collection, nil
+}
+
+func insomniaRequest(resource models.InsomniaResource, resources map[string]models.InsomniaResource) ImportedRequest {
+	imported := ImportedRequest{
+		Name:   resource.Name,
+		Method: resource.Method,
+		URL:    resource.URL,
+	}
+
+	// Prefix the name with the folders the request lives in
+	for parent, ok := resources[resource.ParentID]; ok && parent.Type == "request_group"; parent, ok = resources[parent.ParentID] {
+		imported.Name = parent.Name + " / " + imported.Name
+	}
+
+	imported.Query = insomniaFields(resource.Parameters)
+	imported.Headers = insomniaFields(resource.Headers)
+
+	if auth := resource.Authentication; !auth.Disabled {
+		switch auth.Type {
+		case "bearer":
+			if auth.Prefix == "" || auth.Prefix == "Bearer" {
+				imported.BearerToken = auth.Token
+			} else {
+				imported.Headers = append(imported.Headers, ImportedField{Name: "Authorization", Value: auth.Prefix + " " + auth.Token})
+			}
+		case "basic":
+			imported.BasicAuth = &ImportedBasicAuth{Username: auth.Username, Password: auth.Password}
+		}
+	}
+
+	imported.ContentType = resource.Body.MimeType
+	if len(resource.Body.Params) > 0 {
+		imported.Form = insomniaFields(resource.Body.Params)
+	} else {
+		imported.Body = resource.Body.Text
+	}
+
+	// Insomnia stores GraphQL bodies as JSON text under its own mime type
+	if strings.HasPrefix(imported.ContentType, "application/graphql") {
+		imported.ContentType = "application/json"
+	}
+
+	return imported
+}
+
+func insomniaFields(params []models.InsomniaParam) []ImportedField {
+	var fields []ImportedField
+	for _, param := range params {
+		if param.Disabled || param.Name == "" {
+			continue
+		}
+		fields = append(fields, ImportedField{Name: param.Name, Value: param.Value})
+	}
+	return fields
+}
@@ -0,0 +1,97 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/jeksilaen/api-builder/modules/request/models"
+)
+
+func TestParseInsomniaExport(t *testing.T) {
+	export := models.InsomniaExport{
+		ExportFormat: 4,
+		Resources: []models.InsomniaResource{
+			{ID: "wrk_1", Type: "workspace", Name: "Shop"},
+			{ID: "fld_1", Type: "request_group", ParentID: "wrk_1", Name: "Users"},
+			{ID: "fld_2", Type: "request_group", ParentID: "fld_1", Name: "Admin"},
+			{
+				ID:       "req_1",
+				Type:     "request",
+				ParentID: "fld_2",
+				Name:     "Create",
+				Method:   "post",
+				URL:      "https://api.example.com/users",
+				Parameters: []models.InsomniaParam{
+					{Name: "notify", Value: "true"},
+					{Name: "debug", Value: "1", Disabled: true},
+				},
+				Headers:        []models.InsomniaParam{{Name: "X-Trace", Value: "1"}},
+				Authentication: models.InsomniaAuth{Type: "bearer", Token: "abc123"},
+				Body:           models.InsomniaBody{MimeType: "application/json", Text: `{"name":"alice"}`},
+			},
+			{
+				ID:             "req_2",
+				Type:           "request",
+				ParentID:       "wrk_1",
+				Name:           "Login",
+				Method:         "POST",
+				URL:            "https://api.example.com/login",
+				Authentication: models.InsomniaAuth{Type: "basic", Username: "admin", Password: "secret"},
+				Body: models.InsomniaBody{
+					MimeType: "application/x-www-form-urlencoded",
+					Params:   []models.InsomniaParam{{Name: "remember", Value: "yes"}},
+				},
+			},
+		},
+	}
+
+	collection, err := ParseInsomniaExport(export)
+	if err != nil {
+		t.Fatalf("ParseInsomniaExport: %v", err)
+	}
+	if collection.Name != "Shop" {
+		t.Errorf("Name = %q, want Shop", collection.Name)
+	}
+
+	requests := collection.ToRequests()
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+
+	create := requests[0]
+	if create.Name != "Users / Admin / Create" {
+		t.Errorf("Name = %q", create.Name)
+	}
+	if create.Method != "POST" {
+		t.Errorf("Method = %q, want POST", create.Method)
+	}
+	if create.URL != "https://api.example.com/users?notify=true" {
+		t.Errorf("URL = %q", create.URL)
+	}
+	if create.BearerToken != "abc123" {
+		t.Errorf("BearerToken = %q", create.BearerToken)
+	}
+	if create.Payload["name"] != "alice" {
+		t.Errorf("Payload = %v", create.Payload)
+	}
+
+	login := requests[1]
+	if login.Headers["Authorization"] != "Basic YWRtaW46c2VjcmV0" {
+		t.Errorf("Authorization = %v", login.Headers["Authorization"])
+	}
+	if login.Payload["remember"] != "yes" {
+		t.Errorf("Payload = %v", login.Payload)
+	}
+}
+
+func TestParseInsomniaExportErrors(t *testing.T) {
+	tests := []models.InsomniaExport{
+		{ExportFormat: 3, Resources: []models.InsomniaResource{{ID: "req_1", Type: "request", URL: "https://api.example.com"}}},
+		{ExportFormat: 4, Resources: []models.InsomniaResource{{ID: "wrk_1", Type: "workspace", Name: "Empty"}}},
+	}
+
+	for _, export := range tests {
+		if _, err := ParseInsomniaExport(export); err == nil {
+			t.Errorf("format %d with %d resources: no error", export.ExportFormat, len(export.Resources))
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package helpers
+
+import (
+	"strings"
+
+	"github.com/jeksilaen/api-builder/modules/request/models"
+)
+
+// RedactedValue replaces the secret values hidden from a member.
+const RedactedValue = "********"
+
+// sensitiveHeaders are the headers carrying credentials.
+var sensitiveHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"x-api-key":           true,
+	"x-auth-token":        true,
+}
+
+// RedactRequests hides the bearer token and the credential headers of the
+// requests for members who can't see secrets. Redacted requests must not be
+// saved.
+func RedactRequests(requests []*models.Request) {
+	for _, request := range requests {
+		if request.BearerToken != "" {
+			request.BearerToken = RedactedValue
+		}
+		if request.Headers == nil {
+			continue
+		}
+
+		headers := models.JSONMap{}
+		for name, value := range request.Headers {
+			if IsSensitiveHeader(name) {
+				value = RedactedValue
+			}
+			headers[name] = value
+		}
+		request.Headers = headers
+	}
+}
+
+// IsSensitiveHeader reports whether a header carries credentials.
+func IsSensitiveHeader(name string) bool {
+	return sensitiveHeaders[strings.ToLower(name)]
+}
+
+// KeepSecretHeaders returns the headers of an update with the stored values
+// of its credential headers that were sent back redacted. Members who can't
+// see secrets can't change the credential headers either: they keep the
+// stored ones.
+func KeepSecretHeaders(stored models.JSONMap, updated models.JSONMap, canSeeSecrets bool) models.JSONMap {
+	headers := models.JSONMap{}
+	for name, value := range updated {
+		if IsSensitiveHeader(name) && !canSeeSecrets {
+			continue
+		}
+		if IsSensitiveHeader(name) && value == RedactedValue {
+			storedValue, ok := stored[name]
+			if !ok {
+				continue
+			}
+			value = storedValue
+		}
+		headers[name] = value
+	}
+
+	if !canSeeSecrets {
+		for name, value := range stored {
+			if IsSensitiveHeader(name) {
+				headers[name] = value
+			}
+		}
+	}
+	return headers
+}
@@ -1,7 +1,8 @@
 package helpers
 
 import (	
-	"log"
+	"log/slog"
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
 	"github.com/jeksilaen/api-builder/modules/request/models"
 	"encoding/json"
 )
@@ -12,12 +13,12 @@ func ReturnSucessGetResponse(request []*models.Request) *models.SucessGetRespons
 	for _, request := range request {
 		payloadDataBytes, err := json.Marshal(request.Payload)
 		if err != nil {
-			log.Println("Failed to encode payload data to JSON:", err)
+			slog.Error("Failed to encode payload data to JSON", "error", err)
 			return nil
 		}
 		responseDataBytes, err := json.Marshal(request.Response)
 		if err != nil {
-			log.Println("Failed to encode response data to JSON:", err)
+			slog.Error("Failed to encode response data to JSON", "error", err)
 			return nil
 		}
 		requestResponses = append(requestResponses, models.RequestResponse{
@@ -27,8 +28,17 @@ func ReturnSucessGetResponse(request []*models.Request) *models.SucessGetRespons
 			URL:	request.URL,
 			Method:	request.Method,
 			BearerToken: request.BearerToken,
+			Headers:	request.Headers,
+			RawBody:	request.RawBody,
 			Payload:      json.RawMessage(payloadDataBytes),
 			Response:     json.RawMessage(responseDataBytes),
+			RawResponse:    request.RawResponse,
+			ResponseStatus: request.ResponseStatus,
+			ResponseTime:   request.ResponseTime,
+			LastRunAt:      request.LastRunAt,
+			MockStatus:     request.MockStatus,
+			MockHeaders:    request.MockHeaders,
+			MockDelay:      request.MockDelay,
 		})
 	}
 
@@ -68,39 +78,39 @@ func convertToJSON(data interface{}) ([]byte, error) {
 func ReturnSucessCreateRequestResponse(createdRequest *models.Request) *models.SucessCreateResponse {
 	payloadJSON, err := convertToJSON(createdRequest.Payload)
 	if err != nil {
-		log.Println("Failed to encode payload to JSON:", err)
+		slog.Error("Failed to encode payload to JSON", "error", err)
 		return nil
 	}
 
 	var payloadData map[string]interface{}
 	err = json.Unmarshal(payloadJSON, &payloadData)
 	if err != nil {
-		log.Println("Failed to unmarshal JSON payload:", err)
+		slog.Error("Failed to unmarshal JSON payload", "error", err)
 		return nil
 	}
 
 	payloadDataBytes, err := json.Marshal(payloadData)
 	if err != nil {
-		log.Println("Failed to encode payload data to JSON:", err)
+		slog.Error("Failed to encode payload data to JSON", "error", err)
 		return nil
 	}
 
 	responseJSON, err := convertToJSON(createdRequest.Response)
 	if err != nil {
-		log.Println("Failed to encode response to JSON:", err)
+		slog.Error("Failed to encode response to JSON", "error", err)
 		return nil
 	}
 
 	var responseData map[string]interface{}
 	err = json.Unmarshal(responseJSON, &responseData)
 	if err != nil {
-		log.Println("Failed to unmarshal JSON response:", err)
+		slog.Error("Failed to unmarshal JSON response", "error", err)
 		return nil
 	}
 
 	responseDataBytes, err := json.Marshal(responseData)
 	if err != nil {
-		log.Println("Failed to encode response data to JSON:", err)
+		slog.Error("Failed to encode response data to JSON", "error", err)
 		return nil
 	}
 
@@ -113,8 +123,17 @@ func ReturnSucessCreateRequestResponse(createdRequest *models.Request) *models.S
 			URL:          createdRequest.URL,
 			Method:       createdRequest.Method,
 			BearerToken:  createdRequest.BearerToken,
+			Headers:      createdRequest.Headers,
+			RawBody:      createdRequest.RawBody,
 			Payload:      json.RawMessage(payloadDataBytes),
 			Response:     json.RawMessage(responseDataBytes),
+			RawResponse:    createdRequest.RawResponse,
+			ResponseStatus: createdRequest.ResponseStatus,
+			ResponseTime:   createdRequest.ResponseTime,
+			LastRunAt:      createdRequest.LastRunAt,
+			MockStatus:     createdRequest.MockStatus,
+			MockHeaders:    createdRequest.MockHeaders,
+			MockDelay:      createdRequest.MockDelay,
 		},		
 	}
 }
@@ -122,39 +141,39 @@ func ReturnSucessCreateRequestResponse(createdRequest *models.Request) *models.S
 func ReturnSucessUpdateRequestResponse(createdRequest *models.Request) *models.SucessCreateResponse {
 	payloadJSON, err := convertToJSON(createdRequest.Payload)
 	if err != nil {
-		log.Println("Failed to encode payload to JSON:", err)
+		slog.Error("Failed to encode payload to JSON", "error", err)
 		return nil
 	}
 
 	var payloadData map[string]interface{}
 	err = json.Unmarshal(payloadJSON, &payloadData)
 	if err != nil {
-		log.Println("Failed to unmarshal JSON payload:", err)
+		slog.Error("Failed to unmarshal JSON payload", "error", err)
 		return nil
 	}
 
 	payloadDataBytes, err := json.Marshal(payloadData)
 	if err != nil {
-		log.Println("Failed to encode payload data to JSON:", err)
+		slog.Error("Failed to encode payload data to JSON", "error", err)
 		return nil
 	}
 
 	responseJSON, err := convertToJSON(createdRequest.Response)
 	if err != nil {
-		log.Println("Failed to encode response to JSON:", err)
+		slog.Error("Failed to encode response to JSON", "error", err)
 		return nil
 	}
 
 	var responseData map[string]interface{}
 	err = json.Unmarshal(responseJSON, &responseData)
 	if err != nil {
-		log.Println("Failed to unmarshal JSON response:", err)
+		slog.Error("Failed to unmarshal JSON response", "error", err)
 		return nil
 	}
 
 	responseDataBytes, err := json.Marshal(responseData)
 	if err != nil {
-		log.Println("Failed to encode response data to JSON:", err)
+		slog.Error("Failed to encode response data to JSON", "error", err)
 		return nil
 	}
 
@@ -167,8 +186,17 @@ func ReturnSucessUpdateRequestResponse(createdRequest *models.Request) *models.S
 			URL:          createdRequest.URL,
 			Method:       createdRequest.Method,
 			BearerToken:  createdRequest.BearerToken,
+			Headers:      createdRequest.Headers,
+			RawBody:      createdRequest.RawBody,
 			Payload:      json.RawMessage(payloadDataBytes),
 			Response:     json.RawMessage(responseDataBytes),
+			RawResponse:    createdRequest.RawResponse,
+			ResponseStatus: createdRequest.ResponseStatus,
+			ResponseTime:   createdRequest.ResponseTime,
+			LastRunAt:      createdRequest.LastRunAt,
+			MockStatus:     createdRequest.MockStatus,
+			MockHeaders:    createdRequest.MockHeaders,
+			MockDelay:      createdRequest.MockDelay,
 		},		
 	}
 }
@@ -192,4 +220,80 @@ func ReturnSucessDeleteResponse(request []*models.Request) *models.SucessDeleteR
 			},
 		},
 	}
-}
\ No newline at end of file
+}
+
+func ReturnFailedImportRequestResponse(message string) *models.FailedResponse {
+	return &models.FailedResponse{
+		Error:   "Import failed",
+		Message: message,
+		Links: []models.Link{
+			{
+				Rel:  "import curl",
+				Href: "/users/v1/request/import/curl",
+			},
+		},
+	}
+}
+
+func ReturnSucessExportCurlResponse(request *models.Request) *models.SucessExportCurlResponse {
+	return &models.SucessExportCurlResponse{
+		Message: "Export Request sucessfully",
+		Data: models.ExportCurlResponse{
+			ID:      request.ID,
+			Command: BuildCurlCommand(request),
+		},
+		Links: []models.Link{
+			{
+				Rel:  "get request",
+				Href: "/users/v1/request/" + request.ID,
+			},
+		},
+	}
+}
+
+func ReturnSucessImportCollectionResponse(collection *collectionModels.Collection, requests []*models.Request) *models.SucessImportCollectionResponse {
+	return &models.SucessImportCollectionResponse{
+		Message: "Import Collection sucessfully",
+		Data: models.ImportCollectionResponse{
+			CollectionID: collection.ID,
+			Name:         collection.Name,
+			Requests:     ReturnSucessGetResponse(requests).Data,
+		},
+		Links: []models.Link{
+			{
+				Rel:  "get request",
+				Href: "/users/v1/request_by_collection/" + collection.ID,
+			},
+		},
+	}
+}
+
+func ReturnSucessCodeSnippetResponse(request *models.Request, lang string, code string) *models.SucessCodeSnippetResponse {
+	return &models.SucessCodeSnippetResponse{
+		Message: "Generate code sucessfully",
+		Data: models.CodeSnippetResponse{
+			ID:   request.ID,
+			Lang: lang,
+			Code: code,
+		},
+		Links: []models.Link{
+			{
+				Rel:  "get request",
+				Href: "/users/v1/request/" + request.ID,
+			},
+		},
+	}
+}
+
+func ReturnSucessRunCollectionResponse(report *models.RunReport) *models.SucessRunCollectionResponse {
+	return &models.SucessRunCollectionResponse{
+		Message: "Run collection sucessfully",
+		Data:    *report,
+		Links: []models.Link{
+			{
+				Rel:  "get request",
+				Href: "/users/v1/request_by_collection/" + report.CollectionID,
+			},
+		},
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+// InsomniaExport is an Insomnia v4 JSON export, reduced to the resources
+// api-builder can import.
+type InsomniaExport struct {
+	Type         string             `json:"_type"`
+	ExportFormat int                `json:"__export_format"`
+	Resources    []InsomniaResource `json:"resources"`
+}
+
+type InsomniaResource struct {
+	ID             string          `json:"_id"`
+	Type           string          `json:"_type"`
+	ParentID       string          `json:"parentId"`
+	Name           string          `json:"name"`
+	Method         string          `json:"method"`
+	URL            string          `json:"url"`
+	Body           InsomniaBody    `json:"body"`
+	Headers        []InsomniaParam `json:"headers"`
+	Parameters     []InsomniaParam `json:"parameters"`
+	Authentication InsomniaAuth    `json:"authentication"`
+}
+
+type InsomniaBody struct {
+	MimeType string          `json:"mimeType"`
+	Text     string          `json:"text"`
+	Params   []InsomniaParam `json:"params"`
+}
+
+type InsomniaParam struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled"`
+}
+
+type InsomniaAuth struct {
+	Type     string `json:"type"`
+	Token    string `json:"token"`
+	Prefix   string `json:"prefix"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Disabled bool   `json:"disabled"`
+}
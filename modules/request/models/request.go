@@ -1,25 +1,36 @@
 package models
 
 import (
-	"github.com/google/uuid"
-	"gorm.io/gorm"
-	"github.com/jeksilaen/api-builder/modules/collection/models"
+	"database/sql/driver"
 	"encoding/json"
-	"database/sql/driver" 
 	"errors"
+	"github.com/google/uuid"
+	"github.com/jeksilaen/api-builder/modules/collection/models"
+	"gorm.io/gorm"
+	"time"
 )
 
 type Request struct {
 	gorm.Model
-	ID         string                 `gorm:"type:uuid;primaryKey"`
-	CollectionID string               `gorm:"type:uuid;"`
-	Name       string                 `json:"name" validate:"required"`
-	URL        string                 `json:"url"`
-	Method        string                 `json:"method"`
-	BearerToken string					`json:"bearer_token"`
-	Payload      JSONMap   `gorm:"type:json"`
-    Response     JSONMap   `gorm:"type:json"`
-	Collection   models.Collection `gorm:"foreignKey:CollectionID"`
+	ID              string            `gorm:"type:uuid;primaryKey"`
+	CollectionID    string            `gorm:"type:uuid;index"`
+	Name            string            `json:"name" validate:"required"`
+	URL             string            `json:"url"`
+	Method          string            `json:"method"`
+	BearerToken     string            `json:"bearer_token"`
+	Headers         JSONMap           `json:"headers" gorm:"type:json"`
+	Payload         JSONMap           `gorm:"type:json"`
+	RawBody         string            `json:"raw_body"`
+	Response        JSONMap           `gorm:"type:json"`
+	RawResponse     string            `json:"raw_response"`
+	ResponseStatus  int               `json:"response_status"`
+	ResponseHeaders JSONMap           `json:"response_headers" gorm:"type:json"`
+	ResponseTime    int64             `json:"response_time"`
+	LastRunAt       *time.Time        `json:"last_run_at"`
+	MockStatus      int               `json:"mock_status"`
+	MockHeaders     JSONMap           `json:"mock_headers" gorm:"type:json"`
+	MockDelay       int               `json:"mock_delay"`
+	Collection      models.Collection `gorm:"foreignKey:CollectionID"`
 }
 
 type JSONMap map[string]interface{}
@@ -43,35 +54,103 @@ func (j JSONMap) Value() (driver.Value, error) {
 }
 
 type RequestResponse struct {
-	ID       string `json:"id"`
-	CollectionID   string `json:"collection_id"`
-	Name    string `json:"name" validate:"required"`
-	URL    string `json:"url"`
-	Method        string                 `json:"method"`
-	BearerToken string					`json:"bearer_token"`
-	Payload      json.RawMessage `json:"payload"`
-	Response     json.RawMessage `json:"response"`
+	ID             string          `json:"id"`
+	CollectionID   string          `json:"collection_id"`
+	Name           string          `json:"name" validate:"required"`
+	URL            string          `json:"url"`
+	Method         string          `json:"method"`
+	BearerToken    string          `json:"bearer_token"`
+	Headers        JSONMap         `json:"headers"`
+	Payload        json.RawMessage `json:"payload"`
+	RawBody        string          `json:"raw_body,omitempty"`
+	Response       json.RawMessage `json:"response"`
+	RawResponse    string          `json:"raw_response,omitempty"`
+	ResponseStatus int             `json:"response_status"`
+	ResponseTime   int64           `json:"response_time"`
+	LastRunAt      *time.Time      `json:"last_run_at"`
+	MockStatus     int             `json:"mock_status"`
+	MockHeaders    JSONMap         `json:"mock_headers"`
+	MockDelay      int             `json:"mock_delay"`
 }
 
 type SucessCreateResponse struct {
-	Message string       `json:"message"`
-	Data    RequestResponse `json:"data"`	
+	Message string          `json:"message"`
+	Data    RequestResponse `json:"data"`
 }
 
 type SucessGetResponse struct {
-	Message string             `json:"message"`
+	Message string            `json:"message"`
 	Data    []RequestResponse `json:"data"`
+	Links   []Link            `json:"links"`
+}
+
+type MockConfigRequest struct {
+	Status  int     `json:"status" validate:"omitempty,min=100,max=599"`
+	Headers JSONMap `json:"headers"`
+	Delay   int     `json:"delay" validate:"min=0,max=10000"`
+}
+
+type ImportCurlRequest struct {
+	CollectionID string `json:"collection_id" validate:"required"`
+	Name         string `json:"name"`
+	Command      string `json:"command" validate:"required"`
+}
+
+type ImportHARRequest struct {
+	WorkspaceID string `json:"workspace_id"`
+	Name        string `json:"name" validate:"required"`
+	HAR         HAR    `json:"har"`
+}
+
+type ImportInsomniaRequest struct {
+	WorkspaceID string         `json:"workspace_id"`
+	Name        string         `json:"name"`
+	Insomnia    InsomniaExport `json:"insomnia"`
+}
+
+type ImportCollectionResponse struct {
+	CollectionID string            `json:"collection_id"`
+	Name         string            `json:"name"`
+	Requests     []RequestResponse `json:"requests"`
+}
+
+type SucessImportCollectionResponse struct {
+	Message string                   `json:"message"`
+	Data    ImportCollectionResponse `json:"data"`
+	Links   []Link                   `json:"links"`
+}
+
+type ExportCurlResponse struct {
+	ID      string `json:"id"`
+	Command string `json:"command"`
+}
+
+type SucessExportCurlResponse struct {
+	Message string             `json:"message"`
+	Data    ExportCurlResponse `json:"data"`
 	Links   []Link             `json:"links"`
 }
 
+type CodeSnippetResponse struct {
+	ID   string `json:"id"`
+	Lang string `json:"lang"`
+	Code string `json:"code"`
+}
+
+type SucessCodeSnippetResponse struct {
+	Message string              `json:"message"`
+	Data    CodeSnippetResponse `json:"data"`
+	Links   []Link              `json:"links"`
+}
+
 type DeleteResponse struct {
-	ID       string `json:"id"`
+	ID string `json:"id"`
 }
 
 type SucessDeleteResponse struct {
-	Message string             `json:"message"`
+	Message string           `json:"message"`
 	Data    []DeleteResponse `json:"data"`
-	Links   []Link             `json:"links"`
+	Links   []Link           `json:"links"`
 }
 
 type FailedResponse struct {
@@ -88,4 +167,4 @@ type Link struct {
 func (request *Request) BeforeCreate(tx *gorm.DB) error {
 	request.ID = uuid.New().String()
 	return nil
-}
\ No newline at end of file
+}
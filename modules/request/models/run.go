@@ -0,0 +1,64 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// RunResult is the outcome of one request during a collection run. A
+// request passes when it gets a 2xx response.
+type RunResult struct {
+	RequestID    string `json:"request_id"`
+	Name         string `json:"name"`
+	Method       string `json:"method"`
+	URL          string `json:"url"`
+	Status       int    `json:"status"`
+	ResponseTime int64  `json:"response_time"`
+	Passed       bool   `json:"passed"`
+	Error        string `json:"error,omitempty"`
+}
+
+type RunResults []RunResult
+
+// Scan converts JSON data from the database into RunResults.
+func (r *RunResults) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("Failed to unmarshal RunResults")
+	}
+	return json.Unmarshal(b, r)
+}
+
+// Value converts RunResults into JSON for storage in the database.
+func (r RunResults) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// RunReport summarises a run of every request in a collection.
+type RunReport struct {
+	CollectionID string     `json:"collection_id"`
+	StartedAt    time.Time  `json:"started_at"`
+	Duration     int64      `json:"duration"`
+	Total        int        `json:"total"`
+	Passed       int        `json:"passed"`
+	Failed       int        `json:"failed"`
+	Results      RunResults `json:"results"`
+}
+
+// RunCollectionRequest picks the variables of a collection run: those of an
+// environment, overridden by the ones given inline.
+type RunCollectionRequest struct {
+	EnvironmentID string            `json:"environment_id"`
+	Variables     map[string]string `json:"variables"`
+}
+
+type SucessRunCollectionResponse struct {
+	Message string    `json:"message"`
+	Data    RunReport `json:"data"`
+	Links   []Link    `json:"links"`
+}
@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	collectionRepositories "github.com/jeksilaen/api-builder/modules/collection/repositories"
+	mockModels "github.com/jeksilaen/api-builder/modules/mock/models"
+	"github.com/jeksilaen/api-builder/modules/request/models"
+	"gorm.io/gorm"
+)
+
+// GormRequestRepository stores requests with gorm, on any of the databases
+// of db.Open.
+type GormRequestRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormRequestRepository(db *gorm.DB) *GormRequestRepository {
+	return &GormRequestRepository{DB: db}
+}
+
+func (r *GormRequestRepository) FindAccessible(userID string, requestID string) (*models.Request, error) {
+	var request models.Request
+	err := r.DB.Scopes(collectionRepositories.CollectionAccessibleBy(userID)).Where("id = ?", requestID).First(&request).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *GormRequestRepository) FindAccessibleWithCollection(userID string, requestID string) (*models.Request, error) {
+	var request models.Request
+	err := r.DB.Scopes(collectionRepositories.CollectionAccessibleBy(userID)).Where("id = ?", requestID).Preload("Collection").First(&request).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *GormRequestRepository) FindByCollectionID(collectionID string) ([]*models.Request, error) {
+	var requests []*models.Request
+	err := r.DB.Where("collection_id = ?", collectionID).Order("created_at").Find(&requests).Error
+	return requests, err
+}
+
+func (r *GormRequestRepository) FindByCollectionIDWithCollection(collectionID string) ([]*models.Request, error) {
+	var requests []*models.Request
+	err := r.DB.Where("collection_id = ?", collectionID).Order("created_at").Preload("Collection").Find(&requests).Error
+	return requests, err
+}
+
+func (r *GormRequestRepository) Create(request *models.Request) error {
+	return r.DB.Create(request).Error
+}
+
+func (r *GormRequestRepository) CreateWithCollection(collection *collectionModels.Collection, requests []*models.Request) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(collection).Error; err != nil {
+			return err
+		}
+
+		for _, request := range requests {
+			request.CollectionID = collection.ID
+			if err := tx.Omit("Collection").Create(request).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *GormRequestRepository) Save(request *models.Request) error {
+	return r.DB.Save(request).Error
+}
+
+func (r *GormRequestRepository) Delete(request *models.Request) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("request_id = ?", request.ID).Delete(&mockModels.MockExample{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(request).Error
+	})
+}
+
+func (r *GormRequestRepository) DeleteByCollections(collectionIDs []string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		requestIDs := tx.Unscoped().Model(&models.Request{}).Select("id").Where("collection_id IN ?", collectionIDs)
+		if err := tx.Unscoped().Where("request_id IN (?)", requestIDs).Delete(&mockModels.MockExample{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("collection_id IN ?", collectionIDs).Delete(&models.Request{}).Error
+	})
+}
@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	"github.com/jeksilaen/api-builder/modules/request/models"
+)
+
+// RequestRepository stores the requests of collections. Lookups of a
+// missing record fail with db.ErrNotFound.
+type RequestRepository interface {
+	// FindAccessible returns the request if its collection is in a
+	// workspace of userID
+	FindAccessible(userID string, requestID string) (*models.Request, error)
+	// FindAccessibleWithCollection is FindAccessible with the collection of
+	// the request loaded
+	FindAccessibleWithCollection(userID string, requestID string) (*models.Request, error)
+	// FindByCollectionID returns the requests of a collection in creation
+	// order
+	FindByCollectionID(collectionID string) ([]*models.Request, error)
+	// FindByCollectionIDWithCollection is FindByCollectionID with the
+	// collection of the requests loaded
+	FindByCollectionIDWithCollection(collectionID string) ([]*models.Request, error)
+	Create(request *models.Request) error
+	// CreateWithCollection creates a collection and its requests at once
+	CreateWithCollection(collection *collectionModels.Collection, requests []*models.Request) error
+	Save(request *models.Request) error
+	// Delete deletes the request with its mock examples
+	Delete(request *models.Request) error
+	// DeleteByCollections deletes for good the requests of the collections,
+	// soft deleted ones too, with their mock examples
+	DeleteByCollections(collectionIDs []string) error
+}
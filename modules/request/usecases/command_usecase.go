@@ -1,348 +1,177 @@
 package usecases
 
 import (
+	"context"
 	"errors"
-	"log"
-	"strings"	
-	"encoding/json"
+	"log/slog"
 	"net/http"
-	"bytes"
-	"strconv"
-	// "encoding/json"
+	"time"
 	"github.com/jeksilaen/api-builder/db"
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	collectionUsecases "github.com/jeksilaen/api-builder/modules/collection/usecases"
+	workspaceUsecases "github.com/jeksilaen/api-builder/modules/workspace/usecases"
 	"github.com/jeksilaen/api-builder/modules/request/models"	
-	"gorm.io/gorm"
+	"github.com/jeksilaen/api-builder/modules/request/repositories"
 )
 
+// ErrRequestNotFound is returned for requests that don't exist or belong to
+// a collection of another user.
+var ErrRequestNotFound = errors.New("Request not found")
+
+// RequestCommandUsecase manages the requests of the collections and sends
+// them with Client. RunTimeout bounds the collection runs users start, zero
+// leaving them unbounded.
 type RequestCommandUsecase struct {
-	DB *gorm.DB
+	Repo        repositories.RequestRepository
+	Collections *collectionUsecases.CollectionCommandUsecase
+	Client      *http.Client
+	RunTimeout  time.Duration
 }
 
-func NewRequestCommandUsecase() *RequestCommandUsecase {
+func NewRequestCommandUsecase(repo repositories.RequestRepository, collections *collectionUsecases.CollectionCommandUsecase, client *http.Client, runTimeout time.Duration) *RequestCommandUsecase {
 	return &RequestCommandUsecase{
-		DB: db.GetDB(),
+		Repo:        repo,
+		Collections: collections,
+		Client:      client,
+		RunTimeout:  runTimeout,
 	}
 }
 
-func (uc *RequestCommandUsecase) GetRequestByRequestID(requestID string) (*models.Request, error) {
-	var request models.Request
-	result := uc.DB.Where("id = ?", requestID).Preload("Collection").First(&request)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			return nil, errors.New("Request not found")
+func (uc *RequestCommandUsecase) GetRequestByRequestID(userID string, requestID string) (*models.Request, error) {
+	request, err := uc.Repo.FindAccessibleWithCollection(userID, requestID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrRequestNotFound
 		}
-		return nil, result.Error
+		return nil, err
 	}
 
-	return &request, nil
+	return request, nil
 }
 
-func (uc *RequestCommandUsecase) GetRequestByCollectionID(collectionID string) ([]*models.Request, error) {
-	var request []*models.Request
-	result := uc.DB.Where("collection_id = ?", collectionID).Preload("Collection").Find(&request)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			return nil, errors.New("Request not found")
-		}
-		return nil, result.Error
+func (uc *RequestCommandUsecase) GetRequestByCollectionID(userID string, collectionID string) ([]*models.Request, error) {
+	if _, err := uc.getOwnedCollection(userID, collectionID); err != nil {
+		return nil, ErrRequestNotFound
+	}
+
+	request, err := uc.Repo.FindByCollectionIDWithCollection(collectionID)
+	if err != nil {
+		return nil, err
 	}
 
 	return request, nil
 }
 
-func (uc *RequestCommandUsecase) CreateRequest(request *models.Request) (*models.Request, error) {
-	
-	if request.Method == "GET" {
-		req, err := http.NewRequest("GET", request.URL, nil)		
-
-		// Set the authorization header
-		req.Header.Set("Authorization", "Bearer "+request.BearerToken)
-
-		// Make the HTTP request
-		response, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer response.Body.Close()
-
-		if response.StatusCode == http.StatusOK {
-			var responseData models.JSONMap
-			err := json.NewDecoder(response.Body).Decode(&responseData)
-			if err != nil {
-				return nil, err
-			}
-			request.Response = responseData
-		} else {
-			errorResponse := make(models.JSONMap)
-			errorResponse["error"] = "Invalid status code: " + strconv.Itoa(response.StatusCode)
-			request.Response = errorResponse
-		}
-	} else if request.Method == "POST"{		
-		payloadJSON, _ := json.Marshal(request.Payload)
-			
-		req, err := http.NewRequest("POST", request.URL, bytes.NewBuffer(payloadJSON))
-		if err != nil {
-			return nil, err
-		}
-
-		// Set the authorization header
-		req.Header.Set("Authorization", "Bearer "+request.BearerToken)
-		req.Header.Set("Content-Type", "application/json")
-
-		response, err := http.DefaultClient.Do(req)
-		if err != nil {			
-			errorResponse := make(models.JSONMap)
-			errorResponse["error"] = "Failed to fetch URL: " + err.Error()
-				
-			request.Response = errorResponse
-		} else {
-			if response.StatusCode == http.StatusOK {				
-				var responseData models.JSONMap
-				err := json.NewDecoder(response.Body).Decode(&responseData)
-				if err != nil {					
-					errorResponse := make(models.JSONMap)
-					errorResponse["error"] = "Failed to parse JSON: " + err.Error()
-						
-					request.Response = errorResponse
-				} else {					
-					request.Response = responseData
-				}
-			} else {				
-				var responseData models.JSONMap
-				json.NewDecoder(response.Body).Decode(&responseData)
-				request.Response = responseData
-				if request.Response == nil {
-					errorResponse := make(models.JSONMap)
-					errorResponse["error"] = "Invalid status code: " + strconv.Itoa(response.StatusCode)
-					request.Response = errorResponse
-				}
-			}
-		}
-
-	}else if request.Method == "PUT" || request.Method == "PATCH" {		
-		payloadJSON, _ := json.Marshal(request.Payload)		
-	
-		req, _ := http.NewRequest(request.Method, request.URL, bytes.NewBuffer(payloadJSON))
-		req.Header.Set("Authorization", "Bearer "+request.BearerToken)
-		req.Header.Set("Content-Type", "application/json")
-		
-		client := &http.Client{}
-		response, err := client.Do(req)
-	
-		if err != nil {			
-			errorResponse := make(models.JSONMap)
-			errorResponse["error"] = "Failed to fetch URL: " + err.Error()
-				
-			request.Response = errorResponse
-		} else {
-			var responseData models.JSONMap
-			
-			json.NewDecoder(response.Body).Decode(&responseData)			
-			request.Response = responseData
-			if request.Response == nil {
-				errorResponse := make(models.JSONMap)
-				errorResponse["error"] = "Invalid status code: " + strconv.Itoa(response.StatusCode)
-				request.Response = errorResponse
-			}
-		}	
-
-	} else if request.Method == "DELETE" {		
-		req, err := http.NewRequest("DELETE", request.URL, nil)
-		
-		req.Header.Set("Authorization", "Bearer "+request.BearerToken)
-		client := http.DefaultClient
-		response, err := client.Do(req)
+func (uc *RequestCommandUsecase) CreateRequest(ctx context.Context, userID string, request *models.Request) (*models.Request, error) {
+	if _, err := uc.getOwnedCollection(userID, request.CollectionID); err != nil {
+		return nil, err
+	}
 
-		if err != nil {			
-			errorResponse := make(models.JSONMap)
-			errorResponse["error"] = "Failed to fetch URL: " + err.Error()
-				
-			request.Response = errorResponse
-		} else {
-			var responseData models.JSONMap
-			
-			json.NewDecoder(response.Body).Decode(&responseData)			
-			request.Response = responseData
-			if request.Response == nil {
-				errorResponse := make(models.JSONMap)
-				errorResponse["error"] = "Invalid status code: " + strconv.Itoa(response.StatusCode)
-				request.Response = errorResponse
-			}
-		}		
-	}  else {
-		return nil, errors.New("Invalid request method for updating, use GET, POST, PUT, or DELETE")
+	if err := uc.executeRequest(ctx, request); err != nil {
+		return nil, err
 	}
 	
-	err := uc.DB.Create(request).Error
+	err := uc.Repo.Create(request)
 	if err != nil {
-		if strings.Contains(err.Error(), "invalid input syntax for type uuid") {
+		if errors.Is(err, db.ErrInvalidReference) {
 			return nil, errors.New("User Id Not Found")
 		}
 
-		log.Println("Error creating user:", err)
+		slog.ErrorContext(ctx, "Error creating request", "error", err)
 		return nil, err
 	}
 
 	return request, nil
 }
 
-func (uc *RequestCommandUsecase) GetRequestByIDWithoutPreload(requestID string) (*models.Request, error) {
-    var request models.Request
-    result := uc.DB.Where("id = ?", requestID).First(&request)
-    if result.Error != nil {
-        if result.Error == gorm.ErrRecordNotFound {
-            return nil, errors.New("Request not found")
-        }
-        return nil, result.Error
-    }
+// ImportRequest stores a request parsed from an external format without
+// executing it.
+func (uc *RequestCommandUsecase) ImportRequest(ctx context.Context, userID string, request *models.Request) (*models.Request, error) {
+	if _, err := uc.getOwnedCollection(userID, request.CollectionID); err != nil {
+		return nil, err
+	}
 
-    return &request, nil
-}
+	err := uc.Repo.Create(request)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidReference) {
+			return nil, errors.New("Collection Id Not Found")
+		}
 
-func (uc *RequestCommandUsecase) UpdateRequest(request *models.Request) (*models.Request, error) {
-	
-	if request.Method == "GET" {
-		req, err := http.NewRequest("GET", request.URL, nil)		
+		slog.ErrorContext(ctx, "Error importing request", "error", err)
+		return nil, err
+	}
 
-		// Set the authorization header
-		req.Header.Set("Authorization", "Bearer "+request.BearerToken)
+	return request, nil
+}
 
-		// Make the HTTP request
-		response, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer response.Body.Close()
+// ImportCollection creates a new collection holding the given requests in a
+// single transaction, in a workspace of userID.
+func (uc *RequestCommandUsecase) ImportCollection(ctx context.Context, userID string, collection *collectionModels.Collection, requests []*models.Request) (*collectionModels.Collection, error) {
+	if !uc.Collections.IsWorkspaceMember(userID, collection.WorkspaceID) {
+		return nil, workspaceUsecases.ErrWorkspaceNotFound
+	}
 
-		if response.StatusCode == http.StatusOK {
-			var responseData models.JSONMap
-			err := json.NewDecoder(response.Body).Decode(&responseData)
-			if err != nil {
-				return nil, err
-			}
-			request.Response = responseData
-		} else {
-			errorResponse := make(models.JSONMap)
-			errorResponse["error"] = "Invalid status code: " + strconv.Itoa(response.StatusCode)
-			request.Response = errorResponse
-		}
-	} else if request.Method == "POST"{		
-		payloadJSON, _ := json.Marshal(request.Payload)
-			
-		req, err := http.NewRequest("POST", request.URL, bytes.NewBuffer(payloadJSON))
-		if err != nil {
-			return nil, err
+	err := uc.Repo.CreateWithCollection(collection, requests)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidReference) {
+			return nil, errors.New("User Id Not Found")
 		}
 
-		// Set the authorization header
-		req.Header.Set("Authorization", "Bearer "+request.BearerToken)
-		req.Header.Set("Content-Type", "application/json")
+		slog.ErrorContext(ctx, "Error importing collection", "error", err)
+		return nil, err
+	}
 
-		response, err := http.DefaultClient.Do(req)
-		if err != nil {			
-			errorResponse := make(models.JSONMap)
-			errorResponse["error"] = "Failed to fetch URL: " + err.Error()
-				
-			request.Response = errorResponse
-		} else {
-			if response.StatusCode == http.StatusOK {				
-				var responseData models.JSONMap
-				err := json.NewDecoder(response.Body).Decode(&responseData)
-				if err != nil {					
-					errorResponse := make(models.JSONMap)
-					errorResponse["error"] = "Failed to parse JSON: " + err.Error()
-						
-					request.Response = errorResponse
-				} else {					
-					request.Response = responseData
-				}
-			} else {				
-				var responseData models.JSONMap
-				json.NewDecoder(response.Body).Decode(&responseData)
-				request.Response = responseData
-				if request.Response == nil {
-					errorResponse := make(models.JSONMap)
-					errorResponse["error"] = "Invalid status code: " + strconv.Itoa(response.StatusCode)
-					request.Response = errorResponse
-				}
-			}
-		}
+	return collection, nil
+}
 
-	}else if request.Method == "PUT" || request.Method == "PATCH" {		
-		payloadJSON, _ := json.Marshal(request.Payload)		
-	
-		req, _ := http.NewRequest(request.Method, request.URL, bytes.NewBuffer(payloadJSON))
-		req.Header.Set("Authorization", "Bearer "+request.BearerToken)
-		req.Header.Set("Content-Type", "application/json")
-		
-		client := &http.Client{}
-		response, err := client.Do(req)
-	
-		if err != nil {			
-			errorResponse := make(models.JSONMap)
-			errorResponse["error"] = "Failed to fetch URL: " + err.Error()
-				
-			request.Response = errorResponse
-		} else {
-			var responseData models.JSONMap
-			
-			json.NewDecoder(response.Body).Decode(&responseData)			
-			request.Response = responseData
-			if request.Response == nil {
-				errorResponse := make(models.JSONMap)
-				errorResponse["error"] = "Invalid status code: " + strconv.Itoa(response.StatusCode)
-				request.Response = errorResponse
-			}
-		}	
+func (uc *RequestCommandUsecase) GetRequestByIDWithoutPreload(userID string, requestID string) (*models.Request, error) {
+    request, err := uc.Repo.FindAccessible(userID, requestID)
+    if err != nil {
+        if errors.Is(err, db.ErrNotFound) {
+            return nil, ErrRequestNotFound
+        }
+        return nil, err
+    }
 
-	} else if request.Method == "DELETE" {		
-		req, err := http.NewRequest("DELETE", request.URL, nil)
-		
-		req.Header.Set("Authorization", "Bearer "+request.BearerToken)
-		client := http.DefaultClient
-		response, err := client.Do(req)
+    return request, nil
+}
 
-		if err != nil {			
-			errorResponse := make(models.JSONMap)
-			errorResponse["error"] = "Failed to fetch URL: " + err.Error()
-				
-			request.Response = errorResponse
-		} else {
-			var responseData models.JSONMap
-			
-			json.NewDecoder(response.Body).Decode(&responseData)			
-			request.Response = responseData
-			if request.Response == nil {
-				errorResponse := make(models.JSONMap)
-				errorResponse["error"] = "Invalid status code: " + strconv.Itoa(response.StatusCode)
-				request.Response = errorResponse
-			}
-		}		
-	}  else {
-		return nil, errors.New("Invalid request method for updating, use GET, POST, PUT, or DELETE")
+func (uc *RequestCommandUsecase) UpdateRequest(ctx context.Context, request *models.Request) (*models.Request, error) {
+	if err := uc.executeRequest(ctx, request); err != nil {
+		return nil, err
 	}
 		
-	err := uc.DB.Save(request).Error
+	err := uc.Repo.Save(request)
 	if err != nil {
 		return nil, err
 	}
 	return request, nil
 }
 
-func (uc *RequestCommandUsecase) DeleteRequestByRequestID(requestID string) (*models.Request, error) {
-	var request models.Request
-	result := uc.DB.Where("id = ?", requestID).Preload("Collection").First(&request)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			return nil, errors.New("Request not found")
-		}
-		return nil, result.Error
+// SaveRequest stores changes to a request without executing it.
+func (uc *RequestCommandUsecase) SaveRequest(request *models.Request) (*models.Request, error) {
+	err := uc.Repo.Save(request)
+	if err != nil {
+		return nil, err
 	}
+	return request, nil
+}
 
-	// Delete the request from the database
-	if err := uc.DB.Delete(&request).Error; err != nil {
+func (uc *RequestCommandUsecase) DeleteRequestByRequestID(userID string, requestID string) (*models.Request, error) {
+	request, err := uc.GetRequestByRequestID(userID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Delete the request with its mock examples
+	if err := uc.Repo.Delete(request); err != nil {
 		return nil, err
 	}
 
-	return &request, nil
-}
\ No newline at end of file
+	return request, nil
+}
+
+// getOwnedCollection returns the collection if it belongs to userID.
+func (uc *RequestCommandUsecase) getOwnedCollection(userID string, collectionID string) (*collectionModels.Collection, error) {
+	return uc.Collections.GetCollectionByIDWithoutPreload(userID, collectionID)
+}
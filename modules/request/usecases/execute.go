@@ -0,0 +1,185 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jeksilaen/api-builder/logging"
+	"github.com/jeksilaen/api-builder/modules/request/models"
+)
+
+// doRequest sends req with the client of the usecase and logs it, with its
+// secret headers and query parameters redacted.
+func (uc *RequestCommandUsecase) doRequest(req *http.Request) (*http.Response, error) {
+	startedAt := time.Now()
+	response, err := uc.Client.Do(req)
+
+	attrs := []any{
+		"method", req.Method,
+		"url", logging.URL(req.URL),
+		logging.Headers("headers", req.Header),
+		"duration_ms", time.Since(startedAt).Milliseconds(),
+	}
+	if err != nil {
+		slog.DebugContext(req.Context(), "Executed request failed", append(attrs, "error", logging.Error(err))...)
+		return nil, err
+	}
+	slog.DebugContext(req.Context(), "Executed request", append(attrs, "status", response.StatusCode)...)
+	return response, nil
+}
+
+// methodsWithoutBody are the methods sent without the saved body.
+var methodsWithoutBody = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// executeRequest sends the saved request to its URL with its method and
+// stores the decoded body in request.Response. A request that gets no
+// response is recorded with the error in place of a body.
+func (uc *RequestCommandUsecase) executeRequest(ctx context.Context, request *models.Request) error {
+	var body io.Reader
+	contentType := ""
+	if !methodsWithoutBody[request.Method] {
+		var err error
+		body, contentType, err = encodeRequestBody(request)
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, request.Method, request.URL, body)
+	if err != nil {
+		return err
+	}
+
+	// Set the request headers
+	setRequestHeaders(req, request)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	startedAt := time.Now()
+	response, err := uc.doRequest(req)
+	if err != nil {
+		request.Response = models.JSONMap{"error": "Failed to fetch URL: " + err.Error()}
+		recordResponse(request, nil, startedAt)
+		return nil
+	}
+	defer response.Body.Close()
+	recordResponse(request, response, startedAt)
+
+	succeeded := response.StatusCode >= 200 && response.StatusCode < 300
+	var responseData models.JSONMap
+	err = json.NewDecoder(response.Body).Decode(&responseData)
+	switch {
+	case err == nil:
+		request.Response = responseData
+	case errors.Is(err, io.EOF) && succeeded:
+		// HEAD, 204 and the like have no body to decode
+		request.Response = models.JSONMap{}
+	case succeeded:
+		request.Response = models.JSONMap{"error": "Failed to parse JSON: " + err.Error()}
+	default:
+		request.Response = models.JSONMap{"error": "Request failed with status " + strconv.Itoa(response.StatusCode)}
+	}
+
+	return nil
+}
+
+// recordResponse stores the status, headers and timing of the latest run.
+// A nil response records a run that failed before any response arrived.
+func recordResponse(request *models.Request, response *http.Response, startedAt time.Time) {
+	request.ResponseStatus = 0
+	request.ResponseHeaders = nil
+	request.RawResponse = ""
+	request.ResponseTime = time.Since(startedAt).Milliseconds()
+	request.LastRunAt = &startedAt
+
+	if response == nil {
+		return
+	}
+
+	request.ResponseStatus = response.StatusCode
+	request.ResponseHeaders = make(models.JSONMap)
+	for key := range response.Header {
+		request.ResponseHeaders[key] = response.Header.Get(key)
+	}
+}
+
+// setRequestHeaders copies the saved headers onto req. The BearerToken field
+// wins over any Authorization header stored alongside it.
+func setRequestHeaders(req *http.Request, request *models.Request) {
+	for key, value := range request.Headers {
+		req.Header.Set(key, fmt.Sprint(value))
+	}
+
+	if request.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+request.BearerToken)
+	}
+}
+
+// encodeRequestBody builds the outgoing body from RawBody or Payload and
+// returns the Content-Type that should be sent with it.
+func encodeRequestBody(request *models.Request) (io.Reader, string, error) {
+	contentType := headerValue(request.Headers, "Content-Type")
+
+	if request.RawBody != "" {
+		return strings.NewReader(request.RawBody), contentType, nil
+	}
+
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		form := url.Values{}
+		for key, value := range request.Payload {
+			form.Set(key, fmt.Sprint(value))
+		}
+		return strings.NewReader(form.Encode()), contentType, nil
+	}
+
+	if strings.HasPrefix(contentType, "multipart/form-data") {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		for key, value := range request.Payload {
+			if err := writer.WriteField(key, fmt.Sprint(value)); err != nil {
+				return nil, "", err
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return nil, "", err
+		}
+		return &buf, writer.FormDataContentType(), nil
+	}
+
+	payloadJSON, err := json.Marshal(request.Payload)
+	if err != nil {
+		return nil, "", err
+	}
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	return bytes.NewBuffer(payloadJSON), contentType, nil
+}
+
+// headerValue looks up a header in a JSONMap without regard to case.
+func headerValue(headers models.JSONMap, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return fmt.Sprint(value)
+		}
+	}
+	return ""
+}
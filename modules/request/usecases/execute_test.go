@@ -0,0 +1,108 @@
+package usecases
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jeksilaen/api-builder/modules/request/models"
+)
+
+func TestExecuteRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/echo":
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"method":"`+r.Method+`"}`)
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+		case "/text":
+			io.WriteString(w, "plain text")
+		default:
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	uc := &RequestCommandUsecase{Client: server.Client()}
+	tests := []struct {
+		method string
+		path   string
+		key    string
+		want   interface{}
+	}{
+		{"PATCH", "/echo", "method", "PATCH"},
+		{"OPTIONS", "/echo", "method", "OPTIONS"},
+		{"HEAD", "/empty", "", nil},
+		{"GET", "/text", "error", "Failed to parse JSON: invalid character 'p' looking for beginning of value"},
+		{"POST", "/fail", "error", "Request failed with status 500"},
+	}
+
+	for _, test := range tests {
+		request := &models.Request{
+			Method:  test.method,
+			URL:     server.URL + test.path,
+			Headers: models.JSONMap{"X-Token": "abc"},
+			RawBody: `{"name":"alice"}`,
+		}
+		if err := uc.executeRequest(context.Background(), request); err != nil {
+			t.Errorf("%s %s: %v", test.method, test.path, err)
+			continue
+		}
+		if test.key == "" {
+			if len(request.Response) != 0 {
+				t.Errorf("%s %s: Response = %v, want {}", test.method, test.path, request.Response)
+			}
+			continue
+		}
+		if request.Response[test.key] != test.want {
+			t.Errorf("%s %s: Response = %v, want %s %v", test.method, test.path, request.Response, test.key, test.want)
+		}
+	}
+}
+
+func TestExecuteRequestSendsBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"body":`+string(body)+`,"token":"`+r.Header.Get("X-Token")+`"}`)
+	}))
+	defer server.Close()
+
+	request := &models.Request{
+		Method:  "PUT",
+		URL:     server.URL,
+		Headers: models.JSONMap{"X-Token": "abc"},
+		RawBody: `{"name":"alice"}`,
+	}
+	if err := (&RequestCommandUsecase{Client: server.Client()}).executeRequest(context.Background(), request); err != nil {
+		t.Fatal(err)
+	}
+
+	if request.ResponseStatus != http.StatusOK || request.ResponseHeaders["Content-Type"] != "application/json" {
+		t.Errorf("response recorded as %d %v", request.ResponseStatus, request.ResponseHeaders)
+	}
+
+	body, _ := request.Response["body"].(map[string]interface{})
+	if body["name"] != "alice" || request.Response["token"] != "abc" {
+		t.Errorf("server received %v", request.Response)
+	}
+}
+
+func TestExecuteRequestRecordsFetchErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	request := &models.Request{Method: "GET", URL: server.URL}
+	if err := (&RequestCommandUsecase{Client: server.Client()}).executeRequest(context.Background(), request); err != nil {
+		t.Fatalf("executeRequest: %v", err)
+	}
+	if message, _ := request.Response["error"].(string); message == "" {
+		t.Errorf("Response = %v, want the fetch error", request.Response)
+	}
+	if request.ResponseStatus != 0 || request.LastRunAt == nil {
+		t.Errorf("run recorded with status %d at %v", request.ResponseStatus, request.LastRunAt)
+	}
+}
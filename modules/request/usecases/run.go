@@ -0,0 +1,145 @@
+package usecases
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/jeksilaen/api-builder/modules/request/models"
+)
+
+// variablePattern matches {{name}} placeholders, allowing inner spaces.
+var variablePattern = regexp.MustCompile(`\{\{\s*([\w.-]+)\s*\}\}`)
+
+// RunCollection executes every request of a collection owned by userID in
+// creation order with the given variables substituted. The saved requests
+// are left as they are; the outcome is only returned in the report. The
+// requests still to send after RunTimeout fail, so the report is answered
+// before the server's write timeout.
+func (uc *RequestCommandUsecase) RunCollection(ctx context.Context, userID string, collectionID string, variables map[string]string) (*models.RunReport, error) {
+	if _, err := uc.getOwnedCollection(userID, collectionID); err != nil {
+		return nil, err
+	}
+
+	if uc.RunTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, uc.RunTimeout)
+		defer cancel()
+	}
+
+	return uc.RunCollectionByID(ctx, collectionID, variables)
+}
+
+// RunCollectionByID runs a collection like RunCollection whatever workspace
+// it is in, for the runs the server starts itself such as monitors.
+func (uc *RequestCommandUsecase) RunCollectionByID(ctx context.Context, collectionID string, variables map[string]string) (*models.RunReport, error) {
+	requests, err := uc.Repo.FindByCollectionID(collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.RunReport{
+		CollectionID: collectionID,
+		StartedAt:    time.Now(),
+		Results:      models.RunResults{},
+	}
+
+	for _, request := range requests {
+		runRequest := applyVariables(request, variables)
+
+		runResult := models.RunResult{
+			RequestID: request.ID,
+			Name:      request.Name,
+			Method:    request.Method,
+			URL:       request.URL,
+		}
+
+		if err := ctx.Err(); err != nil {
+			runResult.Error = "not sent: " + err.Error()
+		} else if err := uc.executeRequest(ctx, runRequest); err != nil {
+			runResult.Error = err.Error()
+		} else {
+			runResult.Status = runRequest.ResponseStatus
+			runResult.ResponseTime = runRequest.ResponseTime
+			if runRequest.ResponseStatus == 0 {
+				runResult.Error = "no response received"
+				if fetchErr, ok := runRequest.Response["error"].(string); ok {
+					runResult.Error = fetchErr
+				}
+			}
+		}
+		runResult.Passed = runResult.Status >= 200 && runResult.Status < 300
+
+		if runResult.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.Results = append(report.Results, runResult)
+	}
+
+	report.Total = len(report.Results)
+	report.Duration = time.Since(report.StartedAt).Milliseconds()
+
+	return report, nil
+}
+
+// applyVariables returns a copy of request with {{name}} placeholders in the
+// URL, token, headers and body replaced. Unknown placeholders are kept.
+func applyVariables(request *models.Request, variables map[string]string) *models.Request {
+	runRequest := *request
+	if len(variables) == 0 {
+		return &runRequest
+	}
+
+	replace := func(text string) string {
+		return variablePattern.ReplaceAllStringFunc(text, func(match string) string {
+			name := variablePattern.FindStringSubmatch(match)[1]
+			if value, ok := variables[name]; ok {
+				return value
+			}
+			return match
+		})
+	}
+
+	runRequest.URL = replace(request.URL)
+	runRequest.BearerToken = replace(request.BearerToken)
+	runRequest.RawBody = replace(request.RawBody)
+
+	if request.Headers != nil {
+		runRequest.Headers = models.JSONMap{}
+		for key, value := range request.Headers {
+			runRequest.Headers[key] = replaceValue(value, replace)
+		}
+	}
+
+	if request.Payload != nil {
+		runRequest.Payload = replaceValue(map[string]interface{}(request.Payload), replace).(map[string]interface{})
+	}
+
+	return &runRequest
+}
+
+// replaceValue applies replace to every string inside a decoded JSON value.
+func replaceValue(value interface{}, replace func(string) string) interface{} {
+	switch typed := value.(type) {
+	case string:
+		return replace(typed)
+	case map[string]interface{}:
+		replaced := make(map[string]interface{}, len(typed))
+		for key, inner := range typed {
+			replaced[key] = replaceValue(inner, replace)
+		}
+		return replaced
+	case models.JSONMap:
+		return replaceValue(map[string]interface{}(typed), replace)
+	case []interface{}:
+		replaced := make([]interface{}, len(typed))
+		for i, inner := range typed {
+			replaced[i] = replaceValue(inner, replace)
+		}
+		return replaced
+	default:
+		return value
+	}
+}
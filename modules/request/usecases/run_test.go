@@ -0,0 +1,67 @@
+package usecases
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jeksilaen/api-builder/db/dbtest"
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	collectionRepositories "github.com/jeksilaen/api-builder/modules/collection/repositories"
+	collectionUsecases "github.com/jeksilaen/api-builder/modules/collection/usecases"
+	"github.com/jeksilaen/api-builder/modules/request/models"
+	"github.com/jeksilaen/api-builder/modules/request/repositories"
+	userModels "github.com/jeksilaen/api-builder/modules/user/models"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+)
+
+func TestRunCollectionStopsAtRunTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	gormDB := dbtest.Open(t)
+	create := func(record interface{}) {
+		if err := gormDB.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	user := &userModels.User{Email: "alice@example.com", Username: "alice", Password: "hash"}
+	create(user)
+	workspace := &workspaceModels.Workspace{Name: "Alice", OwnerID: user.ID, Personal: true}
+	create(workspace)
+	create(&workspaceModels.WorkspaceMember{WorkspaceID: workspace.ID, UserID: user.ID, Role: workspaceModels.RoleOwner})
+	collection := &collectionModels.Collection{UserID: user.ID, WorkspaceID: workspace.ID, Name: "Slow"}
+	create(collection)
+	for _, name := range []string{"First", "Second", "Third"} {
+		create(&models.Request{ID: uuid.New().String(), CollectionID: collection.ID, Name: name, Method: "GET", URL: server.URL})
+	}
+
+	collections := collectionUsecases.NewCollectionCommandUsecase(collectionRepositories.NewGormCollectionRepository(gormDB))
+	uc := NewRequestCommandUsecase(repositories.NewGormRequestRepository(gormDB), collections, server.Client(), 100*time.Millisecond)
+
+	startedAt := time.Now()
+	report, err := uc.RunCollection(context.Background(), user.ID, collection.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(startedAt); elapsed > 500*time.Millisecond {
+		t.Errorf("run took %s, want it stopped at the run timeout", elapsed)
+	}
+	if report.Total != 3 || report.Failed != 3 {
+		t.Fatalf("report = %+v, want 3 failed requests", report)
+	}
+	for _, runResult := range report.Results[1:] {
+		if !strings.HasPrefix(runResult.Error, "not sent") {
+			t.Errorf("%s: error = %q, want it not sent", runResult.Name, runResult.Error)
+		}
+	}
+}
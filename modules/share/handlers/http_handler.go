@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/jeksilaen/api-builder/middlewares"
+	collectionUsecases "github.com/jeksilaen/api-builder/modules/collection/usecases"
+	"github.com/jeksilaen/api-builder/modules/share/helpers"
+	"github.com/jeksilaen/api-builder/modules/share/models"
+	"github.com/jeksilaen/api-builder/modules/share/usecases"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+)
+
+// ShareHttpHandler serves the share routes with the usecases it is given.
+type ShareHttpHandler struct {
+	Shares *usecases.ShareCommandUsecase
+}
+
+func NewShareHttpHandler(shares *usecases.ShareCommandUsecase) *ShareHttpHandler {
+	return &ShareHttpHandler{
+		Shares: shares,
+	}
+}
+
+func InitShareHttpHandler(router *gin.Engine, auth *middlewares.Auth, h *ShareHttpHandler) {
+	router.GET("/public/collections/:token", h.GetSharedCollection)
+
+	router.GET("/users/v1/share_link_by_collection/:collection_id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermManage, auth.WorkspaceOfCollection("collection_id")), h.GetShareLinksByCollection)
+	router.POST("/users/v1/share_link", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermManage, auth.WorkspaceOfBodyCollection("collection_id")), h.CreateShareLink)
+	router.DELETE("/users/v1/share_link/:id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermManage, auth.WorkspaceOfRecord("share_links", "id")), h.RevokeShareLink)
+}
+
+func (h *ShareHttpHandler) GetSharedCollection(ctx *gin.Context) {
+
+	token := ctx.Param("token")
+
+	if token == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Token is required"})
+		return
+	}
+
+	// Get the shared collection through an active link
+	link, requests, environments, err := h.Shares.GetSharedCollection(ctx.Request.Context(), token)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Shared collection not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessPublicResponse(link, requests, environments))
+}
+
+func (h *ShareHttpHandler) GetShareLinksByCollection(ctx *gin.Context) {
+
+	collectionID := ctx.Param("collection_id")
+
+	if collectionID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Collection ID is required"})
+		return
+	}
+
+	// Get the share links from usecase
+	links, err := h.Shares.GetShareLinksByCollectionID(middlewares.GetUserID(ctx), collectionID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Share links not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessGetResponse(links))
+}
+
+func (h *ShareHttpHandler) CreateShareLink(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into ShareLinkRequest object
+	var req models.ShareLinkRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	link := &models.ShareLink{
+		CollectionID: req.CollectionID,
+		Name:         req.Name,
+		ExpiresAt:    req.ExpiresAt,
+	}
+
+	// Create the share link
+	createdLink, token, err := h.Shares.CreateShareLink(ctx.Request.Context(), middlewares.GetUserID(ctx), link)
+	if errors.Is(err, collectionUsecases.ErrCollectionNotFound) {
+		ctx.JSON(http.StatusNotFound, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, helpers.ReturnSucessTokenResponse(createdLink, token))
+}
+
+func (h *ShareHttpHandler) RevokeShareLink(ctx *gin.Context) {
+
+	linkID := ctx.Param("id")
+
+	if linkID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Share link ID is required"})
+		return
+	}
+
+	// Revoke the share link
+	if _, err := h.Shares.RevokeShareLink(middlewares.GetUserID(ctx), linkID); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Share link not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessDeleteResponse("Revoked Share Link Successfully"))
+}
@@ -0,0 +1,133 @@
+package helpers
+
+import (
+	environmentModels "github.com/jeksilaen/api-builder/modules/environment/models"
+	requestHelpers "github.com/jeksilaen/api-builder/modules/request/helpers"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+	"github.com/jeksilaen/api-builder/modules/share/models"
+)
+
+func ReturnFailedCreateResponse(message string) *models.FailedResponse {
+	return &models.FailedResponse{
+		Error:   "Create failed",
+		Message: message,
+		Links: []models.Link{
+			{
+				Rel:  "create share link",
+				Href: "/users/v1/share_link",
+			},
+		},
+	}
+}
+
+func toShareLinkResponse(link *models.ShareLink) models.ShareLinkResponse {
+	return models.ShareLinkResponse{
+		ID:           link.ID,
+		CollectionID: link.CollectionID,
+		Name:         link.Name,
+		TokenPrefix:  link.TokenPrefix,
+		ExpiresAt:    link.ExpiresAt,
+		RevokedAt:    link.RevokedAt,
+		Views:        link.Views,
+		LastViewedAt: link.LastViewedAt,
+		CreatedAt:    link.CreatedAt,
+	}
+}
+
+// ReturnSucessTokenResponse includes the token and public URL of a link,
+// which are only shown when it is created.
+func ReturnSucessTokenResponse(link *models.ShareLink, token string) *models.SucessCreateResponse {
+	response := ReturnSucessCreateResponse(link)
+	response.Data.Token = token
+	response.Data.URL = "/public/collections/" + token
+	return response
+}
+
+func ReturnSucessCreateResponse(link *models.ShareLink) *models.SucessCreateResponse {
+	return &models.SucessCreateResponse{
+		Message: "Save Share Link sucessfully",
+		Data:    toShareLinkResponse(link),
+		Links: []models.Link{
+			{
+				Rel:  "get share links",
+				Href: "/users/v1/share_link_by_collection/" + link.CollectionID,
+			},
+			{
+				Rel:  "revoke share link",
+				Href: "/users/v1/share_link/" + link.ID,
+			},
+		},
+	}
+}
+
+func ReturnSucessGetResponse(links []*models.ShareLink) *models.SucessGetResponse {
+	linkResponses := []models.ShareLinkResponse{}
+	for _, link := range links {
+		linkResponses = append(linkResponses, toShareLinkResponse(link))
+	}
+
+	return &models.SucessGetResponse{
+		Message: "Get Share Links sucessfully",
+		Data:    linkResponses,
+		Links: []models.Link{
+			{
+				Rel:  "create share link",
+				Href: "/users/v1/share_link",
+			},
+		},
+	}
+}
+
+// ReturnSucessPublicResponse builds the sanitized view of a shared
+// collection. Bearer tokens, credential headers and secret variables are
+// left out.
+func ReturnSucessPublicResponse(link *models.ShareLink, requests []*requestModels.Request, environments []*environmentModels.Environment) *models.SucessPublicResponse {
+	publicCollection := models.PublicCollection{
+		Name:         link.Collection.Name,
+		Requests:     []models.PublicRequest{},
+		Environments: []models.PublicEnvironment{},
+		ExpiresAt:    link.ExpiresAt,
+	}
+
+	for _, request := range requests {
+		headers := requestModels.JSONMap{}
+		for name, value := range request.Headers {
+			if !requestHelpers.IsSensitiveHeader(name) {
+				headers[name] = value
+			}
+		}
+
+		publicCollection.Requests = append(publicCollection.Requests, models.PublicRequest{
+			Name:    request.Name,
+			Method:  request.Method,
+			URL:     request.URL,
+			Headers: headers,
+			Payload: request.Payload,
+			RawBody: request.RawBody,
+		})
+	}
+
+	for _, environment := range environments {
+		publicCollection.Environments = append(publicCollection.Environments, models.PublicEnvironment{
+			Name:      environment.Name,
+			Variables: environment.Variables,
+		})
+	}
+
+	return &models.SucessPublicResponse{
+		Message: "Get Shared Collection sucessfully",
+		Data:    publicCollection,
+	}
+}
+
+func ReturnSucessDeleteResponse(message string) *models.SucessDeleteResponse {
+	return &models.SucessDeleteResponse{
+		Message: message,
+		Links: []models.Link{
+			{
+				Rel:  "create share link",
+				Href: "/users/v1/share_link",
+			},
+		},
+	}
+}
@@ -0,0 +1,33 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// tokenPrefixLength is how much of a token is kept to tell links apart.
+const tokenPrefixLength = 12
+
+// NewToken returns a random share token.
+func NewToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "shr_" + hex.EncodeToString(b), nil
+}
+
+// HashToken returns the hash under which a token is stored.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// TokenPrefix returns the start of a token, which is safe to display.
+func TokenPrefix(token string) string {
+	if len(token) <= tokenPrefixLength {
+		return token
+	}
+	return token[:tokenPrefixLength]
+}
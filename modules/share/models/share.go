@@ -0,0 +1,108 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+	"gorm.io/gorm"
+)
+
+// ShareLink gives unauthenticated read access to a sanitized view of a
+// collection. Only the hash of its token is stored.
+type ShareLink struct {
+	gorm.Model
+	ID           string                      `gorm:"type:uuid;primaryKey"`
+	CollectionID string                      `json:"collection_id" gorm:"type:uuid;not null;index"`
+	Name         string                      `json:"name"`
+	TokenHash    string                      `json:"-" gorm:"not null;uniqueIndex"`
+	TokenPrefix  string                      `json:"token_prefix"`
+	CreatedBy    string                      `json:"created_by" gorm:"type:uuid"`
+	ExpiresAt    *time.Time                  `json:"expires_at"`
+	RevokedAt    *time.Time                  `json:"revoked_at"`
+	Views        int64                       `json:"views"`
+	LastViewedAt *time.Time                  `json:"last_viewed_at"`
+	Collection   collectionModels.Collection `gorm:"foreignKey:CollectionID" json:"-"`
+}
+
+type ShareLinkRequest struct {
+	CollectionID string     `json:"collection_id" validate:"required"`
+	Name         string     `json:"name"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+}
+
+type ShareLinkResponse struct {
+	ID           string     `json:"id"`
+	CollectionID string     `json:"collection_id"`
+	Name         string     `json:"name"`
+	TokenPrefix  string     `json:"token_prefix"`
+	Token        string     `json:"token,omitempty"`
+	URL          string     `json:"url,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	Views        int64      `json:"views"`
+	LastViewedAt *time.Time `json:"last_viewed_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// PublicCollection is the sanitized view of a shared collection. It has no
+// bearer tokens, credential headers or secret variables.
+type PublicCollection struct {
+	Name         string              `json:"name"`
+	Requests     []PublicRequest     `json:"requests"`
+	Environments []PublicEnvironment `json:"environments"`
+	ExpiresAt    *time.Time          `json:"expires_at,omitempty"`
+}
+
+type PublicRequest struct {
+	Name    string                `json:"name"`
+	Method  string                `json:"method"`
+	URL     string                `json:"url"`
+	Headers requestModels.JSONMap `json:"headers"`
+	Payload requestModels.JSONMap `json:"payload"`
+	RawBody string                `json:"raw_body"`
+}
+
+type PublicEnvironment struct {
+	Name      string                `json:"name"`
+	Variables requestModels.JSONMap `json:"variables"`
+}
+
+type SucessCreateResponse struct {
+	Message string            `json:"message"`
+	Data    ShareLinkResponse `json:"data"`
+	Links   []Link            `json:"links"`
+}
+
+type SucessGetResponse struct {
+	Message string              `json:"message"`
+	Data    []ShareLinkResponse `json:"data"`
+	Links   []Link              `json:"links"`
+}
+
+type SucessPublicResponse struct {
+	Message string           `json:"message"`
+	Data    PublicCollection `json:"data"`
+}
+
+type SucessDeleteResponse struct {
+	Message string `json:"message"`
+	Links   []Link `json:"links"`
+}
+
+type FailedResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+	Links   []Link `json:"links"`
+}
+
+type Link struct {
+	Rel  string `json:"rel"`
+	Href string `json:"href"`
+}
+
+func (link *ShareLink) BeforeCreate(tx *gorm.DB) error {
+	link.ID = uuid.New().String()
+	return nil
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"time"
+
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	collectionRepositories "github.com/jeksilaen/api-builder/modules/collection/repositories"
+	environmentModels "github.com/jeksilaen/api-builder/modules/environment/models"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+	"github.com/jeksilaen/api-builder/modules/share/models"
+	"gorm.io/gorm"
+)
+
+// GormShareRepository stores share links with gorm, on any of the databases
+// of db.Open.
+type GormShareRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormShareRepository(db *gorm.DB) *GormShareRepository {
+	return &GormShareRepository{DB: db}
+}
+
+func (r *GormShareRepository) FindAccessibleByCollectionID(userID string, collectionID string) ([]*models.ShareLink, error) {
+	var links []*models.ShareLink
+	err := r.DB.Scopes(collectionRepositories.CollectionAccessibleBy(userID)).Where("collection_id = ?", collectionID).Order("created_at desc").Find(&links).Error
+	return links, err
+}
+
+func (r *GormShareRepository) FindAccessible(userID string, linkID string) (*models.ShareLink, error) {
+	var link models.ShareLink
+	err := r.DB.Scopes(collectionRepositories.CollectionAccessibleBy(userID)).Where("id = ?", linkID).First(&link).Error
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *GormShareRepository) FindUnrevokedByTokenHash(tokenHash string) (*models.ShareLink, error) {
+	var link models.ShareLink
+	if err := r.DB.Where("token_hash = ? AND revoked_at IS NULL", tokenHash).First(&link).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *GormShareRepository) Create(link *models.ShareLink) error {
+	return r.DB.Omit("Collection").Create(link).Error
+}
+
+func (r *GormShareRepository) Revoke(link *models.ShareLink) error {
+	return r.DB.Model(link).Update("revoked_at", link.RevokedAt).Error
+}
+
+func (r *GormShareRepository) RevokeByCreator(userID string) error {
+	return r.DB.Model(&models.ShareLink{}).
+		Where("created_by = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *GormShareRepository) DeleteByCollections(collectionIDs []string) error {
+	return r.DB.Unscoped().Where("collection_id IN ?", collectionIDs).Delete(&models.ShareLink{}).Error
+}
+
+func (r *GormShareRepository) CountView(link *models.ShareLink) error {
+	// Leave the updated_at of the link alone
+	return r.DB.Model(link).UpdateColumns(map[string]interface{}{
+		"views":          gorm.Expr("views + 1"),
+		"last_viewed_at": time.Now(),
+	}).Error
+}
+
+func (r *GormShareRepository) FindCollectionContent(collectionID string) (*collectionModels.Collection, []*requestModels.Request, []*environmentModels.Environment, error) {
+	var collection collectionModels.Collection
+	if err := r.DB.Where("id = ?", collectionID).First(&collection).Error; err != nil {
+		return nil, nil, nil, err
+	}
+
+	var requests []*requestModels.Request
+	if err := r.DB.Where("collection_id = ?", collectionID).Order("created_at").Find(&requests).Error; err != nil {
+		return nil, nil, nil, err
+	}
+
+	var environments []*environmentModels.Environment
+	if err := r.DB.Where("collection_id = ?", collectionID).Order("created_at").Find(&environments).Error; err != nil {
+		return nil, nil, nil, err
+	}
+
+	return &collection, requests, environments, nil
+}
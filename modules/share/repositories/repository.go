@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	environmentModels "github.com/jeksilaen/api-builder/modules/environment/models"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+	"github.com/jeksilaen/api-builder/modules/share/models"
+)
+
+// ShareRepository stores the share links of collections and reads what they
+// share. Lookups of a missing record fail with db.ErrNotFound.
+type ShareRepository interface {
+	// FindAccessibleByCollectionID returns the links of a collection in a
+	// workspace of userID, newest first
+	FindAccessibleByCollectionID(userID string, collectionID string) ([]*models.ShareLink, error)
+	// FindAccessible returns the link if its collection is in a workspace
+	// of userID
+	FindAccessible(userID string, linkID string) (*models.ShareLink, error)
+	// FindUnrevokedByTokenHash returns the link of a token unless revoked
+	FindUnrevokedByTokenHash(tokenHash string) (*models.ShareLink, error)
+	Create(link *models.ShareLink) error
+	// Revoke stores the RevokedAt of the link
+	Revoke(link *models.ShareLink) error
+	// RevokeByCreator revokes the links userID created
+	RevokeByCreator(userID string) error
+	// DeleteByCollections deletes for good the links of the collections
+	DeleteByCollections(collectionIDs []string) error
+	// CountView counts a view of the link
+	CountView(link *models.ShareLink) error
+	// FindCollectionContent returns a collection with its requests and
+	// environments in creation order
+	FindCollectionContent(collectionID string) (*collectionModels.Collection, []*requestModels.Request, []*environmentModels.Environment, error)
+}
@@ -0,0 +1,114 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jeksilaen/api-builder/db"
+	collectionUsecases "github.com/jeksilaen/api-builder/modules/collection/usecases"
+	environmentModels "github.com/jeksilaen/api-builder/modules/environment/models"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+	"github.com/jeksilaen/api-builder/modules/share/helpers"
+	"github.com/jeksilaen/api-builder/modules/share/models"
+	"github.com/jeksilaen/api-builder/modules/share/repositories"
+)
+
+// ErrShareLinkNotFound is returned for share links that don't exist, are
+// revoked or expired, or belong to a collection the user can't see.
+var ErrShareLinkNotFound = errors.New("Share link not found")
+
+type ShareCommandUsecase struct {
+	Repo        repositories.ShareRepository
+	Collections *collectionUsecases.CollectionCommandUsecase
+}
+
+func NewShareCommandUsecase(repo repositories.ShareRepository, collections *collectionUsecases.CollectionCommandUsecase) *ShareCommandUsecase {
+	return &ShareCommandUsecase{
+		Repo:        repo,
+		Collections: collections,
+	}
+}
+
+func (uc *ShareCommandUsecase) GetShareLinksByCollectionID(userID string, collectionID string) ([]*models.ShareLink, error) {
+	links, err := uc.Repo.FindAccessibleByCollectionID(userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// CreateShareLink generates the token of a new share link. The token is
+// returned once and only its hash is stored.
+func (uc *ShareCommandUsecase) CreateShareLink(ctx context.Context, userID string, link *models.ShareLink) (*models.ShareLink, string, error) {
+	if _, err := uc.Collections.GetCollectionByIDWithoutPreload(userID, link.CollectionID); err != nil {
+		return nil, "", err
+	}
+
+	if link.ExpiresAt != nil && !link.ExpiresAt.After(time.Now()) {
+		return nil, "", errors.New("expires_at must be in the future")
+	}
+
+	token, err := helpers.NewToken()
+	if err != nil {
+		return nil, "", err
+	}
+	link.TokenHash = helpers.HashToken(token)
+	link.TokenPrefix = helpers.TokenPrefix(token)
+	link.CreatedBy = userID
+
+	if err := uc.Repo.Create(link); err != nil {
+		slog.ErrorContext(ctx, "Error creating share link", "error", err)
+		return nil, "", err
+	}
+
+	return link, token, nil
+}
+
+// RevokeShareLink stops a share link from working. Revoked links are kept so
+// they still show up in the list of the collection.
+func (uc *ShareCommandUsecase) RevokeShareLink(userID string, linkID string) (*models.ShareLink, error) {
+	link, err := uc.Repo.FindAccessible(userID, linkID)
+	if err != nil {
+		return nil, ErrShareLinkNotFound
+	}
+
+	if link.RevokedAt == nil {
+		now := time.Now()
+		link.RevokedAt = &now
+		if err := uc.Repo.Revoke(link); err != nil {
+			return nil, err
+		}
+	}
+
+	return link, nil
+}
+
+// GetSharedCollection returns the collection of an active share link with
+// its requests and environments, and counts the view.
+func (uc *ShareCommandUsecase) GetSharedCollection(ctx context.Context, token string) (*models.ShareLink, []*requestModels.Request, []*environmentModels.Environment, error) {
+	link, err := uc.Repo.FindUnrevokedByTokenHash(helpers.HashToken(token))
+	if err != nil {
+		return nil, nil, nil, ErrShareLinkNotFound
+	}
+	if link.ExpiresAt != nil && link.ExpiresAt.Before(time.Now()) {
+		return nil, nil, nil, ErrShareLinkNotFound
+	}
+
+	collection, requests, environments, err := uc.Repo.FindCollectionContent(link.CollectionID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, nil, nil, ErrShareLinkNotFound
+		}
+		return nil, nil, nil, err
+	}
+	link.Collection = *collection
+
+	if err := uc.Repo.CountView(link); err != nil {
+		slog.ErrorContext(ctx, "Error counting share link view", "error", err)
+	}
+
+	return link, requests, environments, nil
+}
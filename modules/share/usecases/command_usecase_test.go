@@ -0,0 +1,148 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jeksilaen/api-builder/db/dbtest"
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	collectionRepositories "github.com/jeksilaen/api-builder/modules/collection/repositories"
+	collectionUsecases "github.com/jeksilaen/api-builder/modules/collection/usecases"
+	environmentModels "github.com/jeksilaen/api-builder/modules/environment/models"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+	"github.com/jeksilaen/api-builder/modules/share/helpers"
+	"github.com/jeksilaen/api-builder/modules/share/models"
+	"github.com/jeksilaen/api-builder/modules/share/repositories"
+	userModels "github.com/jeksilaen/api-builder/modules/user/models"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+	"gorm.io/gorm"
+)
+
+// newTestUsecase returns the usecase with a collection of bob holding
+// credentials, and carol, who is in none of bob's workspaces.
+func newTestUsecase(t *testing.T) (*ShareCommandUsecase, *gorm.DB, *collectionModels.Collection, *userModels.User, *userModels.User) {
+	t.Helper()
+	gormDB := dbtest.Open(t)
+	create := func(record interface{}) {
+		if err := gormDB.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bob := &userModels.User{Email: "bob@example.com", Username: "bob", Password: "hash"}
+	carol := &userModels.User{Email: "carol@example.com", Username: "carol", Password: "hash"}
+	create(bob)
+	create(carol)
+	workspace := &workspaceModels.Workspace{Name: "Bob", OwnerID: bob.ID, Personal: true}
+	create(workspace)
+	create(&workspaceModels.WorkspaceMember{WorkspaceID: workspace.ID, UserID: bob.ID, Role: workspaceModels.RoleOwner})
+
+	collection := &collectionModels.Collection{UserID: bob.ID, WorkspaceID: workspace.ID, Name: "Orders"}
+	create(collection)
+	create(&requestModels.Request{
+		ID:           uuid.New().String(),
+		CollectionID: collection.ID,
+		Name:         "List orders",
+		Method:       "GET",
+		URL:          "https://api.example.com/orders",
+		BearerToken:  "bearer-secret",
+		Headers:      requestModels.JSONMap{"Authorization": "Basic header-secret", "X-Api-Key": "key-secret", "Accept": "application/json"},
+	})
+	create(&environmentModels.Environment{
+		CollectionID:    collection.ID,
+		Name:            "Production",
+		Variables:       requestModels.JSONMap{"baseUrl": "https://api.example.com"},
+		SecretVariables: requestModels.JSONMap{"password": "variable-secret"},
+	})
+
+	uc := NewShareCommandUsecase(repositories.NewGormShareRepository(gormDB), collectionUsecases.NewCollectionCommandUsecase(collectionRepositories.NewGormCollectionRepository(gormDB)))
+	return uc, gormDB, collection, bob, carol
+}
+
+func TestShareLink(t *testing.T) {
+	uc, _, collection, bob, carol := newTestUsecase(t)
+	ctx := context.Background()
+
+	if _, _, err := uc.CreateShareLink(ctx, carol.ID, &models.ShareLink{CollectionID: collection.ID}); err == nil {
+		t.Error("carol shared a collection of bob")
+	}
+
+	link, token, err := uc.CreateShareLink(ctx, bob.ID, &models.ShareLink{CollectionID: collection.ID, Name: "Partners"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.TokenHash != helpers.HashToken(token) || strings.Contains(link.TokenHash, token) || !strings.HasPrefix(token, link.TokenPrefix) {
+		t.Errorf("link = %+v, want only the hash and prefix of %q", link, token)
+	}
+
+	shared, requests, environments, err := uc.GetSharedCollection(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shared.Collection.Name != "Orders" || len(requests) != 1 || len(environments) != 1 {
+		t.Fatalf("shared %q with %d requests and %d environments", shared.Collection.Name, len(requests), len(environments))
+	}
+
+	// The public view leaves every credential out
+	public, err := json.Marshal(helpers.ReturnSucessPublicResponse(shared, requests, environments))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"bearer-secret", "header-secret", "key-secret", "variable-secret"} {
+		if strings.Contains(string(public), secret) {
+			t.Errorf("public view shows %s: %s", secret, public)
+		}
+	}
+	if !strings.Contains(string(public), "application/json") {
+		t.Errorf("public view lost the Accept header: %s", public)
+	}
+
+	links, err := uc.GetShareLinksByCollectionID(bob.ID, collection.ID)
+	if err != nil || len(links) != 1 || links[0].Views != 1 || links[0].LastViewedAt == nil {
+		t.Errorf("links = %v, %v, want one view counted", links, err)
+	}
+
+	if _, err := uc.RevokeShareLink(carol.ID, link.ID); !errors.Is(err, ErrShareLinkNotFound) {
+		t.Errorf("carol revoking: %v, want ErrShareLinkNotFound", err)
+	}
+	if _, err := uc.RevokeShareLink(bob.ID, link.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := uc.GetSharedCollection(ctx, token); !errors.Is(err, ErrShareLinkNotFound) {
+		t.Errorf("revoked link: %v, want ErrShareLinkNotFound", err)
+	}
+}
+
+func TestExpiredShareLink(t *testing.T) {
+	uc, gormDB, collection, bob, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	past := time.Now().Add(-time.Minute)
+	if _, _, err := uc.CreateShareLink(ctx, bob.ID, &models.ShareLink{CollectionID: collection.ID, ExpiresAt: &past}); err == nil {
+		t.Error("link created already expired")
+	}
+
+	future := time.Now().Add(time.Hour)
+	link, token, err := uc.CreateShareLink(ctx, bob.ID, &models.ShareLink{CollectionID: collection.ID, ExpiresAt: &future})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := uc.GetSharedCollection(ctx, token); err != nil {
+		t.Fatalf("link before expiry: %v", err)
+	}
+
+	if err := gormDB.Model(link).Update("expires_at", past).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := uc.GetSharedCollection(ctx, token); !errors.Is(err, ErrShareLinkNotFound) {
+		t.Errorf("expired link: %v, want ErrShareLinkNotFound", err)
+	}
+	if _, _, _, err := uc.GetSharedCollection(ctx, "unknown"); !errors.Is(err, ErrShareLinkNotFound) {
+		t.Errorf("unknown token: %v, want ErrShareLinkNotFound", err)
+	}
+}
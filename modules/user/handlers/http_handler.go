@@ -1,23 +1,130 @@
 package handlers
 
 import (
+	"errors"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
-	// "github.com/jeksilaen/api-builder/middlewares"
+	"github.com/jeksilaen/api-builder/middlewares"
 	"github.com/jeksilaen/api-builder/modules/user/helpers"
 	"github.com/jeksilaen/api-builder/modules/user/models"
 	"github.com/jeksilaen/api-builder/modules/user/usecases"
 )
 
-func InitUserHttpHandler(router *gin.Engine) {
-	router.POST("/users/v1/login", LoginUser)
-	router.POST("/users/v1/register", RegisterUser)
+// UserHttpHandler serves the user routes with the usecases it is given.
+type UserHttpHandler struct {
+	Users *usecases.UserCommandUsecase
 }
 
-func RegisterUser(ctx *gin.Context) {
-	userUsecase := usecases.NewUserCommandUsecase()
+func NewUserHttpHandler(users *usecases.UserCommandUsecase) *UserHttpHandler {
+	return &UserHttpHandler{
+		Users: users,
+	}
+}
+
+func InitUserHttpHandler(router *gin.Engine, auth *middlewares.Auth, h *UserHttpHandler) {
+	router.POST("/users/v1/login", h.LoginUser)
+	router.POST("/users/v1/login/2fa", h.LoginTwoFactor)
+	router.GET("/users/v1/sso/login", h.StartSSOLogin)
+	router.POST("/users/v1/sso/callback", h.CompleteSSOLogin)
+	router.POST("/users/v1/register", h.RegisterUser)
+	router.POST("/users/v1/verify_email", h.VerifyEmail)
+	router.POST("/users/v1/verify_email/resend", auth.VerifyToken, h.ResendVerificationEmail)
+	router.POST("/users/v1/forgot_password", h.ForgotPassword)
+	router.POST("/users/v1/reset_password", h.ResetPassword)
+	router.POST("/users/v1/token/refresh", h.RefreshToken)
+	router.POST("/users/v1/logout", auth.VerifyToken, middlewares.RequireSession, h.LogoutUser)
+	router.GET("/.well-known/jwks.json", h.GetJWKS)
+
+	router.GET("/users/v1/me", auth.VerifyToken, h.GetCurrentUser)
+	router.PATCH("/users/v1/me", auth.VerifyToken, middlewares.RequireSession, h.UpdateCurrentUser)
+	router.DELETE("/users/v1/me", auth.VerifyToken, middlewares.RequireSession, h.DeleteCurrentUser)
+	router.POST("/users/v1/me/password", auth.VerifyToken, middlewares.RequireSession, h.ChangePassword)
+
+	router.POST("/users/v1/2fa/setup", auth.VerifyToken, middlewares.RequireSession, h.SetupTwoFactor)
+	router.POST("/users/v1/2fa/confirm", auth.VerifyToken, middlewares.RequireSession, h.ConfirmTwoFactor)
+	router.POST("/users/v1/2fa/disable", auth.VerifyToken, middlewares.RequireSession, h.DisableTwoFactor)
+	router.POST("/users/v1/2fa/recovery_codes", auth.VerifyToken, middlewares.RequireSession, h.RegenerateRecoveryCodes)
+
+	router.GET("/users/v1/api_key", auth.VerifyToken, middlewares.RequireSession, h.GetAPIKeys)
+	router.POST("/users/v1/api_key", auth.VerifyToken, middlewares.RequireSession, h.CreateAPIKey)
+	router.DELETE("/users/v1/api_key/:id", auth.VerifyToken, middlewares.RequireSession, h.RevokeAPIKey)
+}
+
+func (h *UserHttpHandler) GetAPIKeys(ctx *gin.Context) {
+
+	// Get the API keys of the user from usecase
+	apiKeys, err := h.Users.GetAPIKeysByUserID(middlewares.GetUserID(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "API keys not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessGetAPIKeysResponse(apiKeys))
+}
+
+func (h *UserHttpHandler) CreateAPIKey(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into APIKeyRequest object
+	var req models.APIKeyRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedAPIKeyResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedAPIKeyResponse(err.Error()))
+		return
+	}
+
+	apiKey := &models.APIKey{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	// Create the API key
+	createdAPIKey, key, err := h.Users.CreateAPIKey(ctx.Request.Context(), middlewares.GetUserID(ctx), apiKey)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedAPIKeyResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, helpers.ReturnSucessCreateAPIKeyResponse(createdAPIKey, key))
+}
+
+func (h *UserHttpHandler) RevokeAPIKey(ctx *gin.Context) {
+
+	keyID := ctx.Param("id")
+
+	if keyID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "API key ID is required"})
+		return
+	}
+
+	// Revoke the API key
+	apiKey, err := h.Users.RevokeAPIKey(middlewares.GetUserID(ctx), keyID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessAPIKeyResponse(apiKey, "Revoked API Key Successfully"))
+}
+
+// GetJWKS publishes the public keys verifying access tokens.
+func (h *UserHttpHandler) GetJWKS(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, h.Users.GetJWKS())
+}
+
+func (h *UserHttpHandler) RegisterUser(ctx *gin.Context) {
 	validate := validator.New()
 
 	// Decode the request JSON data into User object
@@ -35,17 +142,123 @@ func RegisterUser(ctx *gin.Context) {
 	}
 
 	// Create the user
-	createdUser, err := userUsecase.CreateUser(&req)
+	createdUser, err := h.Users.CreateUser(ctx.Request.Context(), &req)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedRegisterResponse(err.Error()))
 		return
 	}
 
+	// Ask the user to confirm their email address
+	if err := h.Users.SendVerificationEmail(ctx.Request.Context(), createdUser); err != nil {
+		slog.ErrorContext(ctx.Request.Context(), "Error sending verification email", "error", err)
+	}
+
 	ctx.JSON(http.StatusCreated, helpers.ReturnSucessRegisterResponse(createdUser))
 }
 
-func LoginUser(ctx *gin.Context) {
-	userUsecase := usecases.NewUserCommandUsecase()
+func (h *UserHttpHandler) VerifyEmail(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into VerifyEmailRequest object
+	var req models.VerifyEmailRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Mark the email address as verified
+	if _, err := h.Users.VerifyEmail(req.Token); err != nil {
+		if errors.Is(err, usecases.ErrInvalidUserToken) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessMessageResponse("Verified email sucessfully"))
+}
+
+func (h *UserHttpHandler) ResendVerificationEmail(ctx *gin.Context) {
+
+	user, err := h.Users.GetUserByID(middlewares.GetUserID(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	// Send a new verification link
+	if err := h.Users.SendVerificationEmail(ctx.Request.Context(), user); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessMessageResponse("Sent verification email sucessfully"))
+}
+
+func (h *UserHttpHandler) ForgotPassword(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into ForgotPasswordRequest object
+	var req models.ForgotPasswordRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Send a reset link, answering the same whether the account exists or not
+	if err := h.Users.ForgotPassword(ctx.Request.Context(), req.Email); err != nil {
+		slog.ErrorContext(ctx.Request.Context(), "Error sending password reset email", "error", err)
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessMessageResponse("If the email belongs to an account, a reset link was sent"))
+}
+
+func (h *UserHttpHandler) ResetPassword(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into ResetPasswordRequest object
+	var req models.ResetPasswordRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set the new password
+	if err := h.Users.ResetPassword(req.Token, req.Password); err != nil {
+		if errors.Is(err, usecases.ErrInvalidUserToken) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessMessageResponse("Reset password sucessfully"))
+}
+
+func (h *UserHttpHandler) LoginUser(ctx *gin.Context) {
 	validate := validator.New()
 
 	// Decode the request JSON data into User object
@@ -62,12 +275,407 @@ func LoginUser(ctx *gin.Context) {
 		return
 	}
 
-	// Find the user
-	user, err := userUsecase.FindUserByEmailAndPassword(&req)
+	// Check the password, slowing down repeated failures
+	user, err := h.Users.Login(ctx.Request.Context(), &req, ctx.ClientIP())
+	var throttled *usecases.LoginThrottledError
+	if errors.As(err, &throttled) {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		ctx.JSON(http.StatusTooManyRequests, helpers.ReturnFailedLoginResponse(err.Error()))
+		return
+	}
+	if errors.Is(err, usecases.ErrInvalidCredentials) {
+		ctx.JSON(http.StatusUnauthorized, helpers.ReturnFailedLoginResponse(err.Error()))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, helpers.ReturnFailedLoginResponse("Failed to log in, please try again"))
+		return
+	}
+
+	h.startSession(ctx, user)
+}
+
+// startSession answers a login whose first factor was checked, with the
+// tokens of a new session or with a two-factor challenge.
+func (h *UserHttpHandler) startSession(ctx *gin.Context, user *models.User) {
+	// Users with two-factor authentication finish logging in with a code
+	if user.TOTPEnabledAt != nil {
+		challenge, err := h.Users.CreateLoginChallenge(ctx.Request.Context(), user)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, helpers.ReturnFailedLoginResponse("Failed to log in, please try again"))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, helpers.ReturnSucessChallengeResponse(challenge, int64(usecases.LoginChallengeTTL.Seconds())))
+		return
+	}
+
+	// Start a login session
+	tokens, err := h.Users.IssueTokens(ctx.Request.Context(), user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, helpers.ReturnFailedLoginResponse("Failed to generate token, please try again"))
+		return
+	}
+
+	ctx.IndentedJSON(http.StatusOK, helpers.ReturnSucessLoginResponse(user, tokens))
+}
+
+// ssoBindingCookie holds the binding of the single sign-on login started by
+// the browser.
+const (
+	ssoBindingCookie = "sso_login"
+	ssoCookiePath    = "/users/v1/sso"
+)
+
+func (h *UserHttpHandler) StartSSOLogin(ctx *gin.Context) {
+
+	authorizationURL, binding, err := h.Users.StartSSOLogin()
+	if errors.Is(err, usecases.ErrSSODisabled) {
+		ctx.JSON(http.StatusNotFound, helpers.ReturnFailedLoginResponse(err.Error()))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, helpers.ReturnFailedLoginResponse("Failed to start single sign-on, please try again"))
+		return
+	}
+
+	// Only this browser can complete the login
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(ssoBindingCookie, binding, int(usecases.SSOLoginTTL.Seconds()), ssoCookiePath, "", ctx.Request.TLS != nil, true)
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessSSOLoginResponse(authorizationURL, int64(usecases.SSOLoginTTL.Seconds())))
+}
+
+func (h *UserHttpHandler) CompleteSSOLogin(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into SSOCallbackRequest object
+	var req models.SSOCallbackRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedLoginResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedLoginResponse(err.Error()))
+		return
+	}
+
+	// Find the user of the identity the IdP vouches for
+	binding, _ := ctx.Cookie(ssoBindingCookie)
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(ssoBindingCookie, "", -1, ssoCookiePath, "", ctx.Request.TLS != nil, true)
+
+	user, err := h.Users.CompleteSSOLogin(ctx.Request.Context(), req.Code, req.State, binding, ctx.ClientIP())
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrSSODisabled):
+			ctx.JSON(http.StatusNotFound, helpers.ReturnFailedLoginResponse(err.Error()))
+		case errors.Is(err, usecases.ErrInvalidSSOState):
+			ctx.JSON(http.StatusUnauthorized, helpers.ReturnFailedLoginResponse(err.Error()))
+		case errors.Is(err, usecases.ErrSSOEmailNotVerified), errors.Is(err, usecases.ErrSSOIdentityIncomplete), errors.Is(err, usecases.ErrSSOAccountUnverified):
+			ctx.JSON(http.StatusForbidden, helpers.ReturnFailedLoginResponse(err.Error()))
+		default:
+			ctx.JSON(http.StatusInternalServerError, helpers.ReturnFailedLoginResponse("Failed to log in, please try again"))
+		}
+		return
+	}
+
+	h.startSession(ctx, user)
+}
+
+func (h *UserHttpHandler) RefreshToken(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into RefreshRequest object
+	var req models.RefreshRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedRefreshResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedRefreshResponse(err.Error()))
+		return
+	}
+
+	// Swap the refresh token for new tokens
+	tokens, err := h.Users.RefreshTokens(ctx.Request.Context(), req.RefreshToken)
+	if errors.Is(err, usecases.ErrInvalidRefreshToken) || errors.Is(err, usecases.ErrRefreshTokenReused) {
+		ctx.JSON(http.StatusUnauthorized, helpers.ReturnFailedRefreshResponse(err.Error()))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, helpers.ReturnFailedRefreshResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessTokenResponse(tokens))
+}
+
+func (h *UserHttpHandler) LogoutUser(ctx *gin.Context) {
+
+	// End the session of the token, or every session with ?all=true
+	var err error
+	if ctx.Query("all") == "true" {
+		err = h.Users.RevokeAllSessions(middlewares.GetUserID(ctx))
+	} else {
+		err = h.Users.RevokeSession(middlewares.GetSessionID(ctx))
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessLogoutResponse())
+}
+
+func (h *UserHttpHandler) GetCurrentUser(ctx *gin.Context) {
+
+	user, err := h.Users.GetUserByID(middlewares.GetUserID(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessUserResponse(user, "Get user sucessfully"))
+}
+
+func (h *UserHttpHandler) UpdateCurrentUser(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into UpdateProfileRequest object
+	var req models.UpdateProfileRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Update the profile, a new email address is sent a verification link
+	user, err := h.Users.UpdateProfile(ctx.Request.Context(), middlewares.GetUserID(ctx), &req)
+	if err != nil && user == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx.Request.Context(), "Error sending verification email", "error", err)
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessUserResponse(user, "Updated user sucessfully"))
+}
+
+func (h *UserHttpHandler) ChangePassword(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into ChangePasswordRequest object
+	var req models.ChangePasswordRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Change the password and start a new session for the caller
+	user, tokens, err := h.Users.ChangePassword(ctx.Request.Context(), middlewares.GetUserID(ctx), middlewares.GetSessionID(ctx), req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		if errors.Is(err, usecases.ErrIncorrectPassword) || errors.Is(err, usecases.ErrReauthenticationRequired) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessChangePasswordResponse(user, tokens))
+}
+
+func (h *UserHttpHandler) DeleteCurrentUser(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into DeleteAccountRequest object
+	var req models.DeleteAccountRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Delete the account, handing shared workspaces to other members
+	if err := h.Users.DeleteAccount(middlewares.GetUserID(ctx), middlewares.GetSessionID(ctx), req.Password); err != nil {
+		if errors.Is(err, usecases.ErrIncorrectPassword) || errors.Is(err, usecases.ErrReauthenticationRequired) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessMessageResponse("Deleted account sucessfully"))
+}
+
+func (h *UserHttpHandler) LoginTwoFactor(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into TwoFactorLoginRequest object
+	var req models.TwoFactorLoginRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedLoginResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
 	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedLoginResponse(err.Error()))
+		return
+	}
+
+	// Check the code of the login challenge
+	user, err := h.Users.CompleteLogin(ctx.Request.Context(), req.ChallengeToken, req.Code, ctx.ClientIP())
+	var throttled *usecases.LoginThrottledError
+	if errors.As(err, &throttled) {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		ctx.JSON(http.StatusTooManyRequests, helpers.ReturnFailedLoginResponse(err.Error()))
+		return
+	}
+	if errors.Is(err, usecases.ErrInvalidChallenge) || errors.Is(err, usecases.ErrInvalidTwoFactorCode) {
 		ctx.JSON(http.StatusUnauthorized, helpers.ReturnFailedLoginResponse(err.Error()))
 		return
 	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, helpers.ReturnFailedLoginResponse("Failed to log in, please try again"))
+		return
+	}
+
+	// Start a login session
+	tokens, err := h.Users.IssueTokens(ctx.Request.Context(), user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, helpers.ReturnFailedLoginResponse("Failed to generate token, please try again"))
+		return
+	}
+
+	ctx.IndentedJSON(http.StatusOK, helpers.ReturnSucessLoginResponse(user, tokens))
+}
+
+func (h *UserHttpHandler) SetupTwoFactor(ctx *gin.Context) {
+
+	// Generate a new TOTP secret, used once a code of it is confirmed
+	user, secret, err := h.Users.SetupTwoFactor(middlewares.GetUserID(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessTwoFactorSetupResponse(user, secret))
+}
+
+func (h *UserHttpHandler) ConfirmTwoFactor(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into TwoFactorCodeRequest object
+	var req models.TwoFactorCodeRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Enable two-factor authentication, the recovery codes are only shown now
+	codes, err := h.Users.ConfirmTwoFactor(middlewares.GetUserID(ctx), req.Code)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessRecoveryCodesResponse(codes, "Enabled two-factor authentication sucessfully, store the recovery codes somewhere safe"))
+}
+
+func (h *UserHttpHandler) DisableTwoFactor(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into DisableTwoFactorRequest object
+	var req models.DisableTwoFactorRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.Users.DisableTwoFactor(middlewares.GetUserID(ctx), req.Password, req.Code)
+	if err != nil {
+		if errors.Is(err, usecases.ErrIncorrectPassword) || errors.Is(err, usecases.ErrInvalidTwoFactorCode) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessMessageResponse("Disabled two-factor authentication sucessfully"))
+}
+
+func (h *UserHttpHandler) RegenerateRecoveryCodes(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into TwoFactorCodeRequest object
+	var req models.TwoFactorCodeRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Replace the recovery codes, the old ones stop working
+	codes, err := h.Users.RegenerateRecoveryCodes(middlewares.GetUserID(ctx), req.Code)
+	if err != nil {
+		if errors.Is(err, usecases.ErrInvalidTwoFactorCode) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	ctx.IndentedJSON(http.StatusOK, helpers.ReturnSucessLoginResponse(user))
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessRecoveryCodesResponse(codes, "Generated new recovery codes sucessfully"))
 }
@@ -0,0 +1,34 @@
+package helpers
+
+import (
+	"net/url"
+
+	"github.com/jeksilaen/api-builder/mailer"
+)
+
+// VerificationEmail asks a user to confirm their email address in the web
+// app at appURL.
+func VerificationEmail(appURL string, username string, email string, token string) mailer.Message {
+	link := appURL + "/verify-email?token=" + url.QueryEscape(token)
+	return mailer.Message{
+		To:      email,
+		Subject: "Verify your email address",
+		Body: "Hi " + username + ",\n\n" +
+			"Please confirm your email address by opening this link:\n\n" +
+			link + "\n\n" +
+			"The link expires in 48 hours. If you didn't sign up for api-builder, you can ignore this email.\n",
+	}
+}
+
+// ResetPasswordEmail sends a password reset link to the web app at appURL.
+func ResetPasswordEmail(appURL string, username string, email string, token string) mailer.Message {
+	link := appURL + "/reset-password?token=" + url.QueryEscape(token)
+	return mailer.Message{
+		To:      email,
+		Subject: "Reset your password",
+		Body: "Hi " + username + ",\n\n" +
+			"Someone asked to reset the password of your api-builder account. Choose a new password here:\n\n" +
+			link + "\n\n" +
+			"The link expires in 1 hour and works once. If it wasn't you, you can ignore this email.\n",
+	}
+}
@@ -1,7 +1,6 @@
 package helpers
 
 import (
-	"github.com/jeksilaen/api-builder/middlewares"
 	"github.com/jeksilaen/api-builder/modules/user/models"
 )
 
@@ -35,9 +34,11 @@ func ReturnSucessRegisterResponse(createdUser *models.User) *models.SucessRegist
 	return &models.SucessRegistrationResponse{
 		Message: "Registered user sucessfully",
 		Data: models.UserResponse{
-			ID:       createdUser.ID,
-			Email:    createdUser.Email,
-			Username: createdUser.Username,
+			ID:            createdUser.ID,
+			Email:         createdUser.Email,
+			Username:      createdUser.Username,
+			EmailVerified: createdUser.EmailVerifiedAt != nil,
+			TwoFactor:     createdUser.TOTPEnabledAt != nil,
 		},
 		Links: []models.Link{
 			{
@@ -48,27 +49,191 @@ func ReturnSucessRegisterResponse(createdUser *models.User) *models.SucessRegist
 	}
 }
 
-func ReturnSucessLoginResponse(user *models.User) *models.SucessLoginResponse {
-	token, err := middlewares.GenerateToken(user.ID)
-	if err != nil {
-		return &models.SucessLoginResponse{
-			Message: "Login user sucessfully",
-			Data: models.UserResponse{
-				ID:       user.ID,
-				Email:    user.Email,
-				Username: user.Username,
+func ReturnSucessLoginResponse(user *models.User, tokens *models.TokenPair) *models.SucessLoginResponse {
+	return &models.SucessLoginResponse{
+		Message: "Login user sucessfully",
+		Data: models.UserResponse{
+			ID:            user.ID,
+			Email:         user.Email,
+			Username:      user.Username,
+			EmailVerified: user.EmailVerifiedAt != nil,
+			TwoFactor:     user.TOTPEnabledAt != nil,
+		},
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}
+}
+
+func ReturnFailedRefreshResponse(message string) *models.FailedResponse {
+	return &models.FailedResponse{
+		Error:   "Refresh failed",
+		Message: message,
+		Links: []models.Link{
+			{
+				Rel:  "login",
+				Href: "/users/v1/login",
 			},
-			Token: "Failed to generate token, please try again",
-		}
+		},
 	}
+}
 
-	return &models.SucessLoginResponse{
-		Message: "Login user sucessfully",
+func ReturnSucessTokenResponse(tokens *models.TokenPair) *models.SucessTokenResponse {
+	return &models.SucessTokenResponse{
+		Message:      "Refresh token sucessfully",
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		Links: []models.Link{
+			{
+				Rel:  "logout",
+				Href: "/users/v1/logout",
+			},
+		},
+	}
+}
+
+func ReturnSucessLogoutResponse() *models.SucessLogoutResponse {
+	return &models.SucessLogoutResponse{
+		Message: "Logout user sucessfully",
+		Links: []models.Link{
+			{
+				Rel:  "login",
+				Href: "/users/v1/login",
+			},
+		},
+	}
+}
+
+func ReturnFailedAPIKeyResponse(message string) *models.FailedResponse {
+	return &models.FailedResponse{
+		Error:   "Create API key failed",
+		Message: message,
+		Links: []models.Link{
+			{
+				Rel:  "get API keys",
+				Href: "/users/v1/api_key",
+			},
+		},
+	}
+}
+
+func toAPIKeyResponse(apiKey *models.APIKey) models.APIKeyResponse {
+	return models.APIKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.Scopes,
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		RevokedAt:  apiKey.RevokedAt,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
+
+// ReturnSucessCreateAPIKeyResponse includes the key, which is only shown
+// when it is created.
+func ReturnSucessCreateAPIKeyResponse(apiKey *models.APIKey, key string) *models.SucessAPIKeyResponse {
+	response := ReturnSucessAPIKeyResponse(apiKey, "Create API Key sucessfully")
+	response.Data.Key = key
+	return response
+}
+
+func ReturnSucessAPIKeyResponse(apiKey *models.APIKey, message string) *models.SucessAPIKeyResponse {
+	return &models.SucessAPIKeyResponse{
+		Message: message,
+		Data:    toAPIKeyResponse(apiKey),
+		Links: []models.Link{
+			{
+				Rel:  "revoke API key",
+				Href: "/users/v1/api_key/" + apiKey.ID,
+			},
+		},
+	}
+}
+
+func ReturnSucessGetAPIKeysResponse(apiKeys []*models.APIKey) *models.SucessGetAPIKeysResponse {
+	apiKeyResponses := []models.APIKeyResponse{}
+	for _, apiKey := range apiKeys {
+		apiKeyResponses = append(apiKeyResponses, toAPIKeyResponse(apiKey))
+	}
+
+	return &models.SucessGetAPIKeysResponse{
+		Message: "Get API Keys sucessfully",
+		Data:    apiKeyResponses,
+		Links: []models.Link{
+			{
+				Rel:  "create API key",
+				Href: "/users/v1/api_key",
+			},
+		},
+	}
+}
+
+func ReturnSucessMessageResponse(message string) *models.SucessMessageResponse {
+	return &models.SucessMessageResponse{
+		Message: message,
+		Links: []models.Link{
+			{
+				Rel:  "login",
+				Href: "/users/v1/login",
+			},
+		},
+	}
+}
+
+func ReturnSucessUserResponse(user *models.User, message string) *models.SucessUserResponse {
+	return &models.SucessUserResponse{
+		Message: message,
 		Data: models.UserResponse{
-			ID:       user.ID,
-			Email:    user.Email,
-			Username: user.Username,
+			ID:            user.ID,
+			Email:         user.Email,
+			Username:      user.Username,
+			EmailVerified: user.EmailVerifiedAt != nil,
+			TwoFactor:     user.TOTPEnabledAt != nil,
 		},
-		Token: token,
+	}
+}
+
+func ReturnSucessChangePasswordResponse(user *models.User, tokens *models.TokenPair) *models.SucessLoginResponse {
+	response := ReturnSucessLoginResponse(user, tokens)
+	response.Message = "Changed password sucessfully, other sessions were logged out"
+	return response
+}
+
+func ReturnSucessTwoFactorSetupResponse(user *models.User, secret string) *models.SucessTwoFactorSetupResponse {
+	return &models.SucessTwoFactorSetupResponse{
+		Message:    "Scan the otpauth URI with an authenticator app and confirm a code to enable two-factor authentication",
+		Secret:     secret,
+		OTPAuthURI: TOTPURI(user.Email, secret),
+	}
+}
+
+func ReturnSucessRecoveryCodesResponse(codes []string, message string) *models.SucessRecoveryCodesResponse {
+	return &models.SucessRecoveryCodesResponse{
+		Message:       message,
+		RecoveryCodes: codes,
+	}
+}
+
+func ReturnSucessChallengeResponse(challengeToken string, expiresIn int64) *models.SucessChallengeResponse {
+	return &models.SucessChallengeResponse{
+		Message:        "Enter a two-factor code to finish logging in",
+		ChallengeToken: challengeToken,
+		ExpiresIn:      expiresIn,
+		Links: []models.Link{
+			{
+				Rel:  "login_2fa",
+				Href: "/users/v1/login/2fa",
+			},
+		},
+	}
+}
+
+func ReturnSucessSSOLoginResponse(authorizationURL string, expiresIn int64) *models.SucessSSOLoginResponse {
+	return &models.SucessSSOLoginResponse{
+		Message:          "Log in at the identity provider",
+		AuthorizationURL: authorizationURL,
+		ExpiresIn:        expiresIn,
 	}
 }
@@ -0,0 +1,46 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/jeksilaen/api-builder/modules/user/models"
+)
+
+// NewRefreshToken returns a random refresh token.
+func NewRefreshToken() (string, error) {
+	return newToken("rt_")
+}
+
+// NewUserToken returns a random token to send by email.
+func NewUserToken() (string, error) {
+	return newToken("ut_")
+}
+
+func newToken(prefix string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(b), nil
+}
+
+// HashToken returns the hash under which a token is stored.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiKeyPrefixLength is how much of an API key is kept to tell keys apart.
+const apiKeyPrefixLength = 12
+
+// NewAPIKey returns a random personal API key and its displayable prefix.
+func NewAPIKey() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	key := models.APIKeyPrefix + hex.EncodeToString(b)
+	return key, key[:apiKeyPrefixLength], nil
+}
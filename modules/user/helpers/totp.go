@@ -0,0 +1,116 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters, the defaults of authenticator apps.
+const (
+	TOTPIssuer = "api-builder"
+	TOTPDigits = 6
+	TOTPPeriod = 30
+	// totpSkew is how many periods a code can be early or late
+	totpSkew = 1
+)
+
+// NewTOTPSecret returns a random base32 encoded secret.
+func NewTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b), nil
+}
+
+// TOTPURI returns the otpauth URI an authenticator app reads from a QR code.
+func TOTPURI(account string, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", TOTPIssuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(TOTPDigits))
+	query.Set("period", fmt.Sprint(TOTPPeriod))
+
+	label := url.PathEscape(TOTPIssuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// ValidateTOTP checks code against secret at time t. It returns the time
+// step the code belongs to, so a used code can be refused next time.
+func ValidateTOTP(secret string, code string, t time.Time) (int64, bool) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != TOTPDigits {
+		return 0, false
+	}
+
+	step := t.Unix() / TOTPPeriod
+	for offset := int64(-totpSkew); offset <= totpSkew; offset++ {
+		if hmac.Equal([]byte(totpCode(key, step+offset)), []byte(code)) {
+			return step + offset, true
+		}
+	}
+	return 0, false
+}
+
+// totpCode computes the code of a time step as described in RFC 6238.
+func totpCode(key []byte, step int64) string {
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < TOTPDigits; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", TOTPDigits, value%modulo)
+}
+
+// recoveryCodeAlphabet leaves out characters that are easily confused.
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// NewRecoveryCodes returns n random single-use recovery codes.
+func NewRecoveryCodes(n int) ([]string, error) {
+	// Bytes past the last multiple of the alphabet size are skipped so
+	// every character is as likely
+	limit := byte(256 / len(recoveryCodeAlphabet) * len(recoveryCodeAlphabet))
+
+	codes := make([]string, n)
+	random := make([]byte, 1)
+	for i := range codes {
+		code := make([]byte, 0, 10)
+		for len(code) < cap(code) {
+			if _, err := rand.Read(random); err != nil {
+				return nil, err
+			}
+			if random[0] < limit {
+				code = append(code, recoveryCodeAlphabet[int(random[0])%len(recoveryCodeAlphabet)])
+			}
+		}
+		codes[i] = string(code[:5]) + "-" + string(code[5:])
+	}
+	return codes, nil
+}
+
+// NormalizeRecoveryCode puts a typed recovery code in the form it was
+// handed out in.
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+	if len(code) == 10 && !strings.Contains(code, "-") {
+		code = code[:5] + "-" + code[5:]
+	}
+	return code
+}
@@ -0,0 +1,108 @@
+package helpers
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 key of the RFC 6238 test vectors, base32 encoded.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestValidateTOTP(t *testing.T) {
+	// The last six digits of the RFC 6238 SHA1 test vectors
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, test := range tests {
+		step, ok := ValidateTOTP(rfcSecret, test.code, time.Unix(test.unix, 0))
+		if !ok {
+			t.Errorf("%s at %d was refused", test.code, test.unix)
+			continue
+		}
+		if want := test.unix / TOTPPeriod; step != want {
+			t.Errorf("%s at %d: step = %d, want %d", test.code, test.unix, step, want)
+		}
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	at := time.Unix(1234567890, 0)
+
+	if step, ok := ValidateTOTP(rfcSecret, "005924", at.Add(TOTPPeriod*time.Second)); !ok || step != at.Unix()/TOTPPeriod {
+		t.Errorf("code of the previous period: step = %d, ok = %v", step, ok)
+	}
+	if _, ok := ValidateTOTP(rfcSecret, "005924", at.Add(2*TOTPPeriod*time.Second)); ok {
+		t.Error("code two periods old was accepted")
+	}
+}
+
+func TestValidateTOTPRefusesMalformedInput(t *testing.T) {
+	at := time.Unix(59, 0)
+
+	tests := []struct {
+		secret string
+		code   string
+	}{
+		{rfcSecret, "28708"},
+		{rfcSecret, "2870820"},
+		{rfcSecret, "000000"},
+		{"not base32!", "287082"},
+	}
+	for _, test := range tests {
+		if _, ok := ValidateTOTP(test.secret, test.code, at); ok {
+			t.Errorf("secret %q code %q was accepted", test.secret, test.code)
+		}
+	}
+
+	if _, ok := ValidateTOTP(strings.ToLower(rfcSecret), "287082", at); !ok {
+		t.Error("lower case secret was refused")
+	}
+}
+
+func TestNewTOTPSecret(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Fatalf("secret %q decodes to %d bytes: %v", secret, len(key), err)
+	}
+
+	now := time.Now()
+	if _, ok := ValidateTOTP(secret, totpCode(key, now.Unix()/TOTPPeriod), now); !ok {
+		t.Error("code of a new secret was refused")
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := NewRecoveryCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("code %q is not xxxxx-xxxxx", code)
+		}
+		if seen[code] {
+			t.Errorf("code %q handed out twice", code)
+		}
+		seen[code] = true
+
+		typed := strings.ToUpper(strings.ReplaceAll(code, "-", " "))
+		if normalized := NormalizeRecoveryCode(typed); normalized != code {
+			t.Errorf("NormalizeRecoveryCode(%q) = %q, want %q", typed, normalized, code)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIKeyPrefix starts every personal API key, which tells them apart from
+// access tokens.
+const APIKeyPrefix = "abk_"
+
+// APIKey lets scripts act as its user without logging in. Its scopes are
+// workspace permissions, which limit what the key can do on top of the role
+// of the user. Only the hash of the key is stored.
+type APIKey struct {
+	gorm.Model
+	ID         string `gorm:"type:uuid;primaryKey"`
+	UserID     string `gorm:"type:uuid;not null;index"`
+	Name       string `gorm:"not null"`
+	Prefix     string
+	KeyHash    string `gorm:"not null;uniqueIndex"`
+	Scopes     Scopes `gorm:"type:json"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+type Scopes []string
+
+// Scan converts JSON data from the database into Scopes.
+func (s *Scopes) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("Failed to unmarshal Scopes")
+	}
+	return json.Unmarshal(b, s)
+}
+
+// Value converts Scopes into JSON for storage in the database.
+func (s Scopes) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Allows reports whether the scopes include scope.
+func (s Scopes) Allows(scope string) bool {
+	for _, granted := range s {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type APIKeyRequest struct {
+	Name      string     `json:"name" validate:"required"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=read run edit secrets manage"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Key        string     `json:"key,omitempty"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type SucessAPIKeyResponse struct {
+	Message string         `json:"message"`
+	Data    APIKeyResponse `json:"data"`
+	Links   []Link         `json:"links"`
+}
+
+type SucessGetAPIKeysResponse struct {
+	Message string           `json:"message"`
+	Data    []APIKeyResponse `json:"data"`
+	Links   []Link           `json:"links"`
+}
+
+func (key *APIKey) BeforeCreate(tx *gorm.DB) error {
+	key.ID = uuid.New().String()
+	return nil
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Events recorded in the audit log.
+const (
+	AuditAccountLocked = "account_locked"
+	AuditIPLocked      = "ip_locked"
+	AuditSSOLinked     = "sso_linked"
+)
+
+// LoginAttempt is one login with a password, kept for a day to slow down
+// and lock out password guessing.
+type LoginAttempt struct {
+	ID        string    `gorm:"type:uuid;primaryKey"`
+	Email     string    `gorm:"not null;index"`
+	IP        string    `gorm:"not null;index"`
+	Success   bool      `gorm:"not null"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// AuditEvent records a security relevant event. UserID is empty when the
+// event isn't tied to an existing account.
+type AuditEvent struct {
+	ID        string    `gorm:"type:uuid;primaryKey"`
+	UserID    string    `gorm:"index"`
+	Event     string    `gorm:"not null;index"`
+	IP        string    `gorm:"not null"`
+	Detail    string    `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+func (attempt *LoginAttempt) BeforeCreate(tx *gorm.DB) error {
+	attempt.ID = uuid.New().String()
+	return nil
+}
+
+func (event *AuditEvent) BeforeCreate(tx *gorm.DB) error {
+	event.ID = uuid.New().String()
+	return nil
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefreshToken is one refresh token of a login session. A new token replaces
+// it on every refresh; the tokens of a session share its FamilyID, which is
+// also the session ID in the access tokens. Only the hash of a token is
+// stored.
+type RefreshToken struct {
+	gorm.Model
+	ID        string    `gorm:"type:uuid;primaryKey"`
+	UserID    string    `gorm:"type:uuid;not null;index"`
+	FamilyID  string    `gorm:"type:uuid;not null;index"`
+	TokenHash string    `gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+// TokenPair is what a login or a refresh hands out.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type SucessTokenResponse struct {
+	Message      string `json:"message"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Links        []Link `json:"links"`
+}
+
+type SucessLogoutResponse struct {
+	Message string `json:"message"`
+	Links   []Link `json:"links"`
+}
+
+func (token *RefreshToken) BeforeCreate(tx *gorm.DB) error {
+	token.ID = uuid.New().String()
+	return nil
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SSOLogin is a single sign-on login waiting for the IdP to redirect back.
+// The state sent to the IdP is only stored hashed; the nonce and PKCE code
+// verifier never leave the API. BindingHash ties the login to the browser
+// that started it through a cookie.
+type SSOLogin struct {
+	gorm.Model
+	ID           string    `gorm:"type:uuid;primaryKey"`
+	StateHash    string    `gorm:"not null;uniqueIndex"`
+	Nonce        string    `gorm:"not null"`
+	CodeVerifier string    `gorm:"not null"`
+	BindingHash  string    `gorm:"not null;default:''"`
+	ExpiresAt    time.Time `gorm:"not null"`
+	UsedAt       *time.Time
+}
+
+type SSOCallbackRequest struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
+type SucessSSOLoginResponse struct {
+	Message          string `json:"message"`
+	AuthorizationURL string `json:"authorization_url"`
+	ExpiresIn        int64  `json:"expires_in"`
+}
+
+func (login *SSOLogin) BeforeCreate(tx *gorm.DB) error {
+	login.ID = uuid.New().String()
+	return nil
+}
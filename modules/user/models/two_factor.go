@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TokenLoginChallenge is the purpose of the tokens that finish a login with
+// a two-factor code.
+const TokenLoginChallenge = "login_challenge"
+
+// RecoveryCode is a single-use code that replaces a TOTP code when the
+// authenticator is lost. Only the hash of the code is stored.
+type RecoveryCode struct {
+	gorm.Model
+	ID       string `gorm:"type:uuid;primaryKey"`
+	UserID   string `gorm:"type:uuid;not null;index"`
+	CodeHash string `gorm:"not null;index"`
+	UsedAt   *time.Time
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+}
+
+type SucessTwoFactorSetupResponse struct {
+	Message    string `json:"message"`
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type SucessRecoveryCodesResponse struct {
+	Message       string   `json:"message"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type SucessChallengeResponse struct {
+	Message        string `json:"message"`
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresIn      int64  `json:"expires_in"`
+	Links          []Link `json:"links"`
+}
+
+func (code *RecoveryCode) BeforeCreate(tx *gorm.DB) error {
+	code.ID = uuid.New().String()
+	return nil
+}
@@ -1,22 +1,31 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type User struct {
 	gorm.Model
-	ID       string `gorm:"type:uuid;primaryKey"`
-	Email    string `json:"email" gorm:"unique;not null" validate:"required,email"`
-	Username string `json:"username" gorm:"unique;not null" validate:"required"`
-	Password string `json:"password" validate:"required"`
+	ID              string     `gorm:"type:uuid;primaryKey"`
+	Email           string     `json:"email" gorm:"unique;not null" validate:"required,email"`
+	Username        string     `json:"username" gorm:"unique;not null" validate:"required"`
+	Password        string     `json:"password" validate:"required"`
+	EmailVerifiedAt *time.Time `json:"-"`
+	TOTPSecret      string     `json:"-"`
+	TOTPEnabledAt   *time.Time `json:"-"`
+	TOTPLastStep    int64      `json:"-"`
+	OIDCSubject     *string    `json:"-" gorm:"column:oidc_subject;uniqueIndex:idx_users_oidc_subject"`
 }
 
 type UserResponse struct {
-	ID       string `json:"id"`
-	Email    string `json:"email" gorm:"unique;not null" validate:"required,email"`
-	Username string `json:"username" gorm:"unique;not null" validate:"required"`
+	ID            string `json:"id"`
+	Email         string `json:"email" gorm:"unique;not null" validate:"required,email"`
+	Username      string `json:"username" gorm:"unique;not null" validate:"required"`
+	EmailVerified bool   `json:"email_verified"`
+	TwoFactor     bool   `json:"two_factor"`
 }
 
 type LoginRequest struct {
@@ -31,9 +40,11 @@ type SucessRegistrationResponse struct {
 }
 
 type SucessLoginResponse struct {
-	Message string       `json:"message"`
-	Data    UserResponse `json:"data"`
-	Token   string       `json:"token"`
+	Message      string       `json:"message"`
+	Data         UserResponse `json:"data"`
+	Token        string       `json:"token"`
+	RefreshToken string       `json:"refresh_token"`
+	ExpiresIn    int64        `json:"expires_in"`
 }
 
 type FailedResponse struct {
@@ -51,3 +62,24 @@ func (user *User) BeforeCreate(tx *gorm.DB) error {
 	user.ID = uuid.New().String()
 	return nil
 }
+
+type UpdateProfileRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email" validate:"omitempty,email"`
+}
+
+// ChangePasswordRequest and DeleteAccountRequest may leave the password out
+// for an account created with single sign-on, right after signing in.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type SucessUserResponse struct {
+	Message string       `json:"message"`
+	Data    UserResponse `json:"data"`
+}
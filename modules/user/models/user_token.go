@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Purposes of the tokens sent by email.
+const (
+	TokenVerifyEmail   = "verify_email"
+	TokenResetPassword = "reset_password"
+)
+
+// UserToken is a single-use token sent to the email address of a user.
+// Only the hash of the token is stored.
+type UserToken struct {
+	gorm.Model
+	ID        string    `gorm:"type:uuid;primaryKey"`
+	UserID    string    `gorm:"type:uuid;not null;index"`
+	Purpose   string    `gorm:"not null"`
+	Email     string    `gorm:"not null"`
+	TokenHash string    `gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type SucessMessageResponse struct {
+	Message string `json:"message"`
+	Links   []Link `json:"links"`
+}
+
+func (token *UserToken) BeforeCreate(tx *gorm.DB) error {
+	token.ID = uuid.New().String()
+	return nil
+}
@@ -0,0 +1,302 @@
+package repositories
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jeksilaen/api-builder/db"
+	"github.com/jeksilaen/api-builder/modules/user/models"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+	"gorm.io/gorm"
+)
+
+// GormUserRepository stores users with gorm, on any of the databases of
+// db.Open.
+type GormUserRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormUserRepository(db *gorm.DB) *GormUserRepository {
+	return &GormUserRepository{DB: db}
+}
+
+func (r *GormUserRepository) Transaction(fn func(repo UserRepository) error) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		return fn(&GormUserRepository{DB: tx})
+	})
+}
+
+func (r *GormUserRepository) CreateUser(user *models.User) error {
+	return r.DB.Create(user).Error
+}
+
+func (r *GormUserRepository) FindUser(userID string) (*models.User, error) {
+	return r.findUser("id = ?", userID)
+}
+
+func (r *GormUserRepository) FindUserByEmail(email string) (*models.User, error) {
+	return r.findUser("email = ?", email)
+}
+
+func (r *GormUserRepository) FindUserByLowerEmail(email string) (*models.User, error) {
+	return r.findUser("LOWER(email) = ?", email)
+}
+
+func (r *GormUserRepository) FindUserBySubject(subject string) (*models.User, error) {
+	return r.findUser("oidc_subject = ?", subject)
+}
+
+func (r *GormUserRepository) findUser(query string, value string) (*models.User, error) {
+	var user models.User
+	if err := r.DB.Where(query, value).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *GormUserRepository) UsernameTaken(username string) (bool, error) {
+	var count int64
+	err := r.DB.Model(&models.User{}).Where("username = ?", username).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *GormUserRepository) UpdateUser(user *models.User, updates map[string]interface{}) error {
+	return r.DB.Model(user).Updates(updates).Error
+}
+
+func (r *GormUserRepository) SetPassword(userID string, hashedPassword string) error {
+	return r.DB.Model(&models.User{}).Where("id = ?", userID).Update("password", hashedPassword).Error
+}
+
+func (r *GormUserRepository) DeleteUser(user *models.User) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", user.ID).Delete(&workspaceModels.WorkspaceMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("LOWER(email) = ?", strings.ToLower(user.Email)).Delete(&workspaceModels.WorkspaceInvitation{}).Error; err != nil {
+			return err
+		}
+
+		// Sign the user out everywhere and free the email and username
+		for _, model := range []interface{}{&models.RefreshToken{}, &models.APIKey{}, &models.UserToken{}, &models.RecoveryCode{}} {
+			if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Unscoped().Delete(user).Error
+	})
+}
+
+func (r *GormUserRepository) FindDeletedWorkspaceIDs(userID string) ([]string, error) {
+	var workspaceIDs []string
+	err := r.DB.Unscoped().Model(&workspaceModels.Workspace{}).Where("owner_id = ? AND deleted_at IS NOT NULL", userID).Pluck("id", &workspaceIDs).Error
+	return workspaceIDs, err
+}
+
+func (r *GormUserRepository) FindOwnedWorkspaces(userID string) ([]*workspaceModels.WorkspaceMember, error) {
+	var owned []*workspaceModels.WorkspaceMember
+	err := r.DB.Where("user_id = ? AND role = ?", userID, workspaceModels.RoleOwner).Preload("Workspace").Find(&owned).Error
+	return owned, err
+}
+
+func (r *GormUserRepository) FindOldestMember(workspaceID string, userID string, role string) (*workspaceModels.WorkspaceMember, error) {
+	var member workspaceModels.WorkspaceMember
+	err := r.DB.Where("workspace_id = ? AND user_id <> ? AND role = ?", workspaceID, userID, role).Order("created_at").First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *GormUserRepository) TransferWorkspace(member *workspaceModels.WorkspaceMember) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(member).Update("role", workspaceModels.RoleOwner).Error; err != nil {
+			return err
+		}
+		return tx.Model(&workspaceModels.Workspace{}).Where("id = ?", member.WorkspaceID).Update("owner_id", member.UserID).Error
+	})
+}
+
+func (r *GormUserRepository) DeleteWorkspace(workspaceID string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("workspace_id = ?", workspaceID).Delete(&workspaceModels.WorkspaceInvitation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("workspace_id = ?", workspaceID).Delete(&workspaceModels.WorkspaceMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id = ?", workspaceID).Delete(&workspaceModels.Workspace{}).Error
+	})
+}
+
+func (r *GormUserRepository) CreateRefreshToken(token *models.RefreshToken) error {
+	return r.DB.Create(token).Error
+}
+
+func (r *GormUserRepository) FindRefreshToken(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.DB.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *GormUserRepository) FindSessionStart(sessionID string) (time.Time, error) {
+	var token models.RefreshToken
+	if err := r.DB.Where("family_id = ?", sessionID).Order("created_at").First(&token).Error; err != nil {
+		return time.Time{}, err
+	}
+	return token.CreatedAt, nil
+}
+
+func (r *GormUserRepository) UseRefreshToken(tokenID string) (bool, error) {
+	used := r.DB.Model(&models.RefreshToken{}).Where("id = ? AND used_at IS NULL", tokenID).Update("used_at", time.Now())
+	return used.RowsAffected == 1, used.Error
+}
+
+func (r *GormUserRepository) RevokeSession(sessionID string) error {
+	return r.DB.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *GormUserRepository) RevokeAllSessions(userID string) error {
+	return r.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *GormUserRepository) CreateUserToken(token *models.UserToken) error {
+	return r.DB.Create(token).Error
+}
+
+func (r *GormUserRepository) FindUserToken(tokenHash string, purpose string) (*models.UserToken, error) {
+	var token models.UserToken
+	if err := r.DB.Where("token_hash = ? AND purpose = ?", tokenHash, purpose).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *GormUserRepository) UseUserToken(tokenID string) (bool, error) {
+	used := r.DB.Model(&models.UserToken{}).Where("id = ? AND used_at IS NULL", tokenID).Update("used_at", time.Now())
+	return used.RowsAffected == 1, used.Error
+}
+
+func (r *GormUserRepository) UseUserTokens(userID string, purpose string) error {
+	return r.DB.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+}
+
+func (r *GormUserRepository) FindAPIKeys(userID string) ([]*models.APIKey, error) {
+	var keys []*models.APIKey
+	err := r.DB.Where("user_id = ?", userID).Order("created_at desc").Find(&keys).Error
+	return keys, err
+}
+
+func (r *GormUserRepository) FindAPIKey(userID string, keyID string) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	if err := r.DB.Where("id = ? AND user_id = ?", keyID, userID).First(&apiKey).Error; err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+func (r *GormUserRepository) CreateAPIKey(apiKey *models.APIKey) error {
+	return r.DB.Create(apiKey).Error
+}
+
+func (r *GormUserRepository) RevokeAPIKey(apiKey *models.APIKey) error {
+	return r.DB.Model(apiKey).Update("revoked_at", apiKey.RevokedAt).Error
+}
+
+func (r *GormUserRepository) LockLoginAttempts(email string, ip string) error {
+	// SQLite runs one transaction at a time already
+	if r.DB.Dialector.Name() == db.DriverSQLite {
+		return nil
+	}
+
+	// Locked in the same order everywhere, so they can't deadlock
+	for _, key := range []string{"login email " + email, "login ip " + ip} {
+		if err := r.DB.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *GormUserRepository) CreateLoginAttempt(attempt *models.LoginAttempt) error {
+	return r.DB.Create(attempt).Error
+}
+
+func (r *GormUserRepository) SucceedLoginAttempt(attemptID string) error {
+	return r.DB.Model(&models.LoginAttempt{}).Where("id = ?", attemptID).Update("success", true).Error
+}
+
+func (r *GormUserRepository) FindLoginAttemptTimes(column string, value string, success bool, since time.Time, limit int) ([]time.Time, error) {
+	var times []time.Time
+	err := r.DB.Model(&models.LoginAttempt{}).
+		Where(column+" = ? AND success = ? AND created_at > ?", value, success, since).
+		Order("created_at DESC").Limit(limit).Pluck("created_at", &times).Error
+	return times, err
+}
+
+func (r *GormUserRepository) DeleteLoginAttemptsBefore(before time.Time) error {
+	return r.DB.Where("created_at < ?", before).Delete(&models.LoginAttempt{}).Error
+}
+
+func (r *GormUserRepository) CreateAuditEvent(event *models.AuditEvent) error {
+	return r.DB.Create(event).Error
+}
+
+func (r *GormUserRepository) CreateSSOLogin(login *models.SSOLogin) error {
+	return r.DB.Create(login).Error
+}
+
+func (r *GormUserRepository) FindUnusedSSOLogin(stateHash string) (*models.SSOLogin, error) {
+	var login models.SSOLogin
+	if err := r.DB.Where("state_hash = ? AND used_at IS NULL", stateHash).First(&login).Error; err != nil {
+		return nil, err
+	}
+	return &login, nil
+}
+
+func (r *GormUserRepository) UseSSOLogin(loginID string) (bool, error) {
+	used := r.DB.Model(&models.SSOLogin{}).Where("id = ? AND used_at IS NULL", loginID).Update("used_at", time.Now())
+	return used.RowsAffected == 1, used.Error
+}
+
+func (r *GormUserRepository) UseTOTPStep(userID string, step int64) (bool, error) {
+	result := r.DB.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *GormUserRepository) UseRecoveryCode(userID string, codeHash string) (bool, error) {
+	result := r.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *GormUserRepository) ReplaceRecoveryCodes(userID string, codeHashes []string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		for _, codeHash := range codeHashes {
+			if err := tx.Create(&models.RecoveryCode{UserID: userID, CodeHash: codeHash}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *GormUserRepository) DeleteRecoveryCodes(userID string) error {
+	return r.DB.Unscoped().Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+}
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jeksilaen/api-builder/db"
+	"github.com/jeksilaen/api-builder/db/dbtest"
+	"github.com/jeksilaen/api-builder/modules/user/models"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+)
+
+func createUser(t *testing.T, repo *GormUserRepository, name string) *models.User {
+	t.Helper()
+	user := &models.User{Email: name + "@example.com", Username: name, Password: "hash"}
+	if err := repo.CreateUser(user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestFindUserByEmail(t *testing.T) {
+	repo := NewGormUserRepository(dbtest.Open(t))
+	user := createUser(t, repo, "alice")
+
+	found, err := repo.FindUserByEmail("alice@example.com")
+	if err != nil || found.ID != user.ID {
+		t.Fatalf("FindUserByEmail = %v, %v", found, err)
+	}
+	if _, err := repo.FindUserByEmail("bob@example.com"); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("missing user: error = %v, want db.ErrNotFound", err)
+	}
+	if err := repo.CreateUser(&models.User{Email: "alice@example.com", Username: "alice2", Password: "hash"}); !errors.Is(err, db.ErrDuplicate) {
+		t.Errorf("duplicate email: error = %v, want db.ErrDuplicate", err)
+	}
+}
+
+func TestUseRefreshToken(t *testing.T) {
+	repo := NewGormUserRepository(dbtest.Open(t))
+	user := createUser(t, repo, "alice")
+	sessionID := uuid.New().String()
+
+	token := &models.RefreshToken{UserID: user.ID, FamilyID: sessionID, TokenHash: "hash-1", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := repo.CreateRefreshToken(token); err != nil {
+		t.Fatal(err)
+	}
+
+	if used, err := repo.UseRefreshToken(token.ID); err != nil || !used {
+		t.Fatalf("first use = %v, %v", used, err)
+	}
+	if used, err := repo.UseRefreshToken(token.ID); err != nil || used {
+		t.Errorf("second use = %v, %v, want false", used, err)
+	}
+
+	if err := repo.RevokeSession(sessionID); err != nil {
+		t.Fatal(err)
+	}
+	found, err := repo.FindRefreshToken("hash-1")
+	if err != nil || found.UsedAt == nil || found.RevokedAt == nil {
+		t.Errorf("token after use and revocation = %+v, %v", found, err)
+	}
+}
+
+func TestFindSessionStart(t *testing.T) {
+	repo := NewGormUserRepository(dbtest.Open(t))
+	user := createUser(t, repo, "alice")
+	sessionID := uuid.New().String()
+	startedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	// The first token of the session is the login, later ones are refreshes
+	for i, createdAt := range []time.Time{startedAt.Add(time.Minute), startedAt} {
+		token := &models.RefreshToken{UserID: user.ID, FamilyID: sessionID, TokenHash: uuid.New().String(), ExpiresAt: time.Now().Add(time.Hour)}
+		token.CreatedAt = createdAt
+		if err := repo.CreateRefreshToken(token); err != nil {
+			t.Fatalf("token %d: %v", i, err)
+		}
+	}
+
+	found, err := repo.FindSessionStart(sessionID)
+	if err != nil || !found.Equal(startedAt) {
+		t.Errorf("FindSessionStart = %v, %v, want %v", found, err, startedAt)
+	}
+	if _, err := repo.FindSessionStart(uuid.New().String()); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("unknown session: error = %v, want db.ErrNotFound", err)
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	gormDB := dbtest.Open(t)
+	repo := NewGormUserRepository(gormDB)
+	alice := createUser(t, repo, "alice")
+	bob := createUser(t, repo, "bob")
+
+	workspace := &workspaceModels.Workspace{Name: "Team", OwnerID: bob.ID}
+	if err := gormDB.Create(workspace).Error; err != nil {
+		t.Fatal(err)
+	}
+	records := []interface{}{
+		&workspaceModels.WorkspaceMember{WorkspaceID: workspace.ID, UserID: bob.ID, Role: workspaceModels.RoleOwner},
+		&workspaceModels.WorkspaceMember{WorkspaceID: workspace.ID, UserID: alice.ID, Role: workspaceModels.RoleEditor},
+		&models.RefreshToken{UserID: alice.ID, FamilyID: uuid.New().String(), TokenHash: "hash-1", ExpiresAt: time.Now().Add(time.Hour)},
+	}
+	for _, record := range records {
+		if err := gormDB.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repo.DeleteUser(alice); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.FindUser(alice.ID); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("deleted user: error = %v, want db.ErrNotFound", err)
+	}
+	if _, err := repo.FindRefreshToken("hash-1"); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("refresh token of the deleted user: error = %v, want db.ErrNotFound", err)
+	}
+	if _, err := repo.FindOldestMember(workspace.ID, bob.ID, workspaceModels.RoleEditor); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("membership of the deleted user: error = %v, want db.ErrNotFound", err)
+	}
+
+	// The email and username can be registered again
+	createUser(t, repo, "alice")
+}
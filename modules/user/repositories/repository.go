@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/jeksilaen/api-builder/modules/user/models"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+)
+
+// UserRepository stores users with their sessions, tokens, API keys, login
+// attempts and two-factor codes. Lookups of a missing record fail with
+// db.ErrNotFound.
+type UserRepository interface {
+	// Transaction runs fn with a repository whose changes are kept only if
+	// fn succeeds
+	Transaction(fn func(repo UserRepository) error) error
+
+	CreateUser(user *models.User) error
+	FindUser(userID string) (*models.User, error)
+	FindUserByEmail(email string) (*models.User, error)
+	// FindUserByLowerEmail matches the lower case email case-insensitively
+	FindUserByLowerEmail(email string) (*models.User, error)
+	FindUserBySubject(subject string) (*models.User, error)
+	UsernameTaken(username string) (bool, error)
+	UpdateUser(user *models.User, updates map[string]interface{}) error
+	SetPassword(userID string, hashedPassword string) error
+	// DeleteUser deletes the user with their memberships, the invitations
+	// sent to them, their tokens and their API keys
+	DeleteUser(user *models.User) error
+
+	// FindOwnedWorkspaces returns the memberships of the workspaces userID
+	// owns with the workspace
+	FindOwnedWorkspaces(userID string) ([]*workspaceModels.WorkspaceMember, error)
+	// FindOldestMember returns the earliest member with role of a workspace
+	// other than userID
+	FindOldestMember(workspaceID string, userID string, role string) (*workspaceModels.WorkspaceMember, error)
+	// TransferWorkspace makes member the owner of their workspace
+	TransferWorkspace(member *workspaceModels.WorkspaceMember) error
+	// FindDeletedWorkspaceIDs returns the soft deleted workspaces of userID
+	FindDeletedWorkspaceIDs(userID string) ([]string, error)
+	// DeleteWorkspace deletes a workspace with its members and invitations,
+	// once its collections are gone
+	DeleteWorkspace(workspaceID string) error
+
+	CreateRefreshToken(token *models.RefreshToken) error
+	FindRefreshToken(tokenHash string) (*models.RefreshToken, error)
+	// FindSessionStart returns when the first refresh token of a session
+	// was issued
+	FindSessionStart(sessionID string) (time.Time, error)
+	// UseRefreshToken marks a token as used, reporting false when it was
+	// used before
+	UseRefreshToken(tokenID string) (bool, error)
+	RevokeSession(sessionID string) error
+	RevokeAllSessions(userID string) error
+
+	CreateUserToken(token *models.UserToken) error
+	FindUserToken(tokenHash string, purpose string) (*models.UserToken, error)
+	// UseUserToken marks a token as used, reporting false when it was used
+	// before
+	UseUserToken(tokenID string) (bool, error)
+	// UseUserTokens marks the unused tokens of the user for purpose as used
+	UseUserTokens(userID string, purpose string) error
+
+	// FindAPIKeys returns the API keys of the user, newest first
+	FindAPIKeys(userID string) ([]*models.APIKey, error)
+	FindAPIKey(userID string, keyID string) (*models.APIKey, error)
+	CreateAPIKey(apiKey *models.APIKey) error
+	// RevokeAPIKey stores the RevokedAt of the key
+	RevokeAPIKey(apiKey *models.APIKey) error
+
+	// LockLoginAttempts makes the other transactions locking the attempts of
+	// email or ip wait for the end of this one
+	LockLoginAttempts(email string, ip string) error
+	CreateLoginAttempt(attempt *models.LoginAttempt) error
+	SucceedLoginAttempt(attemptID string) error
+	// FindLoginAttemptTimes returns when the last attempts whose column,
+	// email or ip, is value were made after since, newest first
+	FindLoginAttemptTimes(column string, value string, success bool, since time.Time, limit int) ([]time.Time, error)
+	DeleteLoginAttemptsBefore(before time.Time) error
+	CreateAuditEvent(event *models.AuditEvent) error
+
+	CreateSSOLogin(login *models.SSOLogin) error
+	FindUnusedSSOLogin(stateHash string) (*models.SSOLogin, error)
+	// UseSSOLogin marks a login as used, reporting false when it was used
+	// before
+	UseSSOLogin(loginID string) (bool, error)
+
+	// UseTOTPStep stores the last TOTP step used by the user, reporting
+	// false when a later or the same step was used before
+	UseTOTPStep(userID string, step int64) (bool, error)
+	// UseRecoveryCode marks an unused recovery code of the user as used,
+	// reporting false when there is none
+	UseRecoveryCode(userID string, codeHash string) (bool, error)
+	// ReplaceRecoveryCodes deletes the recovery codes of the user and stores
+	// the new ones
+	ReplaceRecoveryCodes(userID string, codeHashes []string) error
+	DeleteRecoveryCodes(userID string) error
+}
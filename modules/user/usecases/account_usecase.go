@@ -0,0 +1,272 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jeksilaen/api-builder/db"
+	"github.com/jeksilaen/api-builder/modules/user/models"
+	"github.com/jeksilaen/api-builder/modules/user/repositories"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Errors returned when an account change can't be confirmed.
+var (
+	ErrIncorrectPassword        = errors.New("Current password is incorrect")
+	ErrReauthenticationRequired = errors.New("Sign in again with single sign-on to confirm this change")
+)
+
+// reauthWindow is how recent a login must be to confirm an account change
+// without a password.
+const reauthWindow = 10 * time.Minute
+
+// AccountCollections finds the collections touched by deleting an account
+// and deletes them.
+type AccountCollections interface {
+	FindIDsByWorkspace(workspaceID string) ([]string, error)
+	FindOrphanedIDs(userID string) ([]string, error)
+	HandToWorkspaceOwners(userID string) error
+	DeleteByIDs(collectionIDs []string) error
+}
+
+// CollectionRecords deletes what a module stores for collections that are
+// about to be deleted.
+type CollectionRecords interface {
+	DeleteByCollections(collectionIDs []string) error
+}
+
+// ShareLinkRevoker revokes the share links a user created.
+type ShareLinkRevoker interface {
+	RevokeByCreator(userID string) error
+}
+
+// AccountData is what the other modules store for an account. Records are
+// deleted in order, before the collections they belong to.
+type AccountData struct {
+	Collections AccountCollections
+	ShareLinks  ShareLinkRevoker
+	Records     []CollectionRecords
+}
+
+// successorRoles are the roles that can inherit a workspace, from the first
+// to the last choice.
+var successorRoles = []string{
+	workspaceModels.RoleAdmin,
+	workspaceModels.RoleEditor,
+	workspaceModels.RoleRunner,
+	workspaceModels.RoleViewer,
+}
+
+// UpdateProfile changes the username and email address of the user. A new
+// email address has to be verified again.
+func (uc *UserCommandUsecase) UpdateProfile(ctx context.Context, userID string, req *models.UpdateProfileRequest) (*models.User, error) {
+	user, err := uc.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Username != "" && req.Username != user.Username {
+		updates["username"] = req.Username
+		user.Username = req.Username
+	}
+
+	emailChanged := false
+	if email := strings.TrimSpace(req.Email); email != "" && !strings.EqualFold(email, user.Email) {
+		updates["email"] = email
+		updates["email_verified_at"] = nil
+		user.Email = email
+		user.EmailVerifiedAt = nil
+		emailChanged = true
+	}
+
+	if len(updates) == 0 {
+		return user, nil
+	}
+
+	err = uc.Repo.UpdateUser(user, updates)
+	if err != nil {
+		if errors.Is(err, db.ErrDuplicate) {
+			return nil, errors.New("email or username already in use")
+		}
+		return nil, err
+	}
+
+	// Confirm the new address, the links sent to the old one stop working
+	if emailChanged {
+		if err := uc.SendVerificationEmail(ctx, user); err != nil {
+			return user, err
+		}
+	}
+
+	return user, nil
+}
+
+// ChangePassword sets a new password once confirmIdentity accepts the
+// current one. Every session is ended, the caller gets a new one in the
+// returned tokens.
+func (uc *UserCommandUsecase) ChangePassword(ctx context.Context, userID string, sessionID string, currentPassword string, newPassword string) (*models.User, *models.TokenPair, error) {
+	user, err := uc.GetUserByID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := uc.confirmIdentity(user, sessionID, currentPassword); err != nil {
+		return nil, nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tokens *models.TokenPair
+	err = uc.Repo.Transaction(func(repo repositories.UserRepository) error {
+		if err := repo.SetPassword(userID, string(hashedPassword)); err != nil {
+			return err
+		}
+		if err := repo.RevokeAllSessions(userID); err != nil {
+			return err
+		}
+
+		tokens, err = uc.issueTokens(ctx, repo, userID, uuid.New().String())
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, tokens, nil
+}
+
+// DeleteAccount deletes the user once confirmIdentity accepts the password.
+// Workspaces owned by the user go to the next member in line, the admins
+// first, and the collections the user created there are handed to that
+// owner. Workspaces nobody else is a member of are deleted with their
+// collections and everything in them. Every step can be run again, so an
+// account whose deletion failed halfway is deleted by trying again.
+func (uc *UserCommandUsecase) DeleteAccount(userID string, sessionID string, password string) error {
+	user, err := uc.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.confirmIdentity(user, sessionID, password); err != nil {
+		return err
+	}
+
+	owned, err := uc.Repo.FindOwnedWorkspaces(userID)
+	if err != nil {
+		return err
+	}
+
+	deletedWorkspaceIDs, err := uc.Repo.FindDeletedWorkspaceIDs(userID)
+	if err != nil {
+		return err
+	}
+
+	for _, membership := range owned {
+		successor, err := findSuccessor(uc.Repo, membership.WorkspaceID, userID)
+		if err != nil {
+			return err
+		}
+
+		if membership.Workspace.Personal || successor == nil {
+			deletedWorkspaceIDs = append(deletedWorkspaceIDs, membership.WorkspaceID)
+			continue
+		}
+
+		if err := uc.Repo.TransferWorkspace(successor); err != nil {
+			return err
+		}
+	}
+
+	for _, workspaceID := range deletedWorkspaceIDs {
+		collectionIDs, err := uc.Data.Collections.FindIDsByWorkspace(workspaceID)
+		if err != nil {
+			return err
+		}
+		if err := uc.deleteCollections(collectionIDs); err != nil {
+			return err
+		}
+		if err := uc.Repo.DeleteWorkspace(workspaceID); err != nil {
+			return err
+		}
+	}
+
+	// Collections outside of any workspace have nobody to go to
+	orphanIDs, err := uc.Data.Collections.FindOrphanedIDs(userID)
+	if err != nil {
+		return err
+	}
+	if err := uc.deleteCollections(orphanIDs); err != nil {
+		return err
+	}
+
+	// Hand the collections left in shared workspaces to their owners
+	if err := uc.Data.Collections.HandToWorkspaceOwners(userID); err != nil {
+		return err
+	}
+	if err := uc.Data.ShareLinks.RevokeByCreator(userID); err != nil {
+		return err
+	}
+
+	return uc.Repo.DeleteUser(user)
+}
+
+// deleteCollections deletes the collections for good with everything the
+// modules store for them.
+func (uc *UserCommandUsecase) deleteCollections(collectionIDs []string) error {
+	if len(collectionIDs) == 0 {
+		return nil
+	}
+
+	for _, records := range uc.Data.Records {
+		if err := records.DeleteByCollections(collectionIDs); err != nil {
+			return err
+		}
+	}
+	return uc.Data.Collections.DeleteByIDs(collectionIDs)
+}
+
+// confirmIdentity checks the password given to confirm an account change.
+// Accounts created with single sign-on have no password their user knows,
+// so they can leave it out when their session started less than
+// reauthWindow ago, such as right after signing in with the IdP again.
+func (uc *UserCommandUsecase) confirmIdentity(user *models.User, sessionID string, password string) error {
+	if password != "" {
+		if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+			return ErrIncorrectPassword
+		}
+		return nil
+	}
+
+	if user.OIDCSubject == nil {
+		return ErrIncorrectPassword
+	}
+
+	startedAt, err := uc.Repo.FindSessionStart(sessionID)
+	if errors.Is(err, db.ErrNotFound) || (err == nil && time.Since(startedAt) > reauthWindow) {
+		return ErrReauthenticationRequired
+	}
+	return err
+}
+
+// findSuccessor returns the member that inherits a workspace from userID, or
+// nil when nobody else is a member.
+func findSuccessor(repo repositories.UserRepository, workspaceID string, userID string) (*workspaceModels.WorkspaceMember, error) {
+	for _, role := range successorRoles {
+		member, err := repo.FindOldestMember(workspaceID, userID, role)
+		if err == nil {
+			return member, nil
+		}
+		if !errors.Is(err, db.ErrNotFound) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
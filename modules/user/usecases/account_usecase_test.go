@@ -0,0 +1,236 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jeksilaen/api-builder/db"
+	"github.com/jeksilaen/api-builder/db/dbtest"
+	"github.com/jeksilaen/api-builder/middlewares"
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	collectionRepositories "github.com/jeksilaen/api-builder/modules/collection/repositories"
+	environmentModels "github.com/jeksilaen/api-builder/modules/environment/models"
+	environmentRepositories "github.com/jeksilaen/api-builder/modules/environment/repositories"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+	requestRepositories "github.com/jeksilaen/api-builder/modules/request/repositories"
+	shareModels "github.com/jeksilaen/api-builder/modules/share/models"
+	shareRepositories "github.com/jeksilaen/api-builder/modules/share/repositories"
+	"github.com/jeksilaen/api-builder/modules/user/models"
+	"github.com/jeksilaen/api-builder/modules/user/repositories"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+	"gorm.io/gorm"
+)
+
+// newTestUsecase returns the usecase on a new database, wired like the app
+// but without mail or single sign-on.
+func newTestUsecase(t *testing.T) (*UserCommandUsecase, *gorm.DB) {
+	t.Helper()
+	gormDB := dbtest.Open(t)
+	keys, err := middlewares.NewTemporaryKeySet()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shareRepository := shareRepositories.NewGormShareRepository(gormDB)
+	uc := NewUserCommandUsecase(repositories.NewGormUserRepository(gormDB), keys, nil, nil, "http://localhost:3000", AccountData{
+		Collections: collectionRepositories.NewGormCollectionRepository(gormDB),
+		ShareLinks:  shareRepository,
+		Records: []CollectionRecords{
+			requestRepositories.NewGormRequestRepository(gormDB),
+			environmentRepositories.NewGormEnvironmentRepository(gormDB),
+			shareRepository,
+		},
+	})
+	return uc, gormDB
+}
+
+func registerUser(t *testing.T, uc *UserCommandUsecase, name string) *models.User {
+	t.Helper()
+	user, err := uc.CreateUser(context.Background(), &models.User{Email: name + "@example.com", Username: name, Password: "secret-" + name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func mustCreate(t *testing.T, gormDB *gorm.DB, records ...interface{}) {
+	t.Helper()
+	for _, record := range records {
+		if err := gormDB.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	uc, gormDB := newTestUsecase(t)
+	alice := registerUser(t, uc, "alice")
+	bob := registerUser(t, uc, "bob")
+
+	// Alice owns a personal workspace, and a team workspace bob is an
+	// admin of
+	personal := &workspaceModels.Workspace{Name: "Alice", OwnerID: alice.ID, Personal: true}
+	team := &workspaceModels.Workspace{Name: "Team", OwnerID: alice.ID}
+	mustCreate(t, gormDB, personal, team)
+	mustCreate(t, gormDB,
+		&workspaceModels.WorkspaceMember{WorkspaceID: personal.ID, UserID: alice.ID, Role: workspaceModels.RoleOwner},
+		&workspaceModels.WorkspaceMember{WorkspaceID: team.ID, UserID: alice.ID, Role: workspaceModels.RoleOwner},
+		&workspaceModels.WorkspaceMember{WorkspaceID: team.ID, UserID: bob.ID, Role: workspaceModels.RoleAdmin},
+	)
+
+	private := &collectionModels.Collection{UserID: alice.ID, WorkspaceID: personal.ID, Name: "Private"}
+	shared := &collectionModels.Collection{UserID: alice.ID, WorkspaceID: team.ID, Name: "Shared"}
+	mustCreate(t, gormDB, private, shared)
+	link := &shareModels.ShareLink{ID: uuid.New().String(), CollectionID: shared.ID, TokenHash: "share-hash", CreatedBy: alice.ID}
+	mustCreate(t, gormDB,
+		&requestModels.Request{ID: uuid.New().String(), CollectionID: private.ID, Name: "Private request"},
+		&requestModels.Request{ID: uuid.New().String(), CollectionID: shared.ID, Name: "Shared request"},
+		&environmentModels.Environment{ID: uuid.New().String(), CollectionID: private.ID, Name: "Local"},
+		link,
+	)
+
+	if err := uc.DeleteAccount(alice.ID, "", "wrong"); !errors.Is(err, ErrIncorrectPassword) {
+		t.Fatalf("wrong password: error = %v", err)
+	}
+	if err := uc.DeleteAccount(alice.ID, "", ""); !errors.Is(err, ErrIncorrectPassword) {
+		t.Fatalf("no password: error = %v", err)
+	}
+	if err := uc.DeleteAccount(alice.ID, "", "secret-alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := uc.Repo.FindUser(alice.ID); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("deleted user: error = %v", err)
+	}
+
+	count := func(model interface{}, query string, args ...interface{}) int64 {
+		var n int64
+		if err := gormDB.Unscoped().Model(model).Where(query, args...).Count(&n).Error; err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	if n := count(&workspaceModels.Workspace{}, "id = ?", personal.ID); n != 0 {
+		t.Error("the personal workspace was kept")
+	}
+	if n := count(&collectionModels.Collection{}, "id = ?", private.ID); n != 0 {
+		t.Error("the collection of the personal workspace was kept")
+	}
+	if n := count(&requestModels.Request{}, "collection_id = ?", private.ID); n != 0 {
+		t.Error("the requests of the personal workspace were kept")
+	}
+	if n := count(&environmentModels.Environment{}, "collection_id = ?", private.ID); n != 0 {
+		t.Error("the environments of the personal workspace were kept")
+	}
+
+	// The team workspace and its collection go to bob
+	var workspace workspaceModels.Workspace
+	if err := gormDB.First(&workspace, "id = ?", team.ID).Error; err != nil || workspace.OwnerID != bob.ID {
+		t.Errorf("team workspace = %+v, %v, want it owned by bob", workspace, err)
+	}
+	var collection collectionModels.Collection
+	if err := gormDB.First(&collection, "id = ?", shared.ID).Error; err != nil || collection.UserID != bob.ID {
+		t.Errorf("shared collection = %+v, %v, want it handed to bob", collection, err)
+	}
+	if n := count(&requestModels.Request{}, "collection_id = ?", shared.ID); n != 1 {
+		t.Errorf("shared collection has %d requests, want 1", n)
+	}
+	var revoked shareModels.ShareLink
+	if err := gormDB.First(&revoked, "id = ?", link.ID).Error; err != nil || revoked.RevokedAt == nil {
+		t.Errorf("share link of the deleted user = %+v, %v, want it revoked", revoked, err)
+	}
+
+	// The email can be registered again
+	registerUser(t, uc, "alice")
+}
+
+func TestDeleteAccountEndsSessions(t *testing.T) {
+	uc, gormDB := newTestUsecase(t)
+	user := registerUser(t, uc, "alice")
+	sessionID := uuid.New().String()
+	tokens, err := uc.issueTokens(context.Background(), uc.Repo, user.ID, sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	auth := middlewares.NewAuth(uc.Keys, middlewares.NewGormAuthRepository(gormDB), nil)
+	router := gin.New()
+	router.GET("/protected", auth.VerifyToken, func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	get := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	if status := get(); status != http.StatusOK {
+		t.Fatalf("before the deletion: status = %d, want 200", status)
+	}
+	if err := uc.DeleteAccount(user.ID, sessionID, "secret-alice"); err != nil {
+		t.Fatal(err)
+	}
+	// The access token hasn't expired but its user is gone
+	if status := get(); status != http.StatusUnauthorized {
+		t.Errorf("after the deletion: status = %d, want 401", status)
+	}
+}
+
+func TestDeleteAccountWithSingleSignOn(t *testing.T) {
+	uc, gormDB := newTestUsecase(t)
+	user := registerUser(t, uc, "alice")
+	subject := "idp|alice"
+	if err := gormDB.Model(user).Update("oidc_subject", subject).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := uc.DeleteAccount(user.ID, uuid.New().String(), ""); !errors.Is(err, ErrReauthenticationRequired) {
+		t.Fatalf("unknown session: error = %v", err)
+	}
+
+	// A session that just started confirms the deletion without a password
+	sessionID := uuid.New().String()
+	if _, err := uc.issueTokens(context.Background(), uc.Repo, user.ID, sessionID); err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.DeleteAccount(user.ID, sessionID, ""); err != nil {
+		t.Errorf("fresh session: %v", err)
+	}
+}
+
+func TestRefreshTokens(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	user := registerUser(t, uc, "alice")
+	ctx := context.Background()
+
+	first, err := uc.IssueTokens(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := uc.RefreshTokens(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Error("the refresh token was not rotated")
+	}
+
+	// Using the first token again revokes the session, the second token too
+	if _, err := uc.RefreshTokens(ctx, first.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Errorf("reused token: error = %v", err)
+	}
+	if _, err := uc.RefreshTokens(ctx, second.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("token of the revoked session: error = %v", err)
+	}
+	if _, err := uc.RefreshTokens(ctx, "unknown"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("unknown token: error = %v", err)
+	}
+}
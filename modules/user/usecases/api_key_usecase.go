@@ -0,0 +1,66 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jeksilaen/api-builder/modules/user/helpers"
+	"github.com/jeksilaen/api-builder/modules/user/models"
+)
+
+// ErrAPIKeyNotFound is returned for API keys that don't exist or belong to
+// another user.
+var ErrAPIKeyNotFound = errors.New("API key not found")
+
+func (uc *UserCommandUsecase) GetAPIKeysByUserID(userID string) ([]*models.APIKey, error) {
+	keys, err := uc.Repo.FindAPIKeys(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// CreateAPIKey generates a personal API key of the user. The key is
+// returned once and only its hash is stored.
+func (uc *UserCommandUsecase) CreateAPIKey(ctx context.Context, userID string, apiKey *models.APIKey) (*models.APIKey, string, error) {
+	if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(time.Now()) {
+		return nil, "", errors.New("expires_at must be in the future")
+	}
+
+	key, prefix, err := helpers.NewAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+	apiKey.UserID = userID
+	apiKey.Prefix = prefix
+	apiKey.KeyHash = helpers.HashToken(key)
+
+	if err := uc.Repo.CreateAPIKey(apiKey); err != nil {
+		slog.ErrorContext(ctx, "Error creating API key", "error", err)
+		return nil, "", err
+	}
+
+	return apiKey, key, nil
+}
+
+// RevokeAPIKey stops an API key of the user from working. Revoked keys are
+// kept so they still show up in the list.
+func (uc *UserCommandUsecase) RevokeAPIKey(userID string, keyID string) (*models.APIKey, error) {
+	apiKey, err := uc.Repo.FindAPIKey(userID, keyID)
+	if err != nil {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	if apiKey.RevokedAt == nil {
+		now := time.Now()
+		apiKey.RevokedAt = &now
+		if err := uc.Repo.RevokeAPIKey(apiKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return apiKey, nil
+}
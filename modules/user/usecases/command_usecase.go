@@ -1,65 +1,100 @@
 package usecases
 
 import (
+	"context"
 	"errors"
-	"log"
+	"log/slog"
 	"strings"
 
 	"github.com/jeksilaen/api-builder/db"
+	"github.com/jeksilaen/api-builder/mailer"
+	"github.com/jeksilaen/api-builder/middlewares"
 	"github.com/jeksilaen/api-builder/modules/user/models"
+	"github.com/jeksilaen/api-builder/modules/user/repositories"
+	"github.com/jeksilaen/api-builder/sso"
 	"golang.org/x/crypto/bcrypt"
-	"gorm.io/gorm"
 )
 
+// UserCommandUsecase manages the accounts. Keys sign the access tokens,
+// Mailer sends the account emails linking to the web app at AppURL, and SSO
+// is nil while single sign-on is off. Data is deleted along with an account.
 type UserCommandUsecase struct {
-	DB *gorm.DB
+	Repo   repositories.UserRepository
+	Keys   *middlewares.KeySet
+	Mailer mailer.Sender
+	SSO    *sso.Provider
+	AppURL string
+	Data   AccountData
 }
 
-func NewUserCommandUsecase() *UserCommandUsecase {
+func NewUserCommandUsecase(repo repositories.UserRepository, keys *middlewares.KeySet, sender mailer.Sender, provider *sso.Provider, appURL string, data AccountData) *UserCommandUsecase {
 	return &UserCommandUsecase{
-		DB: db.GetDB(),
+		Repo:   repo,
+		Keys:   keys,
+		Mailer: sender,
+		SSO:    provider,
+		AppURL: appURL,
+		Data:   data,
 	}
 }
 
-func (uc *UserCommandUsecase) CreateUser(user *models.User) (*models.User, error) {
+// GetJWKS returns the public keys verifying the access tokens.
+func (uc *UserCommandUsecase) GetJWKS() *middlewares.JWKS {
+	return uc.Keys.JWKS()
+}
+
+func (uc *UserCommandUsecase) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
 	// Hash the password using bcrypt
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
-		log.Fatal(err)
+		slog.ErrorContext(ctx, "Error hashing password", "error", err)
 		return nil, err
 	}
 	user.Password = string(hashedPassword)
 
 	// Create the user and handle duplicate error
-	err = uc.DB.Create(user).Error
+	err = uc.Repo.CreateUser(user)
 	if err != nil {
-		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+		if errors.Is(err, db.ErrDuplicate) {
 			return nil, errors.New("email or username already in use")
 		}
 
-		log.Println("Error creating user:", err)
+		slog.ErrorContext(ctx, "Error creating user", "error", err)
 		return nil, err
 	}
 
 	return user, nil
 }
 
-func (uc *UserCommandUsecase) FindUserByEmailAndPassword(req *models.LoginRequest) (*models.User, error) {
-	// Find the user by email
-	var user models.User
-	err := uc.DB.Where("email = ?", req.Email).First(&user).Error
+func (uc *UserCommandUsecase) GetUserByID(userID string) (*models.User, error) {
+	user, err := uc.Repo.FindUser(userID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, db.ErrNotFound) {
 			return nil, errors.New("user not found")
 		}
 		return nil, err
 	}
 
-	// Check if the password is correct
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+	return user, nil
+}
+
+func (uc *UserCommandUsecase) FindUserByEmailAndPassword(req *models.LoginRequest) (*models.User, error) {
+	// Find the user by email, whatever its case
+	user, err := uc.Repo.FindUserByLowerEmail(strings.ToLower(strings.TrimSpace(req.Email)))
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return nil, err
+	}
+
+	// Check if the password is correct, failing the same way for unknown
+	// emails
+	hash := ""
+	if user != nil {
+		hash = user.Password
+	}
+	err = comparePassword(hash, req.Password)
 	if err != nil {
-		return nil, errors.New("incorrect password")
+		return nil, err
 	}
 
-	return &user, nil
+	return user, nil
 }
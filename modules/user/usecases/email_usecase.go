@@ -0,0 +1,150 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jeksilaen/api-builder/db"
+	"github.com/jeksilaen/api-builder/modules/user/helpers"
+	"github.com/jeksilaen/api-builder/modules/user/models"
+	"github.com/jeksilaen/api-builder/modules/user/repositories"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// How long the tokens sent by email can be used.
+const (
+	verifyEmailTTL   = 48 * time.Hour
+	resetPasswordTTL = time.Hour
+)
+
+// ErrInvalidUserToken is returned for tokens that don't exist, expired or
+// were already used.
+var ErrInvalidUserToken = errors.New("Invalid or expired token")
+
+// SendVerificationEmail sends a link confirming the email address of the
+// user.
+func (uc *UserCommandUsecase) SendVerificationEmail(ctx context.Context, user *models.User) error {
+	if user.EmailVerifiedAt != nil {
+		return errors.New("email already verified")
+	}
+
+	token, err := uc.createUserToken(ctx, user, models.TokenVerifyEmail, verifyEmailTTL)
+	if err != nil {
+		return err
+	}
+
+	return uc.Mailer.Send(helpers.VerificationEmail(uc.AppURL, user.Username, user.Email, token))
+}
+
+// VerifyEmail marks the email address a verification token was sent to as
+// verified.
+func (uc *UserCommandUsecase) VerifyEmail(token string) (*models.User, error) {
+	var user *models.User
+	err := uc.Repo.Transaction(func(repo repositories.UserRepository) error {
+		userToken, err := useUserToken(repo, token, models.TokenVerifyEmail)
+		if err != nil {
+			return err
+		}
+
+		// The address may have changed since the email was sent
+		user, err = repo.FindUser(userToken.UserID)
+		if err != nil || user.Email != userToken.Email {
+			return ErrInvalidUserToken
+		}
+
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		return repo.UpdateUser(user, map[string]interface{}{"email_verified_at": now})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ForgotPassword sends a password reset link when an account uses the
+// email. Unknown addresses are ignored so they can't be told apart.
+func (uc *UserCommandUsecase) ForgotPassword(ctx context.Context, email string) error {
+	user, err := uc.Repo.FindUserByLowerEmail(strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := uc.createUserToken(ctx, user, models.TokenResetPassword, resetPasswordTTL)
+	if err != nil {
+		return err
+	}
+
+	return uc.Mailer.Send(helpers.ResetPasswordEmail(uc.AppURL, user.Username, user.Email, token))
+}
+
+// ResetPassword sets a new password with a reset token. The other reset
+// tokens of the user stop working and every session is ended.
+func (uc *UserCommandUsecase) ResetPassword(token string, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return uc.Repo.Transaction(func(repo repositories.UserRepository) error {
+		userToken, err := useUserToken(repo, token, models.TokenResetPassword)
+		if err != nil {
+			return err
+		}
+
+		if err := repo.SetPassword(userToken.UserID, string(hashedPassword)); err != nil {
+			return err
+		}
+		if err := repo.UseUserTokens(userToken.UserID, models.TokenResetPassword); err != nil {
+			return err
+		}
+
+		return repo.RevokeAllSessions(userToken.UserID)
+	})
+}
+
+func (uc *UserCommandUsecase) createUserToken(ctx context.Context, user *models.User, purpose string, ttl time.Duration) (string, error) {
+	token, err := helpers.NewUserToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = uc.Repo.CreateUserToken(&models.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		Email:     user.Email,
+		TokenHash: helpers.HashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Error creating user token", "error", err)
+		return "", err
+	}
+
+	return token, nil
+}
+
+// useUserToken marks a token as used, failing if it was used before.
+func useUserToken(repo repositories.UserRepository, token string, purpose string) (*models.UserToken, error) {
+	userToken, err := repo.FindUserToken(helpers.HashToken(token), purpose)
+	if err != nil || userToken.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvalidUserToken
+	}
+
+	used, err := repo.UseUserToken(userToken.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, ErrInvalidUserToken
+	}
+
+	return userToken, nil
+}
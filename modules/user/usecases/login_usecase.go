@@ -0,0 +1,236 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeksilaen/api-builder/db"
+	"github.com/jeksilaen/api-builder/modules/user/models"
+	"github.com/jeksilaen/api-builder/modules/user/repositories"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidCredentials is returned for every failed login, so it doesn't
+// tell whether an account uses the email.
+var ErrInvalidCredentials = errors.New("Invalid email or password")
+
+// ErrTooManyLoginAttempts is matched by the LoginThrottledError returned
+// while logins are slowed down or locked.
+var ErrTooManyLoginAttempts = errors.New("Too many failed login attempts, try again later")
+
+// LoginThrottledError tells when the next login can be tried.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return ErrTooManyLoginAttempts.Error()
+}
+
+func (e *LoginThrottledError) Is(target error) bool {
+	return target == ErrTooManyLoginAttempts
+}
+
+// loginPolicy slows down failed logins of an email or an IP address. After
+// FreeAttempts failures every attempt waits for a delay doubling from
+// loginBaseDelay, and LockAfter failures lock logins for loginLockout.
+type loginPolicy struct {
+	FreeAttempts int
+	LockAfter    int
+}
+
+var (
+	accountLoginPolicy = loginPolicy{FreeAttempts: 3, LockAfter: 10}
+	ipLoginPolicy      = loginPolicy{FreeAttempts: 20, LockAfter: 100}
+)
+
+const (
+	// loginWindow is how far back failed attempts count
+	loginWindow      = 15 * time.Minute
+	loginLockout     = 15 * time.Minute
+	loginBaseDelay   = time.Second
+	loginMaxDelay    = time.Minute
+	loginAttemptsTTL = 24 * time.Hour
+)
+
+var (
+	dummyPasswordHash     []byte
+	dummyPasswordHashOnce sync.Once
+)
+
+// Login checks the password of the account using the email, from ip. Failed
+// attempts are counted per email and per IP address; too many of them make
+// further attempts wait and then lock them for a while.
+func (uc *UserCommandUsecase) Login(ctx context.Context, req *models.LoginRequest, ip string) (*models.User, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	attempt, err := uc.reserveLoginAttempt(email, ip)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := uc.FindUserByEmailAndPassword(req)
+	if err != nil && !errors.Is(err, ErrInvalidCredentials) {
+		return nil, err
+	}
+
+	if err := uc.settleLoginAttempt(ctx, attempt, user != nil); err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+// PruneLoginAttempts forgets the login attempts nobody looks at anymore.
+func (uc *UserCommandUsecase) PruneLoginAttempts(ctx context.Context) error {
+	return uc.Repo.DeleteLoginAttemptsBefore(time.Now().Add(-loginAttemptsTTL))
+}
+
+// RecordAuditEvent stores an event of the audit log.
+func (uc *UserCommandUsecase) RecordAuditEvent(ctx context.Context, userID string, event string, ip string, detail string) error {
+	err := uc.Repo.CreateAuditEvent(&models.AuditEvent{UserID: userID, Event: event, IP: ip, Detail: detail})
+	if err != nil {
+		slog.ErrorContext(ctx, "Error recording audit event", "error", err)
+	}
+	return err
+}
+
+// reserveLoginAttempt stores a failed attempt of email from ip unless they
+// have to wait, in which case it returns a LoginThrottledError. The check
+// and the attempt are stored at once, so concurrent guesses all count.
+func (uc *UserCommandUsecase) reserveLoginAttempt(email string, ip string) (*models.LoginAttempt, error) {
+	attempt := &models.LoginAttempt{Email: email, IP: ip}
+	err := uc.Repo.Transaction(func(repo repositories.UserRepository) error {
+		if err := repo.LockLoginAttempts(email, ip); err != nil {
+			return err
+		}
+
+		wait, err := loginWait(repo, email, ip)
+		if err != nil {
+			return err
+		}
+		if wait > 0 {
+			return &LoginThrottledError{RetryAfter: wait}
+		}
+
+		return repo.CreateLoginAttempt(attempt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+// loginWait returns how long logins of email from ip have to wait.
+func loginWait(repo repositories.UserRepository, email string, ip string) (time.Duration, error) {
+	accountFailures, err := recentLoginFailures(repo, "email", email, accountLoginPolicy.LockAfter, true)
+	if err != nil {
+		return 0, err
+	}
+	ipFailures, err := recentLoginFailures(repo, "ip", ip, ipLoginPolicy.LockAfter, false)
+	if err != nil {
+		return 0, err
+	}
+
+	wait := accountLoginPolicy.wait(accountFailures)
+	if ipWait := ipLoginPolicy.wait(ipFailures); ipWait > wait {
+		wait = ipWait
+	}
+	return wait, nil
+}
+
+// recentLoginFailures returns the times of the last failed attempts whose
+// column is value within loginWindow, newest first. A successful login of an
+// account clears its failures when sinceSuccess is set.
+func recentLoginFailures(repo repositories.UserRepository, column string, value string, limit int, sinceSuccess bool) ([]time.Time, error) {
+	since := time.Now().Add(-loginWindow)
+
+	if sinceSuccess {
+		successes, err := repo.FindLoginAttemptTimes(column, value, true, since, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(successes) > 0 {
+			since = successes[0]
+		}
+	}
+
+	return repo.FindLoginAttemptTimes(column, value, false, since, limit)
+}
+
+// wait returns how long to wait after failures, given newest first.
+func (policy loginPolicy) wait(failures []time.Time) time.Duration {
+	if len(failures) < policy.FreeAttempts {
+		return 0
+	}
+
+	delay := loginLockout
+	if len(failures) < policy.LockAfter {
+		delay = loginBaseDelay << (len(failures) - policy.FreeAttempts)
+		if delay > loginMaxDelay {
+			delay = loginMaxDelay
+		}
+	}
+
+	return time.Until(failures[0].Add(delay))
+}
+
+// settleLoginAttempt marks a reserved attempt as successful, or logs the
+// lockouts it causes to the audit log when it failed.
+func (uc *UserCommandUsecase) settleLoginAttempt(ctx context.Context, attempt *models.LoginAttempt, succeeded bool) error {
+	if succeeded {
+		return uc.Repo.SucceedLoginAttempt(attempt.ID)
+	}
+
+	email, ip := attempt.Email, attempt.IP
+	accountFailures, err := recentLoginFailures(uc.Repo, "email", email, accountLoginPolicy.LockAfter, true)
+	if err != nil {
+		return err
+	}
+	if len(accountFailures) == accountLoginPolicy.LockAfter {
+		locked, err := uc.Repo.FindUserByLowerEmail(email)
+		if err != nil && !errors.Is(err, db.ErrNotFound) {
+			return err
+		}
+		userID := ""
+		if locked != nil {
+			userID = locked.ID
+		}
+		uc.RecordAuditEvent(ctx, userID, models.AuditAccountLocked, ip, fmt.Sprintf("Logins of %s locked for %s after %d failed attempts", email, loginLockout, len(accountFailures)))
+	}
+
+	ipFailures, err := recentLoginFailures(uc.Repo, "ip", ip, ipLoginPolicy.LockAfter, false)
+	if err != nil {
+		return err
+	}
+	if len(ipFailures) == ipLoginPolicy.LockAfter {
+		uc.RecordAuditEvent(ctx, "", models.AuditIPLocked, ip, fmt.Sprintf("Logins from %s locked for %s after %d failed attempts", ip, loginLockout, len(ipFailures)))
+	}
+
+	return nil
+}
+
+// comparePassword checks password against hash. Without a hash it compares
+// against a dummy one, so unknown emails take as long as wrong passwords.
+func comparePassword(hash string, password string) error {
+	if hash == "" {
+		dummyPasswordHashOnce.Do(func() {
+			dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+		})
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return ErrInvalidCredentials
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
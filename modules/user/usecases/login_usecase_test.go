@@ -0,0 +1,28 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jeksilaen/api-builder/modules/user/models"
+)
+
+func TestLoginIgnoresEmailCase(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	user, err := uc.CreateUser(context.Background(), &models.User{Email: "Alice@Example.com", Username: "alice", Password: "secret-alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, email := range []string{"Alice@Example.com", "alice@example.com", " ALICE@EXAMPLE.COM "} {
+		loggedIn, err := uc.Login(context.Background(), &models.LoginRequest{Email: email, Password: "secret-alice"}, "192.0.2.1")
+		if err != nil || loggedIn.ID != user.ID {
+			t.Errorf("%q: user = %v, %v, want alice", email, loggedIn, err)
+		}
+	}
+
+	if _, err := uc.Login(context.Background(), &models.LoginRequest{Email: "alice@example.com", Password: "wrong"}, "192.0.2.1"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("wrong password: %v, want ErrInvalidCredentials", err)
+	}
+}
@@ -0,0 +1,192 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jeksilaen/api-builder/db"
+	"github.com/jeksilaen/api-builder/modules/user/helpers"
+	"github.com/jeksilaen/api-builder/modules/user/models"
+	"github.com/jeksilaen/api-builder/sso"
+)
+
+// Errors returned by single sign-on logins.
+var (
+	ErrSSODisabled           = errors.New("Single sign-on is not configured")
+	ErrInvalidSSOState       = errors.New("Invalid or expired single sign-on login, start again")
+	ErrSSOEmailNotVerified   = errors.New("The identity provider didn't verify the email address")
+	ErrSSOIdentityIncomplete = errors.New("The identity provider didn't share an email address")
+	ErrSSOAccountUnverified  = errors.New("An account with this email address exists but the address isn't verified, verify it or log in with your password first")
+)
+
+// SSOLoginTTL is how long a user has to log in at the IdP.
+const SSOLoginTTL = 10 * time.Minute
+
+var usernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// StartSSOLogin returns the IdP address to send the user to and the binding
+// the browser must present, in a cookie, to complete the login.
+func (uc *UserCommandUsecase) StartSSOLogin() (string, string, error) {
+	provider := uc.SSO
+	if provider == nil {
+		return "", "", ErrSSODisabled
+	}
+
+	var values [4]string
+	for i := range values {
+		value, err := sso.NewRandomString()
+		if err != nil {
+			return "", "", err
+		}
+		values[i] = value
+	}
+	state, nonce, codeVerifier, binding := values[0], values[1], values[2], values[3]
+
+	err := uc.Repo.CreateSSOLogin(&models.SSOLogin{
+		StateHash:    helpers.HashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		BindingHash:  helpers.HashToken(binding),
+		ExpiresAt:    time.Now().Add(SSOLoginTTL),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return provider.AuthCodeURL(state, nonce, codeVerifier), binding, nil
+}
+
+// CompleteSSOLogin checks the code the IdP redirected back with and returns
+// the user of the identity. Identities are found by their subject, then by
+// a verified email address; unknown ones get a new account. binding must be
+// the one given to the browser that started the login, so a state can't be
+// replayed from another browser to log the victim into the attacker's
+// account.
+func (uc *UserCommandUsecase) CompleteSSOLogin(ctx context.Context, code string, state string, binding string, ip string) (*models.User, error) {
+	provider := uc.SSO
+	if provider == nil {
+		return nil, ErrSSODisabled
+	}
+
+	login, err := uc.Repo.FindUnusedSSOLogin(helpers.HashToken(state))
+	if err != nil || login.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvalidSSOState
+	}
+	if binding == "" || subtle.ConstantTimeCompare([]byte(helpers.HashToken(binding)), []byte(login.BindingHash)) != 1 {
+		return nil, ErrInvalidSSOState
+	}
+
+	// A state works once
+	used, err := uc.Repo.UseSSOLogin(login.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, ErrInvalidSSOState
+	}
+
+	identity, err := provider.Exchange(ctx, code, login.CodeVerifier, login.Nonce)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error completing single sign-on", "error", err)
+		return nil, ErrInvalidSSOState
+	}
+
+	return uc.findOrCreateSSOUser(ctx, identity, ip)
+}
+
+func (uc *UserCommandUsecase) findOrCreateSSOUser(ctx context.Context, identity *sso.Identity, ip string) (*models.User, error) {
+	user, err := uc.Repo.FindUserBySubject(identity.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, db.ErrNotFound) {
+		return nil, err
+	}
+
+	email := strings.TrimSpace(identity.Email)
+	if email == "" {
+		return nil, ErrSSOIdentityIncomplete
+	}
+	// Linking by an address the IdP didn't check would hand over accounts
+	if !identity.EmailVerified {
+		return nil, ErrSSOEmailNotVerified
+	}
+
+	now := time.Now()
+	user, err = uc.Repo.FindUserByLowerEmail(strings.ToLower(email))
+	if err == nil {
+		// Whoever registered an unverified address may not own it, linking
+		// would let them keep password access to the IdP user's account
+		if user.EmailVerifiedAt == nil {
+			return nil, ErrSSOAccountUnverified
+		}
+		if err := uc.Repo.UpdateUser(user, map[string]interface{}{"oidc_subject": identity.Subject}); err != nil {
+			return nil, err
+		}
+
+		uc.RecordAuditEvent(ctx, user.ID, models.AuditSSOLinked, ip, fmt.Sprintf("Linked single sign-on subject %s", identity.Subject))
+		return user, nil
+	}
+	if !errors.Is(err, db.ErrNotFound) {
+		return nil, err
+	}
+
+	username, err := uc.availableUsername(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	// The account has no usable password until the user resets it
+	password, err := sso.NewRandomString()
+	if err != nil {
+		return nil, err
+	}
+
+	subject := identity.Subject
+	return uc.CreateUser(ctx, &models.User{
+		Email:           email,
+		Username:        username,
+		Password:        password,
+		EmailVerifiedAt: &now,
+		OIDCSubject:     &subject,
+	})
+}
+
+// availableUsername picks a free username from the identity.
+func (uc *UserCommandUsecase) availableUsername(identity *sso.Identity) (string, error) {
+	base := identity.Username
+	if base == "" {
+		base = strings.SplitN(identity.Email, "@", 2)[0]
+	}
+	base = strings.Trim(usernameInvalidChars.ReplaceAllString(base, "-"), "-")
+	if base == "" {
+		base = "user"
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		taken, err := uc.Repo.UsernameTaken(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+
+		suffix := make([]byte, 3)
+		if _, err := rand.Read(suffix); err != nil {
+			return "", err
+		}
+		candidate = base + "-" + hex.EncodeToString(suffix)
+	}
+
+	return "", errors.New("email or username already in use")
+}
@@ -0,0 +1,117 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jeksilaen/api-builder/middlewares"
+	"github.com/jeksilaen/api-builder/modules/user/helpers"
+	"github.com/jeksilaen/api-builder/modules/user/models"
+	"github.com/jeksilaen/api-builder/modules/user/repositories"
+)
+
+// refreshTokenTTL is how long a refresh token can be used. Every refresh
+// hands out a new token, so an active session doesn't expire.
+const refreshTokenTTL = 30 * 24 * time.Hour
+
+// Errors returned when refreshing a session.
+var (
+	ErrInvalidRefreshToken = errors.New("Invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("Refresh token was already used, the session has been revoked")
+)
+
+// IssueTokens starts a login session of the user.
+func (uc *UserCommandUsecase) IssueTokens(ctx context.Context, user *models.User) (*models.TokenPair, error) {
+	return uc.issueTokens(ctx, uc.Repo, user.ID, uuid.New().String())
+}
+
+// RefreshTokens swaps a refresh token for a new access and refresh token of
+// the same session. Using a refresh token twice means it leaked, so the
+// whole session is revoked.
+func (uc *UserCommandUsecase) RefreshTokens(ctx context.Context, refreshToken string) (*models.TokenPair, error) {
+	token, err := uc.Repo.FindRefreshToken(helpers.HashToken(refreshToken))
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if token.RevokedAt != nil || token.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if token.UsedAt != nil {
+		slog.WarnContext(ctx, "Refresh token reused, revoking session", "session_id", token.FamilyID)
+		if err := uc.RevokeSession(token.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	var tokens *models.TokenPair
+	err = uc.Repo.Transaction(func(repo repositories.UserRepository) error {
+		// Only one of two concurrent refreshes can use the token
+		used, err := repo.UseRefreshToken(token.ID)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrRefreshTokenReused
+		}
+
+		tokens, err = uc.issueTokens(ctx, repo, token.UserID, token.FamilyID)
+		return err
+	})
+	if errors.Is(err, ErrRefreshTokenReused) {
+		if err := uc.RevokeSession(token.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// RevokeSession ends a login session. Its refresh tokens stop working and
+// VerifyToken refuses its access tokens.
+func (uc *UserCommandUsecase) RevokeSession(sessionID string) error {
+	return uc.Repo.RevokeSession(sessionID)
+}
+
+// RevokeAllSessions ends every login session of the user.
+func (uc *UserCommandUsecase) RevokeAllSessions(userID string) error {
+	return uc.Repo.RevokeAllSessions(userID)
+}
+
+// issueTokens starts or continues the session sessionID, storing the
+// refresh token with repo.
+func (uc *UserCommandUsecase) issueTokens(ctx context.Context, repo repositories.UserRepository, userID string, sessionID string) (*models.TokenPair, error) {
+	refreshToken, err := helpers.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.CreateRefreshToken(&models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  sessionID,
+		TokenHash: helpers.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Error creating refresh token", "error", err)
+		return nil, err
+	}
+
+	accessToken, err := uc.Keys.GenerateToken(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(middlewares.AccessTokenTTL.Seconds()),
+	}, nil
+}
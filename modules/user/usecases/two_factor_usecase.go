@@ -0,0 +1,229 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jeksilaen/api-builder/modules/user/helpers"
+	"github.com/jeksilaen/api-builder/modules/user/models"
+	"github.com/jeksilaen/api-builder/modules/user/repositories"
+)
+
+// Errors returned when setting up and using two-factor authentication.
+var (
+	ErrTwoFactorEnabled     = errors.New("Two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled  = errors.New("Two-factor authentication is not enabled")
+	ErrTwoFactorNotSetUp    = errors.New("Set up two-factor authentication first")
+	ErrInvalidTwoFactorCode = errors.New("Invalid two-factor code")
+	ErrInvalidChallenge     = errors.New("Invalid or expired login challenge, log in again")
+)
+
+// recoveryCodeCount is how many recovery codes a user gets at a time.
+const recoveryCodeCount = 10
+
+// LoginChallengeTTL is how long the second step of a login can take.
+const LoginChallengeTTL = 5 * time.Minute
+
+// SetupTwoFactor generates a TOTP secret for the user. It is only used once
+// a code of it is confirmed.
+func (uc *UserCommandUsecase) SetupTwoFactor(userID string) (*models.User, string, error) {
+	user, err := uc.GetUserByID(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, "", ErrTwoFactorEnabled
+	}
+
+	secret, err := helpers.NewTOTPSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = uc.Repo.UpdateUser(user, map[string]interface{}{"totp_secret": secret, "totp_last_step": 0})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return user, secret, nil
+}
+
+// ConfirmTwoFactor enables two-factor authentication with a code of the
+// secret from SetupTwoFactor, and returns the recovery codes.
+func (uc *UserCommandUsecase) ConfirmTwoFactor(userID string, code string) ([]string, error) {
+	user, err := uc.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, ErrTwoFactorEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotSetUp
+	}
+
+	step, ok := helpers.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	var codes []string
+	err = uc.Repo.Transaction(func(repo repositories.UserRepository) error {
+		err := repo.UpdateUser(user, map[string]interface{}{"totp_enabled_at": time.Now(), "totp_last_step": step})
+		if err != nil {
+			return err
+		}
+
+		codes, err = replaceRecoveryCodes(repo, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableTwoFactor turns two-factor authentication off after checking the
+// password and a TOTP or recovery code.
+func (uc *UserCommandUsecase) DisableTwoFactor(userID string, password string, code string) error {
+	user, err := uc.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.TOTPEnabledAt == nil {
+		return ErrTwoFactorNotEnabled
+	}
+	if comparePassword(user.Password, password) != nil {
+		return ErrIncorrectPassword
+	}
+
+	return uc.Repo.Transaction(func(repo repositories.UserRepository) error {
+		ok, err := verifySecondFactor(repo, user, code)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+
+		err = repo.UpdateUser(user, map[string]interface{}{"totp_secret": "", "totp_enabled_at": nil, "totp_last_step": 0})
+		if err != nil {
+			return err
+		}
+
+		return repo.DeleteRecoveryCodes(userID)
+	})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the user after
+// checking a TOTP or recovery code.
+func (uc *UserCommandUsecase) RegenerateRecoveryCodes(userID string, code string) ([]string, error) {
+	user, err := uc.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt == nil {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	var codes []string
+	err = uc.Repo.Transaction(func(repo repositories.UserRepository) error {
+		ok, err := verifySecondFactor(repo, user, code)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+
+		codes, err = replaceRecoveryCodes(repo, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// CreateLoginChallenge returns the token that finishes the login of a user
+// with two-factor authentication, once their password was checked.
+func (uc *UserCommandUsecase) CreateLoginChallenge(ctx context.Context, user *models.User) (string, error) {
+	return uc.createUserToken(ctx, user, models.TokenLoginChallenge, LoginChallengeTTL)
+}
+
+// CompleteLogin checks the TOTP or recovery code for a login challenge.
+// Wrong codes count as failed logins of the account.
+func (uc *UserCommandUsecase) CompleteLogin(ctx context.Context, challengeToken string, code string, ip string) (*models.User, error) {
+	challenge, err := uc.Repo.FindUserToken(helpers.HashToken(challengeToken), models.TokenLoginChallenge)
+	if err != nil || challenge.UsedAt != nil || challenge.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvalidChallenge
+	}
+
+	user, err := uc.GetUserByID(challenge.UserID)
+	if err != nil || user.TOTPEnabledAt == nil {
+		return nil, ErrInvalidChallenge
+	}
+
+	attempt, err := uc.reserveLoginAttempt(challenge.Email, ip)
+	if err != nil {
+		return nil, err
+	}
+
+	var ok bool
+	err = uc.Repo.Transaction(func(repo repositories.UserRepository) error {
+		ok, err = verifySecondFactor(repo, user, code)
+		if err != nil || !ok {
+			return err
+		}
+
+		_, err = useUserToken(repo, challengeToken, models.TokenLoginChallenge)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvalidUserToken) {
+			return nil, ErrInvalidChallenge
+		}
+		return nil, err
+	}
+
+	if err := uc.settleLoginAttempt(ctx, attempt, ok); err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	return user, nil
+}
+
+// verifySecondFactor checks a TOTP code, refusing codes used before, or
+// uses up a recovery code.
+func verifySecondFactor(repo repositories.UserRepository, user *models.User, code string) (bool, error) {
+	if step, ok := helpers.ValidateTOTP(user.TOTPSecret, code, time.Now()); ok {
+		return repo.UseTOTPStep(user.ID, step)
+	}
+
+	return repo.UseRecoveryCode(user.ID, helpers.HashToken(helpers.NormalizeRecoveryCode(code)))
+}
+
+// replaceRecoveryCodes deletes the recovery codes of the user and creates
+// new ones.
+func replaceRecoveryCodes(repo repositories.UserRepository, userID string) ([]string, error) {
+	codes, err := helpers.NewRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	codeHashes := make([]string, len(codes))
+	for i, code := range codes {
+		codeHashes[i] = helpers.HashToken(code)
+	}
+	if err := repo.ReplaceRecoveryCodes(userID, codeHashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
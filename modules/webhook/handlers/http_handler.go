@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/jeksilaen/api-builder/middlewares"
+	collectionUsecases "github.com/jeksilaen/api-builder/modules/collection/usecases"
+	"github.com/jeksilaen/api-builder/modules/webhook/helpers"
+	"github.com/jeksilaen/api-builder/modules/webhook/models"
+	"github.com/jeksilaen/api-builder/modules/webhook/usecases"
+	workspaceModels "github.com/jeksilaen/api-builder/modules/workspace/models"
+)
+
+// WebhookHttpHandler serves the webhook routes with the usecases it is given.
+type WebhookHttpHandler struct {
+	Webhooks   *usecases.WebhookCommandUsecase
+	Dispatcher *usecases.WebhookDispatcher
+}
+
+func NewWebhookHttpHandler(webhooks *usecases.WebhookCommandUsecase, dispatcher *usecases.WebhookDispatcher) *WebhookHttpHandler {
+	return &WebhookHttpHandler{
+		Webhooks:   webhooks,
+		Dispatcher: dispatcher,
+	}
+}
+
+func InitWebhookHttpHandler(router *gin.Engine, auth *middlewares.Auth, h *WebhookHttpHandler) {
+	ofWebhook := auth.WorkspaceOfRecord("webhooks", "id")
+
+	router.GET("/users/v1/webhook_by_collection/:collection_id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermRead, auth.WorkspaceOfCollection("collection_id")), h.GetWebhooksByCollection)
+	router.GET("/users/v1/webhook/:id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermRead, ofWebhook), h.GetWebhook)
+	router.POST("/users/v1/webhook", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, auth.WorkspaceOfBodyCollection("collection_id")), h.CreateWebhook)
+	router.PUT("/users/v1/webhook/:id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, ofWebhook), h.UpdateWebhook)
+	router.DELETE("/users/v1/webhook/:id", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, ofWebhook), h.DeleteWebhook)
+	router.POST("/users/v1/webhook/:id/secret", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, ofWebhook), h.RotateWebhookSecret)
+	router.POST("/users/v1/webhook/:id/test", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermEdit, ofWebhook), h.TestWebhook)
+	router.GET("/users/v1/webhook/:id/deliveries", auth.VerifyToken, auth.RequirePermission(workspaceModels.PermRead, ofWebhook), h.GetWebhookDeliveries)
+}
+
+func (h *WebhookHttpHandler) GetWebhooksByCollection(ctx *gin.Context) {
+
+	collectionID := ctx.Param("collection_id")
+
+	if collectionID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Collection ID is required"})
+		return
+	}
+
+	// Get the webhooks from usecase
+	webhooks, err := h.Webhooks.GetWebhooksByCollectionID(middlewares.GetUserID(ctx), collectionID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Webhooks not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessGetResponse(webhooks))
+}
+
+func (h *WebhookHttpHandler) GetWebhook(ctx *gin.Context) {
+
+	webhookID := ctx.Param("id")
+
+	if webhookID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Webhook ID is required"})
+		return
+	}
+
+	// Get the webhook from usecase
+	webhook, err := h.Webhooks.GetWebhookByID(middlewares.GetUserID(ctx), webhookID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessCreateResponse(webhook))
+}
+
+func (h *WebhookHttpHandler) CreateWebhook(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into WebhookRequest object
+	var req models.WebhookRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Webhooks are enabled unless asked otherwise
+	webhook := &models.Webhook{
+		CollectionID: req.CollectionID,
+		Name:         req.Name,
+		URL:          req.URL,
+		Enabled:      req.Enabled == nil || *req.Enabled,
+	}
+
+	// Create the webhook
+	createdWebhook, err := h.Webhooks.CreateWebhook(ctx.Request.Context(), middlewares.GetUserID(ctx), webhook)
+	if errors.Is(err, collectionUsecases.ErrCollectionNotFound) {
+		ctx.JSON(http.StatusNotFound, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, helpers.ReturnSucessSecretResponse(createdWebhook))
+}
+
+func (h *WebhookHttpHandler) UpdateWebhook(ctx *gin.Context) {
+	validate := validator.New()
+
+	webhookID := ctx.Param("id")
+
+	if webhookID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Webhook ID is required"})
+		return
+	}
+
+	// Decode the request JSON data into WebhookRequest object
+	var req models.WebhookRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.StructPartial(req, "Name", "URL")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Get the existing webhook from usecase
+	existingWebhook, err := h.Webhooks.GetWebhookByID(middlewares.GetUserID(ctx), webhookID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	existingWebhook.Name = req.Name
+	existingWebhook.URL = req.URL
+	if req.Enabled != nil {
+		existingWebhook.Enabled = *req.Enabled
+	}
+
+	// Save the updated webhook
+	updatedWebhook, err := h.Webhooks.UpdateWebhook(existingWebhook)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessCreateResponse(updatedWebhook))
+}
+
+func (h *WebhookHttpHandler) DeleteWebhook(ctx *gin.Context) {
+
+	webhookID := ctx.Param("id")
+
+	if webhookID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Webhook ID is required"})
+		return
+	}
+
+	// Delete the webhook with its delivery log
+	err := h.Webhooks.DeleteWebhook(middlewares.GetUserID(ctx), webhookID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessDeleteResponse("Deleted Webhook Successfully"))
+}
+
+func (h *WebhookHttpHandler) RotateWebhookSecret(ctx *gin.Context) {
+
+	webhookID := ctx.Param("id")
+
+	if webhookID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Webhook ID is required"})
+		return
+	}
+
+	webhook, err := h.Webhooks.GetWebhookByID(middlewares.GetUserID(ctx), webhookID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	// Replace the signing secret; the old one stops working right away
+	rotatedWebhook, err := h.Webhooks.RotateWebhookSecret(webhook)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessSecretResponse(rotatedWebhook))
+}
+
+func (h *WebhookHttpHandler) TestWebhook(ctx *gin.Context) {
+
+	webhookID := ctx.Param("id")
+
+	if webhookID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Webhook ID is required"})
+		return
+	}
+
+	webhook, err := h.Webhooks.GetWebhookByID(middlewares.GetUserID(ctx), webhookID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	// Send a test event and report how the receiver answered
+	delivery, err := h.Dispatcher.SendTestEvent(ctx.Request.Context(), webhook)
+	if delivery == nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedDeliveryResponse(err.Error()))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadGateway, helpers.ReturnSucessDeliveryResponse(delivery))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessDeliveryResponse(delivery))
+}
+
+func (h *WebhookHttpHandler) GetWebhookDeliveries(ctx *gin.Context) {
+
+	webhookID := ctx.Param("id")
+
+	if webhookID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Webhook ID is required"})
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+		return
+	}
+
+	// Get the latest deliveries from usecase
+	deliveries, err := h.Webhooks.GetDeliveriesByWebhookID(middlewares.GetUserID(ctx), webhookID, limit)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Webhook deliveries not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessGetDeliveriesResponse(deliveries))
+}
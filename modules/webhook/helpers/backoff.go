@@ -0,0 +1,16 @@
+package helpers
+
+import "time"
+
+// Backoff returns the wait before retry number attempt (starting at 1),
+// doubling from base up to max.
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	wait := base
+	for i := 1; i < attempt; i++ {
+		wait *= 2
+		if wait >= max {
+			return max
+		}
+	}
+	return wait
+}
@@ -0,0 +1,143 @@
+package helpers
+
+import (
+	"encoding/json"
+
+	"github.com/jeksilaen/api-builder/modules/webhook/models"
+)
+
+func ReturnFailedCreateResponse(message string) *models.FailedResponse {
+	return &models.FailedResponse{
+		Error:   "Create failed",
+		Message: message,
+		Links: []models.Link{
+			{
+				Rel:  "create webhook",
+				Href: "/users/v1/webhook",
+			},
+		},
+	}
+}
+
+func ReturnFailedDeliveryResponse(message string) *models.FailedResponse {
+	return &models.FailedResponse{
+		Error:   "Delivery failed",
+		Message: message,
+		Links: []models.Link{
+			{
+				Rel:  "get webhook deliveries",
+				Href: "/users/v1/webhook/:id/deliveries",
+			},
+		},
+	}
+}
+
+func toWebhookResponse(webhook *models.Webhook) models.WebhookResponse {
+	return models.WebhookResponse{
+		ID:           webhook.ID,
+		CollectionID: webhook.CollectionID,
+		Name:         webhook.Name,
+		URL:          webhook.URL,
+		Enabled:      webhook.Enabled,
+	}
+}
+
+func toDeliveryResponse(delivery *models.WebhookDelivery) models.WebhookDeliveryResponse {
+	return models.WebhookDeliveryResponse{
+		ID:          delivery.ID,
+		WebhookID:   delivery.WebhookID,
+		Event:       delivery.Event,
+		Payload:     json.RawMessage(delivery.Payload),
+		Success:     delivery.Success,
+		StatusCode:  delivery.StatusCode,
+		Attempts:    delivery.Attempts,
+		CreatedAt:   delivery.CreatedAt,
+		DeliveredAt: delivery.DeliveredAt,
+	}
+}
+
+// ReturnSucessSecretResponse includes the signing secret, which is only
+// shown when a webhook is created or its secret is rotated.
+func ReturnSucessSecretResponse(webhook *models.Webhook) *models.SucessCreateResponse {
+	response := ReturnSucessCreateResponse(webhook)
+	response.Data.Secret = webhook.Secret
+	return response
+}
+
+func ReturnSucessCreateResponse(webhook *models.Webhook) *models.SucessCreateResponse {
+	return &models.SucessCreateResponse{
+		Message: "Save Webhook sucessfully",
+		Data:    toWebhookResponse(webhook),
+		Links: []models.Link{
+			{
+				Rel:  "test webhook",
+				Href: "/users/v1/webhook/" + webhook.ID + "/test",
+			},
+			{
+				Rel:  "get webhook deliveries",
+				Href: "/users/v1/webhook/" + webhook.ID + "/deliveries",
+			},
+		},
+	}
+}
+
+func ReturnSucessGetResponse(webhooks []*models.Webhook) *models.SucessGetResponse {
+	webhookResponses := []models.WebhookResponse{}
+	for _, webhook := range webhooks {
+		webhookResponses = append(webhookResponses, toWebhookResponse(webhook))
+	}
+
+	return &models.SucessGetResponse{
+		Message: "Get Webhooks sucessfully",
+		Data:    webhookResponses,
+		Links: []models.Link{
+			{
+				Rel:  "create webhook",
+				Href: "/users/v1/webhook",
+			},
+		},
+	}
+}
+
+func ReturnSucessDeliveryResponse(delivery *models.WebhookDelivery) *models.SucessDeliveryResponse {
+	return &models.SucessDeliveryResponse{
+		Message: "Delivered Webhook sucessfully",
+		Data:    toDeliveryResponse(delivery),
+		Links: []models.Link{
+			{
+				Rel:  "get webhook deliveries",
+				Href: "/users/v1/webhook/" + delivery.WebhookID + "/deliveries",
+			},
+		},
+	}
+}
+
+func ReturnSucessGetDeliveriesResponse(deliveries []*models.WebhookDelivery) *models.SucessGetDeliveriesResponse {
+	deliveryResponses := []models.WebhookDeliveryResponse{}
+	for _, delivery := range deliveries {
+		deliveryResponses = append(deliveryResponses, toDeliveryResponse(delivery))
+	}
+
+	return &models.SucessGetDeliveriesResponse{
+		Message: "Get Webhook Deliveries sucessfully",
+		Data:    deliveryResponses,
+		Links: []models.Link{
+			{
+				Rel:  "get webhooks by collection",
+				Href: "/users/v1/webhook_by_collection/:collection_id",
+			},
+		},
+	}
+}
+
+func ReturnSucessDeleteResponse(message string) *models.SucessDeleteResponse {
+	return &models.SucessDeleteResponse{
+		Message: message,
+		Links: []models.Link{
+			{
+				Rel:  "create webhook",
+				Href: "/users/v1/webhook",
+			},
+		},
+	}
+}
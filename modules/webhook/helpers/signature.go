@@ -0,0 +1,65 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers set on every webhook delivery.
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// signaturePrefix names the algorithm in the signature header value.
+const signaturePrefix = "sha256="
+
+// NewSecret returns a random signing secret.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature header value for body sent at timestamp. The
+// timestamp is part of the signed content so a captured delivery can't be
+// replayed later with a fresh timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks a delivery the way a receiver should: the signature
+// must match and the timestamp must be within tolerance of now.
+func VerifySignature(secret, signature, timestamp string, body []byte, tolerance time.Duration, now time.Time) error {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return errors.New("missing or unsupported signature")
+	}
+
+	sentAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid timestamp")
+	}
+	if age := now.Sub(time.Unix(sentAt, 0)); age > tolerance || age < -tolerance {
+		return errors.New("timestamp outside of tolerance")
+	}
+
+	expected := Sign(secret, sentAt, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
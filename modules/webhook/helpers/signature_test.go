@@ -0,0 +1,73 @@
+package helpers
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	// HMAC-SHA256 of "1700000000.{}" with the key "whsec_test"
+	signature := Sign("whsec_test", 1700000000, []byte("{}"))
+	if want := "sha256=35495024f4ef3f94e5a93e22221544c4b75e9a42300cd965ab81cb85cd994e91"; signature != want {
+		t.Fatalf("Sign = %q, want %q", signature, want)
+	}
+	if Sign("whsec_test", 1700000001, []byte("{}")) == signature {
+		t.Error("the timestamp is not signed")
+	}
+	if Sign("whsec_other", 1700000000, []byte("{}")) == signature {
+		t.Error("the secret is not used")
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"event":"run.failed"}`)
+	secret, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := Sign(secret, now.Unix(), body)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	if err := VerifySignature(secret, signature, timestamp, body, 5*time.Minute, now.Add(time.Minute)); err != nil {
+		t.Errorf("valid delivery: %v", err)
+	}
+
+	tests := map[string]struct {
+		secret    string
+		signature string
+		timestamp string
+		body      string
+		now       time.Time
+	}{
+		"wrong secret":      {"whsec_other", signature, timestamp, string(body), now},
+		"changed body":      {secret, signature, timestamp, `{"event":"test"}`, now},
+		"changed timestamp": {secret, signature, strconv.FormatInt(now.Unix()+1, 10), string(body), now},
+		"no prefix":         {secret, strings.TrimPrefix(signature, "sha256="), timestamp, string(body), now},
+		"invalid timestamp": {secret, signature, "yesterday", string(body), now},
+		"too old":           {secret, signature, timestamp, string(body), now.Add(6 * time.Minute)},
+		"from the future":   {secret, signature, timestamp, string(body), now.Add(-6 * time.Minute)},
+	}
+	for name, test := range tests {
+		if err := VerifySignature(test.secret, test.signature, test.timestamp, []byte(test.body), 5*time.Minute, test.now); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := map[int]time.Duration{
+		1: time.Second,
+		2: 2 * time.Second,
+		3: 4 * time.Second,
+		5: 10 * time.Second,
+		9: 10 * time.Second,
+	}
+	for attempt, want := range tests {
+		if wait := Backoff(attempt, time.Second, 10*time.Second); wait != want {
+			t.Errorf("Backoff(%d) = %v, want %v", attempt, wait, want)
+		}
+	}
+}
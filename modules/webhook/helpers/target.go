@@ -0,0 +1,58 @@
+package helpers
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenTarget is returned when a webhook URL resolves to an address
+// of the server's own networks.
+var ErrForbiddenTarget = errors.New("webhook URL resolves to a loopback, private or link-local address")
+
+// IsPublicAddress reports whether webhooks may be posted to ip: loopback,
+// private, link-local, multicast and unspecified addresses would let a
+// webhook reach the services next to the server.
+func IsPublicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() &&
+		!ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified()
+}
+
+// NewClient returns a client that only connects to public addresses, unless
+// allowPrivate is set. The address is checked once resolved, right before
+// dialing, so neither DNS answers nor redirects can point it at the internal
+// network.
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, conn syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !IsPublicAddress(addrPort.Addr()) {
+				return ErrForbiddenTarget
+			}
+			return nil
+		}
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// Dial the webhook itself, a proxy would hide its address
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
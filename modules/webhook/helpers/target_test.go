@@ -0,0 +1,56 @@
+package helpers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestIsPublicAddress(t *testing.T) {
+	tests := map[string]bool{
+		"93.184.216.34":      true,
+		"2606:4700::1111":    true,
+		"127.0.0.1":          false,
+		"::1":                false,
+		"10.1.2.3":           false,
+		"172.16.0.1":         false,
+		"192.168.1.1":        false,
+		"169.254.169.254":    false,
+		"fe80::1":            false,
+		"fd00::1":            false,
+		"0.0.0.0":            false,
+		"224.0.0.1":          false,
+		"::ffff:127.0.0.1":   false,
+		"::ffff:192.168.0.1": false,
+	}
+
+	for address, want := range tests {
+		if public := IsPublicAddress(netip.MustParseAddr(address)); public != want {
+			t.Errorf("IsPublicAddress(%s) = %v, want %v", address, public, want)
+		}
+	}
+}
+
+func TestNewClientRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, err := NewClient(time.Second, false).Post(server.URL, "application/json", nil)
+	if !errors.Is(err, ErrForbiddenTarget) {
+		t.Errorf("error = %v, want ErrForbiddenTarget", err)
+	}
+}
+
+func TestNewClientAllowsPrivateTargets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	response, err := NewClient(time.Second, true).Post(server.URL, "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+}
@@ -0,0 +1,163 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+	"gorm.io/gorm"
+)
+
+// Events sent to webhooks.
+const (
+	EventRunFailed     = "run.failed"
+	EventMonitorFailed = "monitor.failed"
+	EventTest          = "webhook.test"
+)
+
+// Webhook receives a signed JSON payload when a run of its collection fails.
+type Webhook struct {
+	gorm.Model
+	ID           string                      `gorm:"type:uuid;primaryKey"`
+	CollectionID string                      `json:"collection_id" gorm:"type:uuid;not null;index"`
+	Name         string                      `json:"name"`
+	URL          string                      `json:"url"`
+	Secret       string                      `json:"-"`
+	Enabled      bool                        `json:"enabled"`
+	Collection   collectionModels.Collection `gorm:"foreignKey:CollectionID" json:"-"`
+}
+
+// WebhookDelivery logs one event sent to a webhook with all of its attempts.
+type WebhookDelivery struct {
+	gorm.Model
+	ID          string          `gorm:"type:uuid;primaryKey"`
+	WebhookID   string          `json:"webhook_id" gorm:"type:uuid;not null;index"`
+	Event       string          `json:"event"`
+	Payload     string          `json:"payload" gorm:"type:text"`
+	Success     bool            `json:"success"`
+	StatusCode  int             `json:"status_code"`
+	Attempts    WebhookAttempts `json:"attempts" gorm:"type:json"`
+	DeliveredAt *time.Time      `json:"delivered_at"`
+}
+
+// WebhookAttempt is one POST of a delivery.
+type WebhookAttempt struct {
+	At           time.Time `json:"at"`
+	StatusCode   int       `json:"status_code"`
+	ResponseTime int64     `json:"response_time"`
+	Error        string    `json:"error,omitempty"`
+}
+
+type WebhookAttempts []WebhookAttempt
+
+// Scan converts JSON data from the database into WebhookAttempts.
+func (a *WebhookAttempts) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("Failed to unmarshal WebhookAttempts")
+	}
+	return json.Unmarshal(b, a)
+}
+
+// Value converts WebhookAttempts into JSON for storage in the database.
+func (a WebhookAttempts) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
+
+// WebhookPayload is the JSON body posted to a webhook.
+type WebhookPayload struct {
+	ID           string                   `json:"id"`
+	Event        string                   `json:"event"`
+	CollectionID string                   `json:"collection_id"`
+	MonitorID    string                   `json:"monitor_id,omitempty"`
+	SentAt       time.Time                `json:"sent_at"`
+	Report       *requestModels.RunReport `json:"report,omitempty"`
+	Failures     requestModels.RunResults `json:"failures,omitempty"`
+}
+
+type WebhookRequest struct {
+	CollectionID string `json:"collection_id" validate:"required"`
+	Name         string `json:"name" validate:"required"`
+	URL          string `json:"url" validate:"required,http_url"`
+	Enabled      *bool  `json:"enabled"`
+}
+
+type WebhookResponse struct {
+	ID           string `json:"id"`
+	CollectionID string `json:"collection_id"`
+	Name         string `json:"name"`
+	URL          string `json:"url"`
+	Enabled      bool   `json:"enabled"`
+	Secret       string `json:"secret,omitempty"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID          string          `json:"id"`
+	WebhookID   string          `json:"webhook_id"`
+	Event       string          `json:"event"`
+	Payload     json.RawMessage `json:"payload"`
+	Success     bool            `json:"success"`
+	StatusCode  int             `json:"status_code"`
+	Attempts    WebhookAttempts `json:"attempts"`
+	CreatedAt   time.Time       `json:"created_at"`
+	DeliveredAt *time.Time      `json:"delivered_at"`
+}
+
+type SucessCreateResponse struct {
+	Message string          `json:"message"`
+	Data    WebhookResponse `json:"data"`
+	Links   []Link          `json:"links"`
+}
+
+type SucessGetResponse struct {
+	Message string            `json:"message"`
+	Data    []WebhookResponse `json:"data"`
+	Links   []Link            `json:"links"`
+}
+
+type SucessDeliveryResponse struct {
+	Message string                  `json:"message"`
+	Data    WebhookDeliveryResponse `json:"data"`
+	Links   []Link                  `json:"links"`
+}
+
+type SucessGetDeliveriesResponse struct {
+	Message string                    `json:"message"`
+	Data    []WebhookDeliveryResponse `json:"data"`
+	Links   []Link                    `json:"links"`
+}
+
+type SucessDeleteResponse struct {
+	Message string `json:"message"`
+	Links   []Link `json:"links"`
+}
+
+type FailedResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+	Links   []Link `json:"links"`
+}
+
+type Link struct {
+	Rel  string `json:"rel"`
+	Href string `json:"href"`
+}
+
+func (webhook *Webhook) BeforeCreate(tx *gorm.DB) error {
+	webhook.ID = uuid.New().String()
+	return nil
+}
+
+func (delivery *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if delivery.ID == "" {
+		delivery.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	collectionRepositories "github.com/jeksilaen/api-builder/modules/collection/repositories"
+	"github.com/jeksilaen/api-builder/modules/webhook/models"
+	"gorm.io/gorm"
+)
+
+// GormWebhookRepository stores webhooks with gorm, on any of the databases
+// of db.Open.
+type GormWebhookRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormWebhookRepository(db *gorm.DB) *GormWebhookRepository {
+	return &GormWebhookRepository{DB: db}
+}
+
+func (r *GormWebhookRepository) FindAccessibleByCollectionID(userID string, collectionID string) ([]*models.Webhook, error) {
+	var webhooks []*models.Webhook
+	err := r.DB.Scopes(collectionRepositories.CollectionAccessibleBy(userID)).Where("collection_id = ?", collectionID).Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *GormWebhookRepository) FindAccessible(userID string, webhookID string) (*models.Webhook, error) {
+	var webhook models.Webhook
+	err := r.DB.Scopes(collectionRepositories.CollectionAccessibleBy(userID)).Where("id = ?", webhookID).First(&webhook).Error
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *GormWebhookRepository) FindEnabledByCollectionID(collectionID string) ([]*models.Webhook, error) {
+	var webhooks []*models.Webhook
+	err := r.DB.Where("collection_id = ? AND enabled = ?", collectionID, true).Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *GormWebhookRepository) Create(webhook *models.Webhook) error {
+	return r.DB.Omit("Collection").Create(webhook).Error
+}
+
+func (r *GormWebhookRepository) Save(webhook *models.Webhook) error {
+	return r.DB.Omit("Collection").Save(webhook).Error
+}
+
+func (r *GormWebhookRepository) Delete(webhook *models.Webhook) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", webhook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(webhook).Error
+	})
+}
+
+func (r *GormWebhookRepository) DeleteByCollections(collectionIDs []string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		webhookIDs := tx.Unscoped().Model(&models.Webhook{}).Select("id").Where("collection_id IN ?", collectionIDs)
+		if err := tx.Unscoped().Where("webhook_id IN (?)", webhookIDs).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("collection_id IN ?", collectionIDs).Delete(&models.Webhook{}).Error
+	})
+}
+
+func (r *GormWebhookRepository) FindDeliveries(webhookID string, limit int) ([]*models.WebhookDelivery, error) {
+	var deliveries []*models.WebhookDelivery
+	err := r.DB.Where("webhook_id = ?", webhookID).Order("created_at desc").Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *GormWebhookRepository) CreateDelivery(delivery *models.WebhookDelivery) error {
+	return r.DB.Create(delivery).Error
+}
+
+func (r *GormWebhookRepository) SaveDelivery(delivery *models.WebhookDelivery) error {
+	return r.DB.Save(delivery).Error
+}
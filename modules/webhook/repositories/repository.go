@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"github.com/jeksilaen/api-builder/modules/webhook/models"
+)
+
+// WebhookRepository stores the webhooks of collections and the log of their
+// deliveries. Lookups of a missing record fail with db.ErrNotFound.
+type WebhookRepository interface {
+	// FindAccessibleByCollectionID returns the webhooks of a collection in
+	// a workspace of userID
+	FindAccessibleByCollectionID(userID string, collectionID string) ([]*models.Webhook, error)
+	// FindAccessible returns the webhook if its collection is in a
+	// workspace of userID
+	FindAccessible(userID string, webhookID string) (*models.Webhook, error)
+	FindEnabledByCollectionID(collectionID string) ([]*models.Webhook, error)
+	Create(webhook *models.Webhook) error
+	Save(webhook *models.Webhook) error
+	// Delete deletes the webhook with its deliveries
+	Delete(webhook *models.Webhook) error
+	// DeleteByCollections deletes for good the webhooks of the collections,
+	// soft deleted ones too, with their deliveries
+	DeleteByCollections(collectionIDs []string) error
+	// FindDeliveries returns the last deliveries of a webhook, newest first
+	FindDeliveries(webhookID string, limit int) ([]*models.WebhookDelivery, error)
+	CreateDelivery(delivery *models.WebhookDelivery) error
+	SaveDelivery(delivery *models.WebhookDelivery) error
+}
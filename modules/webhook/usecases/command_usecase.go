@@ -0,0 +1,113 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/jeksilaen/api-builder/db"
+	collectionUsecases "github.com/jeksilaen/api-builder/modules/collection/usecases"
+	"github.com/jeksilaen/api-builder/modules/webhook/helpers"
+	"github.com/jeksilaen/api-builder/modules/webhook/models"
+	"github.com/jeksilaen/api-builder/modules/webhook/repositories"
+)
+
+type WebhookCommandUsecase struct {
+	Repo        repositories.WebhookRepository
+	Collections *collectionUsecases.CollectionCommandUsecase
+}
+
+func NewWebhookCommandUsecase(repo repositories.WebhookRepository, collections *collectionUsecases.CollectionCommandUsecase) *WebhookCommandUsecase {
+	return &WebhookCommandUsecase{
+		Repo:        repo,
+		Collections: collections,
+	}
+}
+
+func (uc *WebhookCommandUsecase) GetWebhooksByCollectionID(userID string, collectionID string) ([]*models.Webhook, error) {
+	webhooks, err := uc.Repo.FindAccessibleByCollectionID(userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (uc *WebhookCommandUsecase) GetWebhookByID(userID string, webhookID string) (*models.Webhook, error) {
+	webhook, err := uc.Repo.FindAccessible(userID, webhookID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, errors.New("Webhook not found")
+		}
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// CreateWebhook generates the signing secret of a new webhook.
+func (uc *WebhookCommandUsecase) CreateWebhook(ctx context.Context, userID string, webhook *models.Webhook) (*models.Webhook, error) {
+	if _, err := uc.Collections.GetCollectionByIDWithoutPreload(userID, webhook.CollectionID); err != nil {
+		return nil, err
+	}
+
+	secret, err := helpers.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	webhook.Secret = secret
+
+	err = uc.Repo.Create(webhook)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidReference) {
+			return nil, errors.New("Collection Id Not Found")
+		}
+
+		slog.ErrorContext(ctx, "Error creating webhook", "error", err)
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (uc *WebhookCommandUsecase) UpdateWebhook(webhook *models.Webhook) (*models.Webhook, error) {
+	err := uc.Repo.Save(webhook)
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// RotateWebhookSecret replaces the signing secret of a webhook.
+func (uc *WebhookCommandUsecase) RotateWebhookSecret(webhook *models.Webhook) (*models.Webhook, error) {
+	secret, err := helpers.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	webhook.Secret = secret
+
+	return uc.UpdateWebhook(webhook)
+}
+
+func (uc *WebhookCommandUsecase) DeleteWebhook(userID string, webhookID string) error {
+	webhook, err := uc.GetWebhookByID(userID, webhookID)
+	if err != nil {
+		return err
+	}
+
+	// Delete the webhook with its delivery log
+	return uc.Repo.Delete(webhook)
+}
+
+func (uc *WebhookCommandUsecase) GetDeliveriesByWebhookID(userID string, webhookID string, limit int) ([]*models.WebhookDelivery, error) {
+	if _, err := uc.GetWebhookByID(userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := uc.Repo.FindDeliveries(webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
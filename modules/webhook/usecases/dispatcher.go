@@ -0,0 +1,218 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jeksilaen/api-builder/logging"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+	"github.com/jeksilaen/api-builder/modules/webhook/helpers"
+	"github.com/jeksilaen/api-builder/modules/webhook/models"
+	"github.com/jeksilaen/api-builder/modules/webhook/repositories"
+)
+
+// WebhookDispatcher posts signed payloads to webhooks, retrying failed
+// attempts with exponential backoff and logging every attempt.
+type WebhookDispatcher struct {
+	Repo        repositories.WebhookRepository
+	Client      *http.Client
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+
+	// pending tracks the deliveries running in the background, which end
+	// when stopped is cancelled
+	pending sync.WaitGroup
+	stopped context.Context
+	stop    context.CancelFunc
+}
+
+// NewWebhookDispatcher returns a dispatcher posting with client, which
+// should refuse internal addresses, see helpers.NewClient.
+func NewWebhookDispatcher(repo repositories.WebhookRepository, client *http.Client) *WebhookDispatcher {
+	stopped, stop := context.WithCancel(context.Background())
+	return &WebhookDispatcher{
+		Repo:        repo,
+		Client:      client,
+		MaxAttempts: 5,
+		BaseBackoff: 2 * time.Second,
+		MaxBackoff:  time.Minute,
+		stopped:     stopped,
+		stop:        stop,
+	}
+}
+
+// Wait blocks until the background deliveries have finished.
+func (d *WebhookDispatcher) Wait() {
+	d.pending.Wait()
+}
+
+// Stop gives up the background deliveries: their attempt in progress fails
+// and they don't retry.
+func (d *WebhookDispatcher) Stop() {
+	d.stop()
+}
+
+// NotifyRunFailure sends event to every enabled webhook of the collection of
+// a failed run. Deliveries run in the background; monitorID is empty for
+// runs that were not started by a monitor. The deliveries keep the request
+// ID of ctx but outlive its cancellation, until Stop.
+func (d *WebhookDispatcher) NotifyRunFailure(ctx context.Context, event, monitorID string, report *requestModels.RunReport) {
+	webhooks, err := d.Repo.FindEnabledByCollectionID(report.CollectionID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error loading webhooks", "error", err)
+		return
+	}
+
+	failures := requestModels.RunResults{}
+	for _, runResult := range report.Results {
+		if !runResult.Passed {
+			failures = append(failures, runResult)
+		}
+	}
+
+	for _, webhook := range webhooks {
+		payload := models.WebhookPayload{
+			Event:        event,
+			CollectionID: report.CollectionID,
+			MonitorID:    monitorID,
+			Report:       report,
+			Failures:     failures,
+		}
+
+		deliveryCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		stopDelivery := context.AfterFunc(d.stopped, cancel)
+
+		d.pending.Add(1)
+		go func(ctx context.Context, webhook *models.Webhook) {
+			defer d.pending.Done()
+			defer cancel()
+			defer stopDelivery()
+
+			if _, err := d.Deliver(ctx, webhook, payload); err != nil {
+				slog.WarnContext(ctx, "Webhook delivery failed", "webhook_id", webhook.ID, "error", err)
+			}
+		}(deliveryCtx, webhook)
+	}
+}
+
+// SendTestEvent delivers a test event once, without retrying, so the
+// receiver's response is returned right away.
+func (d *WebhookDispatcher) SendTestEvent(ctx context.Context, webhook *models.Webhook) (*models.WebhookDelivery, error) {
+	return d.deliver(ctx, webhook, models.WebhookPayload{
+		Event:        models.EventTest,
+		CollectionID: webhook.CollectionID,
+	}, 1)
+}
+
+// Deliver posts payload to webhook until a 2xx response or MaxAttempts, and
+// stores the delivery with its attempts.
+func (d *WebhookDispatcher) Deliver(ctx context.Context, webhook *models.Webhook, payload models.WebhookPayload) (*models.WebhookDelivery, error) {
+	return d.deliver(ctx, webhook, payload, d.MaxAttempts)
+}
+
+func (d *WebhookDispatcher) deliver(ctx context.Context, webhook *models.Webhook, payload models.WebhookPayload, maxAttempts int) (*models.WebhookDelivery, error) {
+	payload.ID = uuid.New().String()
+	payload.SentAt = time.Now().UTC()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &models.WebhookDelivery{
+		ID:        payload.ID,
+		WebhookID: webhook.ID,
+		Event:     payload.Event,
+		Payload:   string(body),
+		Attempts:  models.WebhookAttempts{},
+	}
+	if err := d.Repo.CreateDelivery(delivery); err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 && !wait(ctx, helpers.Backoff(attempt-1, d.BaseBackoff, d.MaxBackoff)) {
+			break
+		}
+
+		result := d.post(ctx, webhook, delivery, body)
+		delivery.Attempts = append(delivery.Attempts, result)
+		delivery.StatusCode = result.StatusCode
+		delivery.Success = result.Error == ""
+		if delivery.Success {
+			deliveredAt := result.At
+			delivery.DeliveredAt = &deliveredAt
+		}
+
+		if err := d.Repo.SaveDelivery(delivery); err != nil {
+			slog.ErrorContext(ctx, "Error saving webhook delivery", "delivery_id", delivery.ID, "error", err)
+		}
+
+		if delivery.Success {
+			return delivery, nil
+		}
+	}
+
+	return delivery, fmt.Errorf("gave up after %d attempts: %s", len(delivery.Attempts), delivery.Attempts[len(delivery.Attempts)-1].Error)
+}
+
+// wait sleeps for delay, reporting false when ctx ends first.
+func wait(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// post makes a single attempt. Any non-2xx response counts as a failure.
+func (d *WebhookDispatcher) post(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery, body []byte) models.WebhookAttempt {
+	startedAt := time.Now()
+	result := models.WebhookAttempt{At: startedAt}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		result.Error = logging.Error(err).Error()
+		return result
+	}
+
+	timestamp := startedAt.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "api-builder-webhook/1.0")
+	req.Header.Set(helpers.EventHeader, delivery.Event)
+	req.Header.Set(helpers.DeliveryHeader, delivery.ID)
+	req.Header.Set(helpers.TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(helpers.SignatureHeader, helpers.Sign(webhook.Secret, timestamp, body))
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		req.Header.Set(logging.RequestIDHeader, requestID)
+	}
+
+	response, err := d.Client.Do(req)
+	result.ResponseTime = time.Since(startedAt).Milliseconds()
+	if err != nil {
+		result.Error = logging.Error(err).Error()
+		return result
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 1<<20))
+
+	result.StatusCode = response.StatusCode
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		result.Error = "unexpected status " + response.Status
+	}
+	return result
+}
@@ -0,0 +1,154 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jeksilaen/api-builder/db/dbtest"
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	requestModels "github.com/jeksilaen/api-builder/modules/request/models"
+	userModels "github.com/jeksilaen/api-builder/modules/user/models"
+	"github.com/jeksilaen/api-builder/modules/webhook/helpers"
+	"github.com/jeksilaen/api-builder/modules/webhook/models"
+	"github.com/jeksilaen/api-builder/modules/webhook/repositories"
+)
+
+// receiver records the deliveries it gets, answering the first failures of
+// them with a 500.
+type receiver struct {
+	secret   string
+	failures int
+
+	mu         sync.Mutex
+	deliveries []models.WebhookPayload
+	errors     []error
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	body, _ := io.ReadAll(req.Body)
+	if err := helpers.VerifySignature(r.secret, req.Header.Get(helpers.SignatureHeader), req.Header.Get(helpers.TimestampHeader), body, time.Minute, time.Now()); err != nil {
+		r.errors = append(r.errors, err)
+	}
+	var payload models.WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		r.errors = append(r.errors, err)
+	}
+	r.deliveries = append(r.deliveries, payload)
+
+	if r.failures > 0 {
+		r.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// newTestDispatcher returns a dispatcher allowed to post to the local
+// receiver, and an enabled webhook of a new collection pointing at it.
+func newTestDispatcher(t *testing.T, receiverURL string) (*WebhookDispatcher, *models.Webhook) {
+	t.Helper()
+	gormDB := dbtest.Open(t)
+	repo := repositories.NewGormWebhookRepository(gormDB)
+
+	user := &userModels.User{Email: "alice@example.com", Username: "alice", Password: "hash"}
+	if err := gormDB.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	collection := &collectionModels.Collection{UserID: user.ID, Name: "Orders"}
+	if err := gormDB.Create(collection).Error; err != nil {
+		t.Fatal(err)
+	}
+	webhook := &models.Webhook{CollectionID: collection.ID, Name: "Alerts", URL: receiverURL, Secret: "whsec_test", Enabled: true}
+	if err := repo.Create(webhook); err != nil {
+		t.Fatal(err)
+	}
+
+	dispatcher := NewWebhookDispatcher(repo, helpers.NewClient(time.Second, true))
+	dispatcher.BaseBackoff = time.Millisecond
+	dispatcher.MaxBackoff = time.Millisecond
+	return dispatcher, webhook
+}
+
+func TestNotifyRunFailure(t *testing.T) {
+	recv := &receiver{secret: "whsec_test", failures: 2}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+	dispatcher, webhook := newTestDispatcher(t, server.URL)
+
+	report := &requestModels.RunReport{
+		CollectionID: webhook.CollectionID,
+		Total:        2,
+		Passed:       1,
+		Failed:       1,
+		Results: requestModels.RunResults{
+			{Name: "List orders", Status: http.StatusOK, Passed: true},
+			{Name: "Create order", Status: http.StatusBadRequest},
+		},
+	}
+	dispatcher.NotifyRunFailure(context.Background(), models.EventRunFailed, "", report)
+	dispatcher.Wait()
+
+	recv.mu.Lock()
+	defer recv.mu.Unlock()
+	if len(recv.errors) > 0 {
+		t.Errorf("receiver refused deliveries: %v", recv.errors)
+	}
+	// Two failed attempts, then the one that got through
+	if len(recv.deliveries) != 3 {
+		t.Fatalf("received %d attempts, want 3", len(recv.deliveries))
+	}
+	payload := recv.deliveries[2]
+	if payload.Event != models.EventRunFailed || payload.CollectionID != webhook.CollectionID {
+		t.Errorf("payload = %+v", payload)
+	}
+	if len(payload.Failures) != 1 || payload.Failures[0].Name != "Create order" {
+		t.Errorf("failures = %+v, want only Create order", payload.Failures)
+	}
+
+	deliveries, err := dispatcher.Repo.FindDeliveries(webhook.ID, 10)
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("deliveries = %v, %v", deliveries, err)
+	}
+	if delivery := deliveries[0]; !delivery.Success || delivery.StatusCode != http.StatusOK || delivery.DeliveredAt == nil {
+		t.Errorf("delivery = %+v, want it delivered", delivery)
+	}
+}
+
+func TestDeliverGivesUp(t *testing.T) {
+	recv := &receiver{secret: "whsec_test", failures: 10}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+	dispatcher, webhook := newTestDispatcher(t, server.URL)
+	dispatcher.MaxAttempts = 2
+
+	delivery, err := dispatcher.Deliver(context.Background(), webhook, models.WebhookPayload{Event: models.EventTest, CollectionID: webhook.CollectionID})
+	if err == nil {
+		t.Fatal("no error after every attempt failed")
+	}
+	if delivery.Success || len(delivery.Attempts) != 2 || delivery.StatusCode != http.StatusInternalServerError {
+		t.Errorf("delivery = %+v, want 2 failed attempts", delivery)
+	}
+}
+
+func TestDeliverRefusesPrivateTargets(t *testing.T) {
+	recv := &receiver{secret: "whsec_test"}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+	dispatcher, webhook := newTestDispatcher(t, server.URL)
+	dispatcher.Client = helpers.NewClient(time.Second, false)
+
+	delivery, err := dispatcher.SendTestEvent(context.Background(), webhook)
+	if err == nil || delivery.Success {
+		t.Fatalf("delivery = %+v, %v, want it refused", delivery, err)
+	}
+	if len(recv.deliveries) != 0 {
+		t.Errorf("the receiver on localhost got %d deliveries", len(recv.deliveries))
+	}
+}
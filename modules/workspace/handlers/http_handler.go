@@ -0,0 +1,400 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/jeksilaen/api-builder/middlewares"
+	"github.com/jeksilaen/api-builder/modules/workspace/helpers"
+	"github.com/jeksilaen/api-builder/modules/workspace/models"
+	"github.com/jeksilaen/api-builder/modules/workspace/usecases"
+)
+
+// WorkspaceHttpHandler serves the workspace routes with the usecases it is given.
+type WorkspaceHttpHandler struct {
+	Workspaces *usecases.WorkspaceCommandUsecase
+}
+
+func NewWorkspaceHttpHandler(workspaces *usecases.WorkspaceCommandUsecase) *WorkspaceHttpHandler {
+	return &WorkspaceHttpHandler{
+		Workspaces: workspaces,
+	}
+}
+
+func InitWorkspaceHttpHandler(router *gin.Engine, auth *middlewares.Auth, h *WorkspaceHttpHandler) {
+	router.GET("/users/v1/workspace", auth.VerifyToken, middlewares.RequireScope(models.PermRead), h.GetWorkspaces)
+	router.POST("/users/v1/workspace", auth.VerifyToken, middlewares.RequireScope(models.PermManage), h.CreateWorkspace)
+	router.GET("/users/v1/workspace/:id", auth.VerifyToken, auth.RequirePermission(models.PermRead, middlewares.WorkspaceFromParam("id")), h.GetWorkspace)
+	router.PUT("/users/v1/workspace/:id", auth.VerifyToken, auth.RequirePermission(models.PermManage, middlewares.WorkspaceFromParam("id")), h.UpdateWorkspace)
+	router.DELETE("/users/v1/workspace/:id", auth.VerifyToken, auth.RequirePermission(models.PermDeleteWorkspace, middlewares.WorkspaceFromParam("id")), h.DeleteWorkspace)
+	router.GET("/users/v1/workspace/:id/members", auth.VerifyToken, auth.RequirePermission(models.PermRead, middlewares.WorkspaceFromParam("id")), h.GetWorkspaceMembers)
+	router.PUT("/users/v1/workspace/:id/members/:user_id", auth.VerifyToken, auth.RequirePermission(models.PermManage, middlewares.WorkspaceFromParam("id")), h.UpdateWorkspaceMember)
+	router.DELETE("/users/v1/workspace/:id/members/:user_id", auth.VerifyToken, middlewares.RequireSession, auth.RequirePermission(models.PermRead, middlewares.WorkspaceFromParam("id")), h.RemoveWorkspaceMember)
+	router.GET("/users/v1/workspace/:id/invitations", auth.VerifyToken, auth.RequirePermission(models.PermRead, middlewares.WorkspaceFromParam("id")), h.GetWorkspaceInvitations)
+	router.POST("/users/v1/workspace/:id/invitations", auth.VerifyToken, auth.RequirePermission(models.PermManage, middlewares.WorkspaceFromParam("id")), h.InviteWorkspaceMember)
+	router.DELETE("/users/v1/workspace/:id/invitations/:invitation_id", auth.VerifyToken, auth.RequirePermission(models.PermManage, middlewares.WorkspaceFromParam("id")), h.RevokeWorkspaceInvitation)
+
+	router.GET("/users/v1/invitation", auth.VerifyToken, middlewares.RequireScope(models.PermRead), h.GetInvitations)
+	router.POST("/users/v1/invitation/:id/accept", auth.VerifyToken, middlewares.RequireSession, h.AcceptInvitation)
+	router.POST("/users/v1/invitation/:id/decline", auth.VerifyToken, middlewares.RequireSession, h.DeclineInvitation)
+}
+
+func (h *WorkspaceHttpHandler) GetWorkspaces(ctx *gin.Context) {
+
+	// Make sure the user has a personal workspace to start with
+	if _, err := h.Workspaces.EnsurePersonalWorkspace(ctx.Request.Context(), middlewares.GetUserID(ctx)); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get the workspaces of the user from usecase
+	members, err := h.Workspaces.GetWorkspacesByUserID(middlewares.GetUserID(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Workspaces not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessGetResponse(members))
+}
+
+func (h *WorkspaceHttpHandler) CreateWorkspace(ctx *gin.Context) {
+	validate := validator.New()
+
+	// Decode the request JSON data into WorkspaceRequest object
+	var req models.WorkspaceRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Create the workspace with the user as its owner
+	member, err := h.Workspaces.CreateWorkspace(ctx.Request.Context(), middlewares.GetUserID(ctx), &models.Workspace{Name: req.Name})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, helpers.ReturnSucessCreateResponse(member))
+}
+
+func (h *WorkspaceHttpHandler) GetWorkspace(ctx *gin.Context) {
+
+	workspaceID := ctx.Param("id")
+
+	if workspaceID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Workspace ID is required"})
+		return
+	}
+
+	// Get the workspace through the membership of the user
+	member, err := h.Workspaces.GetMember(middlewares.GetUserID(ctx), workspaceID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessCreateResponse(member))
+}
+
+func (h *WorkspaceHttpHandler) UpdateWorkspace(ctx *gin.Context) {
+	validate := validator.New()
+
+	workspaceID := ctx.Param("id")
+
+	if workspaceID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Workspace ID is required"})
+		return
+	}
+
+	// Decode the request JSON data into WorkspaceRequest object
+	var req models.WorkspaceRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	member, err := h.Workspaces.GetMember(middlewares.GetUserID(ctx), workspaceID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		return
+	}
+
+	member.Workspace.Name = req.Name
+
+	// Save the renamed workspace
+	if _, err := h.Workspaces.UpdateWorkspace(&member.Workspace); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessCreateResponse(member))
+}
+
+func (h *WorkspaceHttpHandler) DeleteWorkspace(ctx *gin.Context) {
+
+	workspaceID := ctx.Param("id")
+
+	if workspaceID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Workspace ID is required"})
+		return
+	}
+
+	// Delete the workspace with its members and invitations
+	err := h.Workspaces.DeleteWorkspace(middlewares.GetUserID(ctx), workspaceID)
+	if err != nil {
+		if errors.Is(err, usecases.ErrWorkspaceNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, usecases.ErrPermissionDenied) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessDeleteResponse("Deleted Workspace Successfully"))
+}
+
+func (h *WorkspaceHttpHandler) GetWorkspaceMembers(ctx *gin.Context) {
+
+	workspaceID := ctx.Param("id")
+
+	if workspaceID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Workspace ID is required"})
+		return
+	}
+
+	// Get the members from usecase
+	members, err := h.Workspaces.GetMembers(middlewares.GetUserID(ctx), workspaceID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessGetMembersResponse(members))
+}
+
+func (h *WorkspaceHttpHandler) RemoveWorkspaceMember(ctx *gin.Context) {
+
+	workspaceID := ctx.Param("id")
+	memberID := ctx.Param("user_id")
+
+	if workspaceID == "" || memberID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Workspace ID and User ID are required"})
+		return
+	}
+
+	// Remove the member, or leave the workspace when it is the user
+	err := h.Workspaces.RemoveMember(middlewares.GetUserID(ctx), workspaceID, memberID)
+	if err != nil {
+		if errors.Is(err, usecases.ErrWorkspaceNotFound) || errors.Is(err, usecases.ErrMemberNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, usecases.ErrPermissionDenied) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessDeleteResponse("Removed Member Successfully"))
+}
+
+func (h *WorkspaceHttpHandler) UpdateWorkspaceMember(ctx *gin.Context) {
+	validate := validator.New()
+
+	workspaceID := ctx.Param("id")
+	memberID := ctx.Param("user_id")
+
+	if workspaceID == "" || memberID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Workspace ID and User ID are required"})
+		return
+	}
+
+	// Decode the request JSON data into MemberRoleRequest object
+	var req models.MemberRoleRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	// Change the role of the member
+	member, err := h.Workspaces.UpdateMemberRole(middlewares.GetUserID(ctx), workspaceID, memberID, req.Role)
+	if err != nil {
+		if errors.Is(err, usecases.ErrWorkspaceNotFound) || errors.Is(err, usecases.ErrMemberNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, usecases.ErrPermissionDenied) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedCreateResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessMemberResponse(member))
+}
+
+func (h *WorkspaceHttpHandler) GetWorkspaceInvitations(ctx *gin.Context) {
+
+	workspaceID := ctx.Param("id")
+
+	if workspaceID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Workspace ID is required"})
+		return
+	}
+
+	// Get the pending invitations from usecase
+	invitations, err := h.Workspaces.GetWorkspaceInvitations(middlewares.GetUserID(ctx), workspaceID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Workspace not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessGetInvitationsResponse(invitations))
+}
+
+func (h *WorkspaceHttpHandler) InviteWorkspaceMember(ctx *gin.Context) {
+	validate := validator.New()
+
+	workspaceID := ctx.Param("id")
+
+	if workspaceID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Workspace ID is required"})
+		return
+	}
+
+	// Decode the request JSON data into InvitationRequest object
+	var req models.InvitationRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedInvitationResponse(err.Error()))
+		return
+	}
+
+	// Validate the request JSON data
+	err := validate.Struct(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedInvitationResponse(err.Error()))
+		return
+	}
+
+	// Invite the email address to the workspace
+	invitation, err := h.Workspaces.InviteMember(ctx.Request.Context(), middlewares.GetUserID(ctx), workspaceID, req.Email, req.Role)
+	if err != nil {
+		if errors.Is(err, usecases.ErrWorkspaceNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, usecases.ErrPermissionDenied) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedInvitationResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, helpers.ReturnSucessInvitationResponse(invitation))
+}
+
+func (h *WorkspaceHttpHandler) RevokeWorkspaceInvitation(ctx *gin.Context) {
+
+	workspaceID := ctx.Param("id")
+	invitationID := ctx.Param("invitation_id")
+
+	if workspaceID == "" || invitationID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Workspace ID and Invitation ID are required"})
+		return
+	}
+
+	// Delete the pending invitation
+	err := h.Workspaces.RevokeInvitation(middlewares.GetUserID(ctx), workspaceID, invitationID)
+	if err != nil {
+		if errors.Is(err, usecases.ErrWorkspaceNotFound) || errors.Is(err, usecases.ErrInvitationNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, usecases.ErrPermissionDenied) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessDeleteResponse("Revoked Invitation Successfully"))
+}
+
+func (h *WorkspaceHttpHandler) GetInvitations(ctx *gin.Context) {
+
+	// Get the invitations sent to the email of the user
+	invitations, err := h.Workspaces.GetPendingInvitations(middlewares.GetUserID(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Invitations not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessGetInvitationsResponse(invitations))
+}
+
+func (h *WorkspaceHttpHandler) AcceptInvitation(ctx *gin.Context) {
+	h.respondInvitation(ctx, true)
+}
+
+func (h *WorkspaceHttpHandler) DeclineInvitation(ctx *gin.Context) {
+	h.respondInvitation(ctx, false)
+}
+
+func (h *WorkspaceHttpHandler) respondInvitation(ctx *gin.Context, accept bool) {
+
+	invitationID := ctx.Param("id")
+
+	if invitationID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invitation ID is required"})
+		return
+	}
+
+	// Answer the invitation, joining the workspace when it is accepted
+	invitation, err := h.Workspaces.RespondInvitation(middlewares.GetUserID(ctx), invitationID, accept)
+	if err != nil {
+		if errors.Is(err, usecases.ErrInvitationNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, usecases.ErrEmailNotVerified) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, helpers.ReturnFailedInvitationResponse(err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helpers.ReturnSucessInvitationResponse(invitation))
+}
@@ -0,0 +1,19 @@
+package helpers
+
+import (
+	"github.com/jeksilaen/api-builder/mailer"
+)
+
+// InvitationEmail tells someone they were invited to join a workspace, to
+// answer in the web app at appURL.
+func InvitationEmail(appURL string, inviter string, workspace string, email string) mailer.Message {
+	return mailer.Message{
+		To:      email,
+		Subject: inviter + " invited you to " + workspace,
+		Body: "Hi,\n\n" +
+			inviter + " invited you to join the workspace " + workspace + " on api-builder.\n\n" +
+			"Sign in or create an account with this email address to answer the invitation:\n\n" +
+			appURL + "/invitations\n\n" +
+			"The invitation expires in 7 days.\n",
+	}
+}
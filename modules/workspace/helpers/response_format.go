@@ -0,0 +1,178 @@
+package helpers
+
+import (
+	"github.com/jeksilaen/api-builder/modules/workspace/models"
+)
+
+func ReturnFailedCreateResponse(message string) *models.FailedResponse {
+	return &models.FailedResponse{
+		Error:   "Create failed",
+		Message: message,
+		Links: []models.Link{
+			{
+				Rel:  "create workspace",
+				Href: "/users/v1/workspace",
+			},
+		},
+	}
+}
+
+func ReturnFailedInvitationResponse(message string) *models.FailedResponse {
+	return &models.FailedResponse{
+		Error:   "Invitation failed",
+		Message: message,
+		Links: []models.Link{
+			{
+				Rel:  "get invitations",
+				Href: "/users/v1/invitation",
+			},
+		},
+	}
+}
+
+func toWorkspaceResponse(member *models.WorkspaceMember) models.WorkspaceResponse {
+	return models.WorkspaceResponse{
+		ID:          member.Workspace.ID,
+		Name:        member.Workspace.Name,
+		OwnerID:     member.Workspace.OwnerID,
+		Personal:    member.Workspace.Personal,
+		Role:        member.Role,
+		Permissions: models.RolePermissions[member.Role],
+	}
+}
+
+func toMemberResponse(member *models.WorkspaceMember) models.MemberResponse {
+	return models.MemberResponse{
+		UserID:      member.UserID,
+		Email:       member.User.Email,
+		Username:    member.User.Username,
+		Role:        member.Role,
+		Permissions: models.RolePermissions[member.Role],
+		JoinedAt:    member.CreatedAt,
+	}
+}
+
+func toInvitationResponse(invitation *models.WorkspaceInvitation) models.InvitationResponse {
+	return models.InvitationResponse{
+		ID:            invitation.ID,
+		WorkspaceID:   invitation.WorkspaceID,
+		WorkspaceName: invitation.Workspace.Name,
+		Email:         invitation.Email,
+		Role:          invitation.Role,
+		Status:        invitation.Status,
+		ExpiresAt:     invitation.ExpiresAt,
+	}
+}
+
+func ReturnSucessCreateResponse(member *models.WorkspaceMember) *models.SucessCreateResponse {
+	return &models.SucessCreateResponse{
+		Message: "Save Workspace sucessfully",
+		Data:    toWorkspaceResponse(member),
+		Links: []models.Link{
+			{
+				Rel:  "get workspace members",
+				Href: "/users/v1/workspace/" + member.WorkspaceID + "/members",
+			},
+			{
+				Rel:  "get workspace collections",
+				Href: "/users/v1/collection_by_workspace/" + member.WorkspaceID,
+			},
+		},
+	}
+}
+
+func ReturnSucessGetResponse(members []*models.WorkspaceMember) *models.SucessGetResponse {
+	workspaceResponses := []models.WorkspaceResponse{}
+	for _, member := range members {
+		workspaceResponses = append(workspaceResponses, toWorkspaceResponse(member))
+	}
+
+	return &models.SucessGetResponse{
+		Message: "Get Workspaces sucessfully",
+		Data:    workspaceResponses,
+		Links: []models.Link{
+			{
+				Rel:  "create workspace",
+				Href: "/users/v1/workspace",
+			},
+		},
+	}
+}
+
+func ReturnSucessGetMembersResponse(members []*models.WorkspaceMember) *models.SucessGetMembersResponse {
+	memberResponses := []models.MemberResponse{}
+	for _, member := range members {
+		memberResponses = append(memberResponses, toMemberResponse(member))
+	}
+
+	return &models.SucessGetMembersResponse{
+		Message: "Get Workspace Members sucessfully",
+		Data:    memberResponses,
+		Links: []models.Link{
+			{
+				Rel:  "invite member",
+				Href: "/users/v1/workspace/:id/invitations",
+			},
+		},
+	}
+}
+
+func ReturnSucessMemberResponse(member *models.WorkspaceMember) *models.SucessMemberResponse {
+	return &models.SucessMemberResponse{
+		Message: "Save Workspace Member sucessfully",
+		Data:    toMemberResponse(member),
+		Links: []models.Link{
+			{
+				Rel:  "get workspace members",
+				Href: "/users/v1/workspace/" + member.WorkspaceID + "/members",
+			},
+		},
+	}
+}
+
+func ReturnSucessInvitationResponse(invitation *models.WorkspaceInvitation) *models.SucessInvitationResponse {
+	return &models.SucessInvitationResponse{
+		Message: "Save Invitation sucessfully",
+		Data:    toInvitationResponse(invitation),
+		Links: []models.Link{
+			{
+				Rel:  "get workspace members",
+				Href: "/users/v1/workspace/" + invitation.WorkspaceID + "/members",
+			},
+		},
+	}
+}
+
+func ReturnSucessGetInvitationsResponse(invitations []*models.WorkspaceInvitation) *models.SucessGetInvitationsResponse {
+	invitationResponses := []models.InvitationResponse{}
+	for _, invitation := range invitations {
+		invitationResponses = append(invitationResponses, toInvitationResponse(invitation))
+	}
+
+	return &models.SucessGetInvitationsResponse{
+		Message: "Get Invitations sucessfully",
+		Data:    invitationResponses,
+		Links: []models.Link{
+			{
+				Rel:  "accept invitation",
+				Href: "/users/v1/invitation/:id/accept",
+			},
+			{
+				Rel:  "decline invitation",
+				Href: "/users/v1/invitation/:id/decline",
+			},
+		},
+	}
+}
+
+func ReturnSucessDeleteResponse(message string) *models.SucessDeleteResponse {
+	return &models.SucessDeleteResponse{
+		Message: message,
+		Links: []models.Link{
+			{
+				Rel:  "get workspaces",
+				Href: "/users/v1/workspace",
+			},
+		},
+	}
+}
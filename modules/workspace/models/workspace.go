@@ -0,0 +1,207 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	userModels "github.com/jeksilaen/api-builder/modules/user/models"
+	"gorm.io/gorm"
+)
+
+// Member roles, from the most to the least privileged.
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleRunner = "runner"
+	RoleViewer = "viewer"
+)
+
+// Permissions granted by the roles.
+const (
+	// PermRead allows reading collections, requests and their resources
+	PermRead = "read"
+	// PermRun allows executing requests, collections and monitors
+	PermRun = "run"
+	// PermEdit allows creating, changing and deleting requests and the
+	// resources of collections
+	PermEdit = "edit"
+	// PermSecrets allows seeing tokens, credential headers and secret
+	// environment values
+	PermSecrets = "secrets"
+	// PermManage allows managing members, invitations and the collections
+	// of the workspace as a whole
+	PermManage = "manage"
+	// PermDeleteWorkspace allows deleting the workspace
+	PermDeleteWorkspace = "delete_workspace"
+)
+
+// RolePermissions is the permission matrix of the roles.
+var RolePermissions = map[string][]string{
+	RoleOwner:  {PermRead, PermRun, PermEdit, PermSecrets, PermManage, PermDeleteWorkspace},
+	RoleAdmin:  {PermRead, PermRun, PermEdit, PermSecrets, PermManage},
+	RoleEditor: {PermRead, PermRun, PermEdit, PermSecrets},
+	RoleRunner: {PermRead, PermRun},
+	RoleViewer: {PermRead, PermRun},
+}
+
+// Can reports whether role grants permission.
+func Can(role string, permission string) bool {
+	for _, granted := range RolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Invitation statuses.
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+)
+
+// Workspace groups the collections shared by its members. Every user gets a
+// personal workspace for the collections they don't share.
+type Workspace struct {
+	gorm.Model
+	ID       string          `gorm:"type:uuid;primaryKey"`
+	Name     string          `json:"name" validate:"required"`
+	OwnerID  string          `json:"owner_id" gorm:"type:uuid;not null;index"`
+	Personal bool            `json:"personal"`
+	Owner    userModels.User `gorm:"foreignKey:OwnerID" json:"-" validate:"-"`
+}
+
+// WorkspaceMember gives a user access to every collection of a workspace.
+type WorkspaceMember struct {
+	ID          string          `gorm:"type:uuid;primaryKey"`
+	WorkspaceID string          `json:"workspace_id" gorm:"type:uuid;not null;uniqueIndex:idx_workspace_member"`
+	UserID      string          `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_workspace_member;index"`
+	Role        string          `json:"role"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Workspace   Workspace       `gorm:"foreignKey:WorkspaceID" json:"-"`
+	User        userModels.User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// WorkspaceInvitation invites an email address to join a workspace. It is
+// answered by the user registered with that email.
+type WorkspaceInvitation struct {
+	gorm.Model
+	ID          string    `gorm:"type:uuid;primaryKey"`
+	WorkspaceID string    `json:"workspace_id" gorm:"type:uuid;not null;index"`
+	Email       string    `json:"email" gorm:"not null;index"`
+	Role        string    `json:"role"`
+	InvitedBy   string    `json:"invited_by" gorm:"type:uuid;not null"`
+	Status      string    `json:"status"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Workspace   Workspace `gorm:"foreignKey:WorkspaceID" json:"-"`
+}
+
+type WorkspaceRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type InvitationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"omitempty,oneof=admin editor runner viewer"`
+}
+
+type MemberRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin editor runner viewer"`
+}
+
+type WorkspaceResponse struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	OwnerID     string   `json:"owner_id"`
+	Personal    bool     `json:"personal"`
+	Role        string   `json:"role,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+type MemberResponse struct {
+	UserID      string    `json:"user_id"`
+	Email       string    `json:"email"`
+	Username    string    `json:"username"`
+	Role        string    `json:"role"`
+	Permissions []string  `json:"permissions"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+type InvitationResponse struct {
+	ID            string    `json:"id"`
+	WorkspaceID   string    `json:"workspace_id"`
+	WorkspaceName string    `json:"workspace_name,omitempty"`
+	Email         string    `json:"email"`
+	Role          string    `json:"role"`
+	Status        string    `json:"status"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+type SucessCreateResponse struct {
+	Message string            `json:"message"`
+	Data    WorkspaceResponse `json:"data"`
+	Links   []Link            `json:"links"`
+}
+
+type SucessGetResponse struct {
+	Message string              `json:"message"`
+	Data    []WorkspaceResponse `json:"data"`
+	Links   []Link              `json:"links"`
+}
+
+type SucessMemberResponse struct {
+	Message string         `json:"message"`
+	Data    MemberResponse `json:"data"`
+	Links   []Link         `json:"links"`
+}
+
+type SucessGetMembersResponse struct {
+	Message string           `json:"message"`
+	Data    []MemberResponse `json:"data"`
+	Links   []Link           `json:"links"`
+}
+
+type SucessInvitationResponse struct {
+	Message string             `json:"message"`
+	Data    InvitationResponse `json:"data"`
+	Links   []Link             `json:"links"`
+}
+
+type SucessGetInvitationsResponse struct {
+	Message string               `json:"message"`
+	Data    []InvitationResponse `json:"data"`
+	Links   []Link               `json:"links"`
+}
+
+type SucessDeleteResponse struct {
+	Message string `json:"message"`
+	Links   []Link `json:"links"`
+}
+
+type FailedResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+	Links   []Link `json:"links"`
+}
+
+type Link struct {
+	Rel  string `json:"rel"`
+	Href string `json:"href"`
+}
+
+func (workspace *Workspace) BeforeCreate(tx *gorm.DB) error {
+	workspace.ID = uuid.New().String()
+	return nil
+}
+
+func (member *WorkspaceMember) BeforeCreate(tx *gorm.DB) error {
+	member.ID = uuid.New().String()
+	return nil
+}
+
+func (invitation *WorkspaceInvitation) BeforeCreate(tx *gorm.DB) error {
+	invitation.ID = uuid.New().String()
+	return nil
+}
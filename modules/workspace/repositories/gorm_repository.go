@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/jeksilaen/api-builder/db"
+	collectionModels "github.com/jeksilaen/api-builder/modules/collection/models"
+	userModels "github.com/jeksilaen/api-builder/modules/user/models"
+	"github.com/jeksilaen/api-builder/modules/workspace/models"
+	"gorm.io/gorm"
+)
+
+// GormWorkspaceRepository stores workspaces with gorm, on any of the
+// databases of db.Open.
+type GormWorkspaceRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormWorkspaceRepository(db *gorm.DB) *GormWorkspaceRepository {
+	return &GormWorkspaceRepository{DB: db}
+}
+
+func (r *GormWorkspaceRepository) FindMemberships(userID string) ([]*models.WorkspaceMember, error) {
+	var members []*models.WorkspaceMember
+	err := r.DB.Where("user_id = ?", userID).Preload("Workspace").Order("created_at").Find(&members).Error
+	return members, err
+}
+
+func (r *GormWorkspaceRepository) FindMember(workspaceID string, userID string) (*models.WorkspaceMember, error) {
+	var member models.WorkspaceMember
+	err := r.DB.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Preload("Workspace").First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *GormWorkspaceRepository) FindMembers(workspaceID string) ([]*models.WorkspaceMember, error) {
+	var members []*models.WorkspaceMember
+	err := r.DB.Where("workspace_id = ?", workspaceID).Preload("User").Order("created_at").Find(&members).Error
+	return members, err
+}
+
+func (r *GormWorkspaceRepository) FindPersonal(userID string) (*models.Workspace, error) {
+	var workspace models.Workspace
+	if err := r.DB.Where("owner_id = ? AND personal = ?", userID, true).First(&workspace).Error; err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+func (r *GormWorkspaceRepository) CreateWithOwner(workspace *models.Workspace, owner *models.WorkspaceMember) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Owner").Create(workspace).Error; err != nil {
+			return err
+		}
+
+		owner.WorkspaceID = workspace.ID
+		return tx.Omit("Workspace", "User").Create(owner).Error
+	})
+}
+
+func (r *GormWorkspaceRepository) Save(workspace *models.Workspace) error {
+	return r.DB.Omit("Owner").Save(workspace).Error
+}
+
+func (r *GormWorkspaceRepository) Delete(workspace *models.Workspace) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("workspace_id = ?", workspace.ID).Delete(&models.WorkspaceInvitation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("workspace_id = ?", workspace.ID).Delete(&models.WorkspaceMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(workspace).Error
+	})
+}
+
+func (r *GormWorkspaceRepository) CountCollections(workspaceID string) (int64, error) {
+	var count int64
+	err := r.DB.Model(&collectionModels.Collection{}).Where("workspace_id = ?", workspaceID).Count(&count).Error
+	return count, err
+}
+
+func (r *GormWorkspaceRepository) UpdateRole(member *models.WorkspaceMember, role string) error {
+	if err := r.DB.Model(member).Update("role", role).Error; err != nil {
+		return err
+	}
+	return r.DB.Preload("User").First(member, "id = ?", member.ID).Error
+}
+
+func (r *GormWorkspaceRepository) DeleteMember(member *models.WorkspaceMember) error {
+	return r.DB.Delete(member).Error
+}
+
+func (r *GormWorkspaceRepository) HasMemberWithEmail(workspaceID string, email string) (bool, error) {
+	var count int64
+	err := r.DB.Model(&models.WorkspaceMember{}).
+		Joins("JOIN users ON users.id = workspace_members.user_id").
+		Where("workspace_members.workspace_id = ? AND LOWER(users.email) = ?", workspaceID, email).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *GormWorkspaceRepository) FindPendingInvitation(workspaceID string, email string) (*models.WorkspaceInvitation, error) {
+	var invitation models.WorkspaceInvitation
+	err := r.DB.Where("workspace_id = ? AND email = ? AND status = ?", workspaceID, email, models.InvitationPending).First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *GormWorkspaceRepository) FindPendingInvitations(workspaceID string) ([]*models.WorkspaceInvitation, error) {
+	var invitations []*models.WorkspaceInvitation
+	err := r.DB.Where("workspace_id = ? AND status = ?", workspaceID, models.InvitationPending).Preload("Workspace").Order("created_at").Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *GormWorkspaceRepository) FindInvitationsTo(email string) ([]*models.WorkspaceInvitation, error) {
+	var invitations []*models.WorkspaceInvitation
+	err := r.DB.Where("email = ? AND status = ? AND expires_at > ?", email, models.InvitationPending, time.Now()).
+		Preload("Workspace").Order("created_at").Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *GormWorkspaceRepository) FindInvitationTo(invitationID string, email string) (*models.WorkspaceInvitation, error) {
+	var invitation models.WorkspaceInvitation
+	err := r.DB.Where("id = ? AND email = ? AND status = ?", invitationID, email, models.InvitationPending).Preload("Workspace").First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *GormWorkspaceRepository) SaveInvitation(invitation *models.WorkspaceInvitation) error {
+	return r.DB.Omit("Workspace").Save(invitation).Error
+}
+
+func (r *GormWorkspaceRepository) DeletePendingInvitation(workspaceID string, invitationID string) error {
+	result := r.DB.Where("id = ? AND workspace_id = ? AND status = ?", invitationID, workspaceID, models.InvitationPending).Delete(&models.WorkspaceInvitation{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return db.ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormWorkspaceRepository) AnswerInvitation(invitation *models.WorkspaceInvitation, member *models.WorkspaceMember) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Workspace").Save(invitation).Error; err != nil {
+			return err
+		}
+		if member == nil {
+			return nil
+		}
+
+		return tx.Omit("Workspace", "User").Create(member).Error
+	})
+}
+
+func (r *GormWorkspaceRepository) FindUser(userID string) (*userModels.User, error) {
+	var user userModels.User
+	if err := r.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *GormWorkspaceRepository) FindUsersWithoutWorkspaceCollections() ([]string, error) {
+	var userIDs []string
+	err := r.DB.Model(&collectionModels.Collection{}).
+		Where("workspace_id IS NULL").
+		Distinct("user_id").
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+func (r *GormWorkspaceRepository) MoveCollectionsWithoutWorkspace(userID string, workspaceID string) error {
+	return r.DB.Model(&collectionModels.Collection{}).
+		Where("user_id = ? AND workspace_id IS NULL", userID).
+		Update("workspace_id", workspaceID).Error
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	userModels "github.com/jeksilaen/api-builder/modules/user/models"
+	"github.com/jeksilaen/api-builder/modules/workspace/models"
+)
+
+// WorkspaceRepository stores workspaces with their members and invitations.
+// Lookups of a missing record fail with db.ErrNotFound.
+type WorkspaceRepository interface {
+	// FindMemberships returns the memberships of userID with their
+	// workspace, oldest first
+	FindMemberships(userID string) ([]*models.WorkspaceMember, error)
+	// FindMember returns the membership of userID in a workspace with the
+	// workspace
+	FindMember(workspaceID string, userID string) (*models.WorkspaceMember, error)
+	// FindMembers returns the members of a workspace with their user,
+	// oldest first
+	FindMembers(workspaceID string) ([]*models.WorkspaceMember, error)
+	FindPersonal(userID string) (*models.Workspace, error)
+	// CreateWithOwner creates a workspace and the membership of its owner
+	CreateWithOwner(workspace *models.Workspace, owner *models.WorkspaceMember) error
+	Save(workspace *models.Workspace) error
+	// Delete deletes a workspace with its members and invitations
+	Delete(workspace *models.Workspace) error
+	CountCollections(workspaceID string) (int64, error)
+	// UpdateRole changes the role of a member and loads their user
+	UpdateRole(member *models.WorkspaceMember, role string) error
+	DeleteMember(member *models.WorkspaceMember) error
+	HasMemberWithEmail(workspaceID string, email string) (bool, error)
+
+	FindPendingInvitation(workspaceID string, email string) (*models.WorkspaceInvitation, error)
+	// FindPendingInvitations returns the pending invitations of a workspace
+	// with the workspace, oldest first
+	FindPendingInvitations(workspaceID string) ([]*models.WorkspaceInvitation, error)
+	// FindInvitationsTo returns the pending invitations sent to email that
+	// haven't expired, with their workspace, oldest first
+	FindInvitationsTo(email string) ([]*models.WorkspaceInvitation, error)
+	// FindInvitationTo returns a pending invitation sent to email with its
+	// workspace
+	FindInvitationTo(invitationID string, email string) (*models.WorkspaceInvitation, error)
+	SaveInvitation(invitation *models.WorkspaceInvitation) error
+	// DeletePendingInvitation fails with db.ErrNotFound when the workspace
+	// has no such pending invitation
+	DeletePendingInvitation(workspaceID string, invitationID string) error
+	// AnswerInvitation saves the invitation and creates member, if any, at
+	// once
+	AnswerInvitation(invitation *models.WorkspaceInvitation, member *models.WorkspaceMember) error
+
+	FindUser(userID string) (*userModels.User, error)
+
+	// FindUsersWithoutWorkspaceCollections returns the users who created
+	// collections outside of any workspace
+	FindUsersWithoutWorkspaceCollections() ([]string, error)
+	// MoveCollectionsWithoutWorkspace moves the collections of userID that
+	// are outside of any workspace into a workspace
+	MoveCollectionsWithoutWorkspace(userID string, workspaceID string) error
+}
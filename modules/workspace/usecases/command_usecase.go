@@ -0,0 +1,408 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jeksilaen/api-builder/db"
+	"github.com/jeksilaen/api-builder/mailer"
+	userModels "github.com/jeksilaen/api-builder/modules/user/models"
+	"github.com/jeksilaen/api-builder/modules/workspace/helpers"
+	"github.com/jeksilaen/api-builder/modules/workspace/models"
+	"github.com/jeksilaen/api-builder/modules/workspace/repositories"
+)
+
+// Errors returned for records that don't exist or that the user can't see.
+var (
+	ErrWorkspaceNotFound  = errors.New("Workspace not found")
+	ErrMemberNotFound     = errors.New("Member not found")
+	ErrInvitationNotFound = errors.New("Invitation not found")
+	ErrPermissionDenied   = errors.New("Your role in the workspace doesn't allow this")
+	ErrEmailNotVerified   = errors.New("Verify your email address to accept invitations")
+)
+
+// invitationTTL is how long an invitation can be answered.
+const invitationTTL = 7 * 24 * time.Hour
+
+// WorkspaceCommandUsecase manages the workspaces. Mailer sends the
+// invitations linking to the web app at AppURL.
+type WorkspaceCommandUsecase struct {
+	Repo   repositories.WorkspaceRepository
+	Mailer mailer.Sender
+	AppURL string
+}
+
+func NewWorkspaceCommandUsecase(repo repositories.WorkspaceRepository, sender mailer.Sender, appURL string) *WorkspaceCommandUsecase {
+	return &WorkspaceCommandUsecase{
+		Repo:   repo,
+		Mailer: sender,
+		AppURL: appURL,
+	}
+}
+
+// GetWorkspacesByUserID returns the workspaces the user is a member of with
+// the membership of the user.
+func (uc *WorkspaceCommandUsecase) GetWorkspacesByUserID(userID string) ([]*models.WorkspaceMember, error) {
+	members, err := uc.Repo.FindMemberships(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// GetMember returns the membership of userID in a workspace. It fails the
+// same way for missing workspaces and ones the user is not a member of.
+func (uc *WorkspaceCommandUsecase) GetMember(userID string, workspaceID string) (*models.WorkspaceMember, error) {
+	member, err := uc.Repo.FindMember(workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// CreateWorkspace creates a workspace with userID as its owner.
+func (uc *WorkspaceCommandUsecase) CreateWorkspace(ctx context.Context, userID string, workspace *models.Workspace) (*models.WorkspaceMember, error) {
+	workspace.OwnerID = userID
+	member := &models.WorkspaceMember{
+		UserID: userID,
+		Role:   models.RoleOwner,
+	}
+
+	err := uc.Repo.CreateWithOwner(workspace, member)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error creating workspace", "error", err)
+		return nil, err
+	}
+
+	member.Workspace = *workspace
+	return member, nil
+}
+
+// EnsurePersonalWorkspace returns the personal workspace of the user,
+// creating it the first time.
+func (uc *WorkspaceCommandUsecase) EnsurePersonalWorkspace(ctx context.Context, userID string) (*models.Workspace, error) {
+	workspace, err := uc.Repo.FindPersonal(userID)
+	if err == nil {
+		return workspace, nil
+	}
+	if !errors.Is(err, db.ErrNotFound) {
+		return nil, err
+	}
+
+	member, err := uc.CreateWorkspace(ctx, userID, &models.Workspace{
+		Name:     "Personal",
+		Personal: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &member.Workspace, nil
+}
+
+func (uc *WorkspaceCommandUsecase) UpdateWorkspace(workspace *models.Workspace) (*models.Workspace, error) {
+	err := uc.Repo.Save(workspace)
+	if err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// DeleteWorkspace deletes an empty shared workspace of which userID is the
+// owner, with its members and invitations.
+func (uc *WorkspaceCommandUsecase) DeleteWorkspace(userID string, workspaceID string) error {
+	member, err := uc.GetMember(userID, workspaceID)
+	if err != nil {
+		return err
+	}
+	if !models.Can(member.Role, models.PermDeleteWorkspace) {
+		return ErrPermissionDenied
+	}
+	if member.Workspace.Personal {
+		return errors.New("A personal workspace can't be deleted")
+	}
+
+	collections, err := uc.Repo.CountCollections(workspaceID)
+	if err != nil {
+		return err
+	}
+	if collections > 0 {
+		return errors.New("Move or delete the collections of the workspace first")
+	}
+
+	return uc.Repo.Delete(&member.Workspace)
+}
+
+// GetMembers lists the members of a workspace the user is a member of.
+func (uc *WorkspaceCommandUsecase) GetMembers(userID string, workspaceID string) ([]*models.WorkspaceMember, error) {
+	if _, err := uc.GetMember(userID, workspaceID); err != nil {
+		return nil, err
+	}
+
+	members, err := uc.Repo.FindMembers(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// RemoveMember removes memberID from a workspace. Owners and admins can
+// remove members below them; anyone but the owner can leave.
+func (uc *WorkspaceCommandUsecase) RemoveMember(userID string, workspaceID string, memberID string) error {
+	member, err := uc.GetMember(userID, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	if memberID == userID {
+		if member.Role == models.RoleOwner {
+			return errors.New("The owner can't leave the workspace")
+		}
+		return uc.Repo.DeleteMember(member)
+	}
+
+	removed, err := uc.getManagedMember(member, memberID)
+	if err != nil {
+		return err
+	}
+	return uc.Repo.DeleteMember(removed)
+}
+
+// UpdateMemberRole changes the role of memberID in a workspace. Only the
+// owner can grant the admin role or change the role of an admin.
+func (uc *WorkspaceCommandUsecase) UpdateMemberRole(userID string, workspaceID string, memberID string, role string) (*models.WorkspaceMember, error) {
+	member, err := uc.GetMember(userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if !canAssign(member.Role, role) {
+		return nil, ErrPermissionDenied
+	}
+
+	updated, err := uc.getManagedMember(member, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.Repo.UpdateRole(updated, role); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// getManagedMember returns the member memberID of the workspace of manager if
+// manager may manage them.
+func (uc *WorkspaceCommandUsecase) getManagedMember(manager *models.WorkspaceMember, memberID string) (*models.WorkspaceMember, error) {
+	if !models.Can(manager.Role, models.PermManage) {
+		return nil, ErrPermissionDenied
+	}
+
+	member, err := uc.GetMember(memberID, manager.WorkspaceID)
+	if err != nil {
+		return nil, ErrMemberNotFound
+	}
+	if member.Role == models.RoleOwner || (member.Role == models.RoleAdmin && manager.Role != models.RoleOwner) {
+		return nil, ErrPermissionDenied
+	}
+
+	return member, nil
+}
+
+// canAssign reports whether a member with the role manager can give role to
+// another member. Nobody can give away the owner role.
+func canAssign(manager string, role string) bool {
+	if !models.Can(manager, models.PermManage) || role == models.RoleOwner {
+		return false
+	}
+	return role != models.RoleAdmin || manager == models.RoleOwner
+}
+
+// InviteMember invites an email address to join a workspace with role,
+// editor by default. A pending invitation to the same address is renewed
+// instead of duplicated.
+func (uc *WorkspaceCommandUsecase) InviteMember(ctx context.Context, userID string, workspaceID string, email string, role string) (*models.WorkspaceInvitation, error) {
+	member, err := uc.GetMember(userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		role = models.RoleEditor
+	}
+	if !canAssign(member.Role, role) {
+		return nil, ErrPermissionDenied
+	}
+	if member.Workspace.Personal {
+		return nil, errors.New("A personal workspace can't be shared")
+	}
+
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	// Users who already joined don't need an invitation
+	isMember, err := uc.Repo.HasMemberWithEmail(workspaceID, email)
+	if err != nil {
+		return nil, err
+	}
+	if isMember {
+		return nil, errors.New("User is already a member of the workspace")
+	}
+
+	invitation, err := uc.Repo.FindPendingInvitation(workspaceID, email)
+	if errors.Is(err, db.ErrNotFound) {
+		invitation, err = &models.WorkspaceInvitation{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	invitation.WorkspaceID = workspaceID
+	invitation.Email = email
+	invitation.Role = role
+	invitation.InvitedBy = userID
+	invitation.Status = models.InvitationPending
+	invitation.ExpiresAt = time.Now().Add(invitationTTL)
+
+	if err := uc.Repo.SaveInvitation(invitation); err != nil {
+		slog.ErrorContext(ctx, "Error saving invitation", "error", err)
+		return nil, err
+	}
+
+	invitation.Workspace = member.Workspace
+
+	// Let the invitee know, the invitation stays valid if the email fails
+	inviter, err := uc.getUser(userID)
+	if err == nil {
+		err = uc.Mailer.Send(helpers.InvitationEmail(uc.AppURL, inviter.Username, member.Workspace.Name, email))
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Error sending invitation email", "error", err)
+	}
+
+	return invitation, nil
+}
+
+// GetWorkspaceInvitations lists the pending invitations of a workspace.
+func (uc *WorkspaceCommandUsecase) GetWorkspaceInvitations(userID string, workspaceID string) ([]*models.WorkspaceInvitation, error) {
+	if _, err := uc.GetMember(userID, workspaceID); err != nil {
+		return nil, err
+	}
+
+	invitations, err := uc.Repo.FindPendingInvitations(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+// RevokeInvitation deletes a pending invitation of a workspace managed by
+// userID.
+func (uc *WorkspaceCommandUsecase) RevokeInvitation(userID string, workspaceID string, invitationID string) error {
+	member, err := uc.GetMember(userID, workspaceID)
+	if err != nil {
+		return err
+	}
+	if !models.Can(member.Role, models.PermManage) {
+		return ErrPermissionDenied
+	}
+
+	err = uc.Repo.DeletePendingInvitation(workspaceID, invitationID)
+	if errors.Is(err, db.ErrNotFound) {
+		return ErrInvitationNotFound
+	}
+	return err
+}
+
+// GetPendingInvitations lists the invitations sent to the email of the user.
+func (uc *WorkspaceCommandUsecase) GetPendingInvitations(userID string) ([]*models.WorkspaceInvitation, error) {
+	user, err := uc.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations, err := uc.Repo.FindInvitationsTo(strings.ToLower(user.Email))
+	if err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+// RespondInvitation accepts or declines an invitation sent to the email of
+// the user. Accepting it adds the user to the workspace.
+func (uc *WorkspaceCommandUsecase) RespondInvitation(userID string, invitationID string, accept bool) (*models.WorkspaceInvitation, error) {
+	user, err := uc.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the owner of the address can join through an invitation sent to it
+	if accept && user.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
+	}
+
+	invitation, err := uc.Repo.FindInvitationTo(invitationID, strings.ToLower(user.Email))
+	if err != nil || invitation.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvitationNotFound
+	}
+
+	invitation.Status = models.InvitationDeclined
+	var member *models.WorkspaceMember
+	if accept {
+		invitation.Status = models.InvitationAccepted
+		member = &models.WorkspaceMember{
+			WorkspaceID: invitation.WorkspaceID,
+			UserID:      userID,
+			Role:        invitation.Role,
+		}
+	}
+
+	err = uc.Repo.AnswerInvitation(invitation, member)
+	if err != nil {
+		if errors.Is(err, db.ErrDuplicate) {
+			return nil, errors.New("User is already a member of the workspace")
+		}
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+// MigrateCollections moves the collections created before workspaces
+// existed into the personal workspace of their creator.
+func (uc *WorkspaceCommandUsecase) MigrateCollections(ctx context.Context) error {
+	userIDs, err := uc.Repo.FindUsersWithoutWorkspaceCollections()
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		workspace, err := uc.EnsurePersonalWorkspace(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		err = uc.Repo.MoveCollectionsWithoutWorkspace(userID, workspace.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (uc *WorkspaceCommandUsecase) getUser(userID string) (*userModels.User, error) {
+	user, err := uc.Repo.FindUser(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
@@ -0,0 +1,197 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jeksilaen/api-builder/db/dbtest"
+	"github.com/jeksilaen/api-builder/mailer"
+	userModels "github.com/jeksilaen/api-builder/modules/user/models"
+	"github.com/jeksilaen/api-builder/modules/workspace/models"
+	"github.com/jeksilaen/api-builder/modules/workspace/repositories"
+	"gorm.io/gorm"
+)
+
+// recordingSender keeps the emails instead of sending them.
+type recordingSender struct {
+	messages []mailer.Message
+}
+
+func (s *recordingSender) Send(message mailer.Message) error {
+	s.messages = append(s.messages, message)
+	return nil
+}
+
+// fixture is the team workspace of bob, in which dave is an editor. Alice
+// and carol have verified their email addresses but joined nothing yet.
+type fixture struct {
+	uc                      *WorkspaceCommandUsecase
+	gormDB                  *gorm.DB
+	sender                  *recordingSender
+	alice, bob, carol, dave *userModels.User
+	team                    *models.Workspace
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	gormDB := dbtest.Open(t)
+	sender := &recordingSender{}
+	f := &fixture{
+		uc:     NewWorkspaceCommandUsecase(repositories.NewGormWorkspaceRepository(gormDB), sender, "http://localhost:3000"),
+		gormDB: gormDB,
+		sender: sender,
+	}
+
+	verifiedAt := time.Now()
+	user := func(name string) *userModels.User {
+		user := &userModels.User{Email: name + "@example.com", Username: name, Password: "hash", EmailVerifiedAt: &verifiedAt}
+		if err := gormDB.Create(user).Error; err != nil {
+			t.Fatal(err)
+		}
+		return user
+	}
+	f.alice, f.bob, f.carol, f.dave = user("alice"), user("bob"), user("carol"), user("dave")
+
+	owner, err := f.uc.CreateWorkspace(context.Background(), f.bob.ID, &models.Workspace{Name: "Team"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.team = &owner.Workspace
+	if err := gormDB.Create(&models.WorkspaceMember{WorkspaceID: f.team.ID, UserID: f.dave.ID, Role: models.RoleEditor}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestInviteMember(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	invitation, err := f.uc.InviteMember(ctx, f.bob.ID, f.team.ID, " Alice@Example.com ", models.RoleRunner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invitation.Email != "alice@example.com" || invitation.Role != models.RoleRunner || invitation.Status != models.InvitationPending {
+		t.Errorf("invitation = %+v", invitation)
+	}
+	if len(f.sender.messages) != 1 || f.sender.messages[0].To != "alice@example.com" {
+		t.Errorf("emails = %+v, want one to alice", f.sender.messages)
+	}
+
+	// Inviting the address again renews the pending invitation
+	renewed, err := f.uc.InviteMember(ctx, f.bob.ID, f.team.ID, "alice@example.com", models.RoleViewer)
+	if err != nil || renewed.ID != invitation.ID || renewed.Role != models.RoleViewer {
+		t.Errorf("renewed = %+v, %v, want invitation %s as viewer", renewed, err, invitation.ID)
+	}
+
+	personal, err := f.uc.EnsurePersonalWorkspace(ctx, f.bob.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		userID      string
+		workspaceID string
+		email       string
+		role        string
+	}{
+		{"editor inviting", f.dave.ID, f.team.ID, "carol@example.com", ""},
+		{"owner role", f.bob.ID, f.team.ID, "carol@example.com", models.RoleOwner},
+		{"not a member", f.carol.ID, f.team.ID, "carol@example.com", ""},
+		{"already a member", f.bob.ID, f.team.ID, "DAVE@example.com", ""},
+		{"personal workspace", f.bob.ID, personal.ID, "carol@example.com", ""},
+	}
+	for _, test := range tests {
+		if _, err := f.uc.InviteMember(ctx, test.userID, test.workspaceID, test.email, test.role); err == nil {
+			t.Errorf("%s: invitation sent", test.name)
+		}
+	}
+
+	invitations, err := f.uc.GetWorkspaceInvitations(f.dave.ID, f.team.ID)
+	if err != nil || len(invitations) != 1 {
+		t.Errorf("invitations = %v, %v, want alice's only", invitations, err)
+	}
+}
+
+func TestRespondInvitation(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	invitation, err := f.uc.InviteMember(ctx, f.bob.ID, f.team.ID, "alice@example.com", models.RoleRunner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.uc.RespondInvitation(f.carol.ID, invitation.ID, true); !errors.Is(err, ErrInvitationNotFound) {
+		t.Errorf("carol accepting: %v, want ErrInvitationNotFound", err)
+	}
+
+	if err := f.gormDB.Model(f.alice).Update("email_verified_at", nil).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.uc.RespondInvitation(f.alice.ID, invitation.ID, true); !errors.Is(err, ErrEmailNotVerified) {
+		t.Errorf("accepting unverified: %v, want ErrEmailNotVerified", err)
+	}
+	if err := f.gormDB.Model(f.alice).Update("email_verified_at", time.Now()).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := f.uc.GetPendingInvitations(f.alice.ID)
+	if err != nil || len(pending) != 1 || pending[0].ID != invitation.ID {
+		t.Fatalf("pending = %v, %v, want the invitation", pending, err)
+	}
+
+	accepted, err := f.uc.RespondInvitation(f.alice.ID, invitation.ID, true)
+	if err != nil || accepted.Status != models.InvitationAccepted {
+		t.Fatalf("accepted = %+v, %v", accepted, err)
+	}
+	member, err := f.uc.GetMember(f.alice.ID, f.team.ID)
+	if err != nil || member.Role != models.RoleRunner {
+		t.Errorf("member = %+v, %v, want alice as runner", member, err)
+	}
+	if _, err := f.uc.RespondInvitation(f.alice.ID, invitation.ID, true); !errors.Is(err, ErrInvitationNotFound) {
+		t.Errorf("accepting twice: %v, want ErrInvitationNotFound", err)
+	}
+}
+
+func TestDeclineExpireAndRevokeInvitations(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	declined, err := f.uc.InviteMember(ctx, f.bob.ID, f.team.ID, "alice@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invitation, err := f.uc.RespondInvitation(f.alice.ID, declined.ID, false); err != nil || invitation.Status != models.InvitationDeclined {
+		t.Errorf("declined = %+v, %v", invitation, err)
+	}
+	if _, err := f.uc.GetMember(f.alice.ID, f.team.ID); !errors.Is(err, ErrWorkspaceNotFound) {
+		t.Errorf("alice joined after declining: %v", err)
+	}
+
+	expired, err := f.uc.InviteMember(ctx, f.bob.ID, f.team.ID, "carol@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.gormDB.Model(expired).Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.uc.RespondInvitation(f.carol.ID, expired.ID, true); !errors.Is(err, ErrInvitationNotFound) {
+		t.Errorf("accepting expired: %v, want ErrInvitationNotFound", err)
+	}
+
+	revoked, err := f.uc.InviteMember(ctx, f.bob.ID, f.team.ID, "erin@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.uc.RevokeInvitation(f.dave.ID, f.team.ID, revoked.ID); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("editor revoking: %v, want ErrPermissionDenied", err)
+	}
+	if err := f.uc.RevokeInvitation(f.bob.ID, f.team.ID, revoked.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.uc.RevokeInvitation(f.bob.ID, f.team.ID, revoked.ID); !errors.Is(err, ErrInvitationNotFound) {
+		t.Errorf("revoking twice: %v, want ErrInvitationNotFound", err)
+	}
+}
@@ -0,0 +1,127 @@
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/jeksilaen/api-builder/config"
+	"golang.org/x/oauth2"
+)
+
+// Identity is what the IdP tells about a user who logged in.
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Username      string
+}
+
+// Provider runs the OpenID Connect authorization code flow with PKCE
+// against an IdP.
+type Provider struct {
+	oauth    oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewConfiguredProvider discovers the IdP of the configuration. It returns
+// nil, leaving SSO off, when no issuer is configured.
+func NewConfiguredProvider(ctx context.Context, oidcConfig config.OIDCConfig) (*Provider, error) {
+	if oidcConfig.Issuer == "" {
+		return nil, nil
+	}
+
+	p, err := NewProvider(ctx, oidcConfig.Issuer, oidcConfig.ClientID, oidcConfig.ClientSecret, oidcConfig.RedirectURL, oidcConfig.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Single sign-on enabled", "issuer", oidcConfig.Issuer)
+	return p, nil
+}
+
+// NewProvider reads the discovery document of issuer.
+func NewProvider(ctx context.Context, issuer string, clientID string, clientSecret string, redirectURL string, scopes []string) (*Provider, error) {
+	discovered, err := oidc.NewProvider(ctx, issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Provider{
+		oauth: oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Endpoint:     discovered.Endpoint(),
+			Scopes:       scopes,
+		},
+		verifier: discovered.Verifier(&oidc.Config{ClientID: clientID}),
+	}, nil
+}
+
+// AuthCodeURL returns where to send the user to log in at the IdP.
+func (p *Provider) AuthCodeURL(state string, nonce string, codeVerifier string) string {
+	return p.oauth.AuthCodeURL(state,
+		oidc.Nonce(nonce),
+		oauth2.SetAuthURLParam("code_challenge", CodeChallenge(codeVerifier)),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	)
+}
+
+// Exchange swaps the code the IdP redirected with for the identity in the
+// verified ID token.
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*Identity, error) {
+	token, err := p.oauth.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", codeVerifier))
+	if err != nil {
+		return nil, err
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("the IdP didn't return an ID token")
+	}
+
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, err
+	}
+	if idToken.Nonce != nonce {
+		return nil, errors.New("the ID token nonce doesn't match")
+	}
+
+	var claims struct {
+		Email             string `json:"email"`
+		EmailVerified     bool   `json:"email_verified"`
+		PreferredUsername string `json:"preferred_username"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	return &Identity{
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Username:      claims.PreferredUsername,
+	}, nil
+}
+
+// NewRandomString returns a random URL safe string, used for states, nonces
+// and PKCE code verifiers.
+func NewRandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallenge returns the S256 PKCE challenge of a code verifier.
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}